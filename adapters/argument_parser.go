@@ -4,6 +4,7 @@ import (
 	"flag"
 	"io/ioutil"
 	"os"
+	"strings"
 )
 
 // ArgumentParser parses command line flags.
@@ -14,13 +15,30 @@ type ArgumentParser interface {
 	Parse() error
 }
 
+// ExtendedArgumentParser is an ArgumentParser that also defines bool options and options that can be given more than
+// once. It's a separate interface, so the implementations of ArgumentParser keep compiling: they are only asked for the
+// path and name options, and the other options are read from environment variables and the config file.
+type ExtendedArgumentParser interface {
+	ArgumentParser
+	OptionBool(name string, value bool) *bool
+	OptionStrings(name string) *[]string
+}
+
+// OptionSetChecker is an optional interface of an ArgumentParser that tells if an option has been given explicitly, so
+// an explicit false (e.g. -dev=false) can be told apart from a missing option.
+type OptionSetChecker interface {
+	IsOptionSet(name string) bool
+}
+
 // FlagArgumentParser is an implementation of ArgumentParser using the package flag.
 type FlagArgumentParser struct {
 	flagSet *flag.FlagSet
 }
 
-// Ensure FlagArgumentParser implements ArgumentParser.
+// Ensure FlagArgumentParser implements ArgumentParser, ExtendedArgumentParser and OptionSetChecker.
 var _ ArgumentParser = FlagArgumentParser{}
+var _ ExtendedArgumentParser = FlagArgumentParser{}
+var _ OptionSetChecker = FlagArgumentParser{}
 
 func NewArgumentParser() FlagArgumentParser {
 	flagSet := flag.NewFlagSet("flags", flag.ContinueOnError)
@@ -37,11 +55,45 @@ func (adapter FlagArgumentParser) OptionString(name string, value string) *strin
 	return adapter.flagSet.String(name, value, "")
 }
 
-// ParseArguments parses the command-line flags from os.Args[1:]. Must be called
+// OptionBool defines a bool flag with specified name and default value.
+// The return value is the address of a bool variable that stores the value of the flag.
+func (adapter FlagArgumentParser) OptionBool(name string, value bool) *bool {
+	return adapter.flagSet.Bool(name, value, "")
+}
+
+// OptionStrings defines a string flag with specified name that can be given more than once.
+// The return value is the address of a slice that stores every value of the flag, in order.
+func (adapter FlagArgumentParser) OptionStrings(name string) *[]string {
+	values := []string{}
+	adapter.flagSet.Var(stringsValue{values: &values}, name, "")
+
+	return &values
+}
+
+// stringsValue is a flag.Value that appends every value it's set to.
+type stringsValue struct {
+	values *[]string
+}
+
+func (value stringsValue) String() string {
+	if value.values == nil {
+		return ""
+	}
+
+	return strings.Join(*value.values, ",")
+}
+
+func (value stringsValue) Set(newValue string) error {
+	*value.values = append(*value.values, newValue)
+
+	return nil
+}
+
+// ParseArguments parses the given command-line flags (os.Args[1:] if nil). Must be called
 // after all flags are defined and before flags are accessed by the program.
 func (adapter FlagArgumentParser) ParseArguments(args []string) error {
 	// Default os.Args[1:] for retro compatibility.
-	if len(args) == 0 {
+	if args == nil {
 		args = os.Args[1:]
 	}
 
@@ -58,3 +110,15 @@ func (adapter FlagArgumentParser) Parse() error {
 func (adapter FlagArgumentParser) PositionalArguments() []string {
 	return adapter.flagSet.Args()
 }
+
+// IsOptionSet returns true if the option has been given on the parsed command line, even with its default value.
+func (adapter FlagArgumentParser) IsOptionSet(name string) bool {
+	isSet := false
+	adapter.flagSet.Visit(func(option *flag.Flag) {
+		if option.Name == name {
+			isSet = true
+		}
+	})
+
+	return isSet
+}
@@ -55,3 +55,49 @@ func TestParsingOptions(test *testing.T) {
 	assert.Equal(test, "1", *opt1)
 	assert.Equal(test, "2", *opt2)
 }
+
+func TestParsingBoolOptions(test *testing.T) {
+	test.Parallel()
+
+	parser := adapters.NewArgumentParser()
+
+	opt1 := parser.OptionBool("opt1", false)
+	opt2 := parser.OptionBool("opt2", false)
+
+	err := parser.ParseArguments([]string{"-opt1"})
+	require.Nil(test, err)
+
+	assert.True(test, *opt1)
+	assert.False(test, *opt2)
+}
+
+func TestParsingRepeatedOptions(test *testing.T) {
+	test.Parallel()
+
+	parser := adapters.NewArgumentParser()
+
+	opt1 := parser.OptionStrings("opt1")
+	opt2 := parser.OptionStrings("opt2")
+
+	err := parser.ParseArguments([]string{"-opt1=1", "-opt1=2"})
+	require.Nil(test, err)
+
+	assert.Equal(test, []string{"1", "2"}, *opt1)
+	assert.Empty(test, *opt2)
+}
+
+func TestCheckingIfAnOptionIsSet(test *testing.T) {
+	test.Parallel()
+
+	parser := adapters.NewArgumentParser()
+
+	opt1 := parser.OptionBool("opt1", false)
+	parser.OptionBool("opt2", false)
+
+	err := parser.ParseArguments([]string{"-opt1=false"})
+	require.Nil(test, err)
+
+	assert.False(test, *opt1)
+	assert.True(test, parser.IsOptionSet("opt1"))
+	assert.False(test, parser.IsOptionSet("opt2"))
+}
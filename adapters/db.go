@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"context"
 	"database/sql"
 )
 
@@ -40,6 +41,40 @@ func (adapter DBAdapter) Exec(query string, args ...interface{}) (sql.Result, er
 	return adapter.db.Exec(query, args...)
 }
 
+// NewConnAdapter returns an implementation of DB that runs every query on the given connection (e.g. to keep
+// per-connection settings like the current schema).
+func NewConnAdapter(ctx context.Context, conn *sql.Conn) ConnAdapter {
+	return ConnAdapter{
+		ctx:  ctx,
+		conn: conn,
+	}
+}
+
+type ConnAdapter struct {
+	ctx  context.Context
+	conn *sql.Conn
+}
+
+// Ensure ConnAdapter implements DB.
+var _ DB = ConnAdapter{}
+
+// Ping verifies the connection to the database is still alive.
+func (adapter ConnAdapter) Ping() error {
+	return adapter.conn.PingContext(adapter.ctx)
+}
+
+// Query executes a query that returns rows, typically a SELECT.
+// The args are for any placeholder parameters in the query.
+func (adapter ConnAdapter) Query(query string, args ...interface{}) (DBRows, error) {
+	return adapter.conn.QueryContext(adapter.ctx, query, args...)
+}
+
+// Exec executes a query without returning any rows.
+// The args are for any placeholder parameters in the query.
+func (adapter ConnAdapter) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return adapter.conn.ExecContext(adapter.ctx, query, args...)
+}
+
 // DBRows is the result of a query. Its cursor starts before the first row
 // of the result set. Use Next to advance from row to row.
 type DBRows interface {
@@ -13,11 +13,18 @@ type FileSystem interface {
 	WriteFile(filename string, data []byte, perm fs.FileMode) error
 }
 
+// FileMover is an optional interface of a FileSystem that moves files to other directories.
+type FileMover interface {
+	Rename(oldPath, newPath string) error
+	MkdirAll(path string, perm fs.FileMode) error
+}
+
 // IOUtilAdapter is an implementation of FileSystem using io/ioutil and os.
 type IOUtilAdapter struct{}
 
-// Ensure IOUtilAdapter implements FileSystem.
+// Ensure IOUtilAdapter implements FileSystem and FileMover.
 var _ FileSystem = IOUtilAdapter{}
+var _ FileMover = IOUtilAdapter{}
 
 // ReadDir list the files on a given directory.
 func (adapter IOUtilAdapter) ReadDir(dirname string) ([]os.FileInfo, error) {
@@ -32,3 +39,13 @@ func (adapter IOUtilAdapter) ReadFile(filename string) ([]byte, error) {
 func (adapter IOUtilAdapter) WriteFile(filename string, data []byte, perm fs.FileMode) error {
 	return ioutil.WriteFile(filename, data, perm)
 }
+
+// Rename moves a file.
+func (adapter IOUtilAdapter) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
+// MkdirAll creates a directory (and its parents) if needed.
+func (adapter IOUtilAdapter) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
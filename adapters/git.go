@@ -0,0 +1,16 @@
+package adapters
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// GetGitAuthor returns the user name from the git config (empty if git is not available or it's not configured).
+func GetGitAuthor() string {
+	output, err := exec.Command("git", "config", "user.name").Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,84 @@
+package adapters
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Prompter asks the user for confirmation.
+type Prompter interface {
+	Confirm(question string) (bool, error)
+	ConfirmTyped(question string, expectedAnswer string) (bool, error)
+	IsInteractive() bool
+}
+
+// TerminalPrompter is an implementation of Prompter that reads the answer from a terminal.
+type TerminalPrompter struct {
+	input  io.Reader
+	output io.Writer
+}
+
+// Ensure TerminalPrompter implements Prompter.
+var _ Prompter = TerminalPrompter{}
+
+// NewTerminalPrompter returns a TerminalPrompter that asks on output and reads the answer from input.
+func NewTerminalPrompter(input io.Reader, output io.Writer) TerminalPrompter {
+	return TerminalPrompter{
+		input:  input,
+		output: output,
+	}
+}
+
+// Confirm asks a yes/no question (no is the default answer). It fails if the input is not a terminal, so nothing
+// is confirmed by accident on pipelines.
+func (prompter TerminalPrompter) Confirm(question string) (bool, error) {
+	answer, err := prompter.ask(fmt.Sprintf("%s [y/N]: ", question))
+	if err != nil {
+		return false, err
+	}
+
+	answer = strings.ToLower(answer)
+
+	return answer == "y" || answer == "yes", nil
+}
+
+// ConfirmTyped asks the user to type the expected answer (e.g. the name of the DB) to confirm. Like Confirm, it fails
+// if the input is not a terminal.
+func (prompter TerminalPrompter) ConfirmTyped(question string, expectedAnswer string) (bool, error) {
+	answer, err := prompter.ask(fmt.Sprintf("%s [type %s to confirm]: ", question, expectedAnswer))
+	if err != nil {
+		return false, err
+	}
+
+	return answer == expectedAnswer, nil
+}
+
+// IsInteractive returns true if the input is a terminal, so the user can answer.
+func (prompter TerminalPrompter) IsInteractive() bool {
+	return IsInputTerminal(prompter.input)
+}
+
+func (prompter TerminalPrompter) ask(question string) (string, error) {
+	if !prompter.IsInteractive() {
+		return "", errors.New("cannot ask for confirmation because the input is not a terminal")
+	}
+
+	_, err := fmt.Fprint(prompter.output, question)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to ask for confirmation")
+	}
+
+	answer, err := bufio.NewReader(prompter.input).ReadString('\n')
+	if err == io.EOF {
+		return "", errors.New("the input was closed before confirming")
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read the confirmation")
+	}
+
+	return strings.TrimSpace(answer), nil
+}
@@ -0,0 +1,52 @@
+package adapters_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+)
+
+func TestConfirmingFailsIfTheInputIsNotATerminal(test *testing.T) {
+	test.Parallel()
+
+	output := &bytes.Buffer{}
+	prompter := adapters.NewTerminalPrompter(strings.NewReader("y\n"), output)
+
+	confirmed, err := prompter.Confirm("Continue?")
+
+	assert.NotNil(test, err)
+	assert.False(test, confirmed)
+	assert.Empty(test, output.String())
+}
+
+func TestConfirmingByTypingFailsIfTheInputIsNotATerminal(test *testing.T) {
+	test.Parallel()
+
+	output := &bytes.Buffer{}
+	prompter := adapters.NewTerminalPrompter(strings.NewReader("db\n"), output)
+
+	confirmed, err := prompter.ConfirmTyped("Run the migrations?", "db")
+
+	assert.NotNil(test, err)
+	assert.False(test, confirmed)
+	assert.False(test, prompter.IsInteractive())
+	assert.Empty(test, output.String())
+}
+
+func TestDevNullIsNotAnInteractiveInput(test *testing.T) {
+	test.Parallel()
+
+	devNull, err := os.Open(os.DevNull)
+	require.Nil(test, err)
+	defer devNull.Close()
+
+	prompter := adapters.NewTerminalPrompter(devNull, &bytes.Buffer{})
+
+	assert.False(test, prompter.IsInteractive())
+}
@@ -0,0 +1,29 @@
+package adapters
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal returns true if the given writer is a file attached to a terminal.
+func IsTerminal(writer io.Writer) bool {
+	return isTerminal(writer)
+}
+
+// IsInputTerminal returns true if the given reader is a file attached to a terminal.
+func IsInputTerminal(reader io.Reader) bool {
+	return isTerminal(reader)
+}
+
+// isTerminal checks the file descriptor itself (not only that it's a character device, since /dev/null is one too,
+// e.g. the stdin of docker run without -i or of Kubernetes jobs).
+func isTerminal(stream interface{}) bool {
+	file, ok := stream.(*os.File)
+	if !ok {
+		return false
+	}
+
+	return term.IsTerminal(int(file.Fd()))
+}
@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// CommandOption customizes RunCommand and RunMigrationsCommand.
+type CommandOption func(settings *commandSettings)
+
+type commandSettings struct {
+	registry       *services.CommandRegistry
+	customCommands map[string]CustomCommand
+	getEnvVar      func(key string) string
+	stdin          io.Reader
+	ctx            context.Context
+	stdout         io.Writer
+	stderr         io.Writer
+	err            error
+}
+
+// WithEnvVars makes RunCommand read environment variables (e.g. MIGRATIONS_PATH or NO_COLOR) with the given function,
+// like os.Getenv. Without it, RunCommand ignores the environment.
+func WithEnvVars(getEnvVar func(key string) string) CommandOption {
+	return func(settings *commandSettings) {
+		settings.getEnvVar = getEnvVar
+	}
+}
+
+// WithStdin makes RunCommand read the answers to confirmations (e.g. of the repair command) from the given reader.
+// Without it, every confirmation is declined.
+func WithStdin(stdin io.Reader) CommandOption {
+	return func(settings *commandSettings) {
+		settings.stdin = stdin
+	}
+}
+
+func getCommandSettings(
+	ctx context.Context,
+	stdout io.Writer,
+	stderr io.Writer,
+	options ...CommandOption,
+) commandSettings {
+	settings := commandSettings{
+		registry:       services.DefaultCommandRegistry(),
+		customCommands: map[string]CustomCommand{},
+		getEnvVar:      func(string) string { return "" },
+		stdin:          strings.NewReader(""),
+		ctx:            ctx,
+		stdout:         stdout,
+		stderr:         stderr,
+	}
+	for _, option := range options {
+		option(&settings)
+	}
+
+	return settings
+}
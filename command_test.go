@@ -0,0 +1,453 @@
+package migrations_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/jimenezmaximiliano/migrations"
+	"github.com/jimenezmaximiliano/migrations/commands"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// runCommand runs a command on a SQLite DB, returning its exit code and output.
+func runCommand(
+	test *testing.T,
+	dbPath string,
+	args []string,
+	options ...migrations.CommandOption,
+) (int, string, string) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	setupDB := func() (*sql.DB, error) {
+		return sql.Open("sqlite", dbPath)
+	}
+
+	exitCode := migrations.RunCommand(context.Background(), args, stdout, stderr, setupDB, options...)
+
+	return exitCode, stdout.String(), stderr.String()
+}
+
+func TestRunningTheMigrateCommand(test *testing.T) {
+	test.Parallel()
+
+	dbPath := filepath.Join(test.TempDir(), "db.sqlite")
+
+	exitCode, stdout, stderr := runCommand(test, dbPath, []string{"migrate", "-path=./fixtures/sqlite"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "20200318001000_createGophersTable.sql")
+	assert.Contains(test, stdout, "20200421001000_insertGophers.sql")
+	assert.Empty(test, stderr)
+
+	exitCode, stdout, _ = runCommand(test, dbPath, []string{"-path=./fixtures/sqlite"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "No migrations to run")
+}
+
+func TestRunningAnInvalidCommand(test *testing.T) {
+	test.Parallel()
+
+	exitCode, stdout, stderr := runCommand(test, "", []string{"migrat", "-path=./fixtures/sqlite"})
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, exitCode)
+	assert.Contains(test, stderr, "invalid 'command' argument: [migrat]")
+	assert.Contains(test, stdout, "Available commands")
+}
+
+func TestRunningACommandIgnoresTheEnvironmentUnlessItIsGiven(test *testing.T) {
+	test.Parallel()
+
+	dbPath := filepath.Join(test.TempDir(), "db.sqlite")
+	env := map[string]string{
+		services.EnvVarCommand:        "validate",
+		services.EnvVarMigrationsPath: "./fixtures/sqlite",
+	}
+	getEnvVar := func(key string) string {
+		return env[key]
+	}
+
+	exitCode, _, stderr := runCommand(test, dbPath, nil)
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, exitCode)
+	assert.Contains(test, stderr, "missing 'path' option for command 'migrate'")
+
+	exitCode, stdout, _ := runCommand(test, dbPath, nil, migrations.WithEnvVars(getEnvVar))
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "No problems found")
+}
+
+func TestRunningTheCreateCommand(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+
+	exitCode, stdout, _ := runCommand(test, "", []string{"create", "-path=" + directory, "-name=addGophers"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "migration file created at")
+	files, err := ioutil.ReadDir(directory)
+	require.Nil(test, err)
+	require.Len(test, files, 1)
+	assert.True(test, strings.HasSuffix(files[0].Name(), "_addGophers.sql"))
+}
+
+func TestCreatingSequentialMigrationsNextToTheTemplate(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	template := "-- {{name}}\nSELECT 1;\n"
+	require.Nil(test, ioutil.WriteFile(filepath.Join(directory, services.DefaultTemplateFileName), []byte(template), 0644))
+	args := []string{"create", "-path=" + directory, "-numbering=sequential", "-name=addGophers"}
+
+	exitCode, _, stderr := runCommand(test, "", args)
+	require.Equal(test, services.ExitCodeSuccess, exitCode, stderr)
+	exitCode, _, stderr = runCommand(test, "", args)
+	require.Equal(test, services.ExitCodeSuccess, exitCode, stderr)
+
+	dbPath := filepath.Join(test.TempDir(), "db.sqlite")
+	exitCode, stdout, stderr := runCommand(test, dbPath, []string{"validate", "-path=" + directory})
+	assert.Equal(test, services.ExitCodeSuccess, exitCode, stderr)
+	assert.Contains(test, stdout, "No problems found")
+
+	exitCode, stdout, stderr = runCommand(test, dbPath, []string{"migrate", "-path=" + directory})
+	assert.Equal(test, services.ExitCodeSuccess, exitCode, stderr)
+	assert.Contains(test, stdout, "_addGophers.sql")
+	assert.NotContains(test, stdout, services.DefaultTemplateFileName)
+}
+
+func TestRunningThePlanCommandWritesToTheGivenStdout(test *testing.T) {
+	test.Parallel()
+
+	dbPath := filepath.Join(test.TempDir(), "db.sqlite")
+	exitCode, _, _ := runCommand(test, dbPath, []string{"migrate", "-path=./fixtures/sqlite"})
+	require.Equal(test, services.ExitCodeSuccess, exitCode)
+
+	exitCode, stdout, _ := runCommand(test, dbPath, []string{"plan", "-path=./fixtures/sqlite"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "-- Migrations to run: 0")
+}
+
+func TestRunningThePlanCommandOnADBWithoutAMigrationsTable(test *testing.T) {
+	test.Parallel()
+
+	dbPath := filepath.Join(test.TempDir(), "db.sqlite")
+
+	exitCode, plan, stderr := runCommand(test, dbPath, []string{"plan", "-path=./fixtures/sqlite"})
+
+	require.Equal(test, services.ExitCodeSuccess, exitCode, stderr)
+	assert.Contains(test, plan, "-- Migrations to run: 2")
+	DB, err := sql.Open("sqlite", dbPath)
+	require.Nil(test, err)
+	defer DB.Close()
+	tables := 0
+	require.Nil(test, DB.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'migrations'").Scan(&tables))
+	assert.Equal(test, 0, tables)
+
+	_, err = DB.Exec(plan)
+	require.Nil(test, err)
+	exitCode, stdout, _ := runCommand(test, dbPath, []string{"migrate", "-path=./fixtures/sqlite"})
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "No migrations to run")
+}
+
+func TestRepairingTheMigrationsTableKeepsARowPerMigration(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	for _, name := range []string{"1_createGophersTable.sql", "2_insertGophers.sql"} {
+		require.Nil(test, ioutil.WriteFile(filepath.Join(directory, name), []byte("SELECT 1;"), 0644))
+	}
+	dbPath := filepath.Join(test.TempDir(), "db.sqlite")
+	exitCode, _, stderr := runCommand(test, dbPath, []string{"migrate", "-path=" + directory})
+	require.Equal(test, services.ExitCodeSuccess, exitCode, stderr)
+	DB, err := sql.Open("sqlite", dbPath)
+	require.Nil(test, err)
+	defer DB.Close()
+	_, err = DB.Exec("INSERT INTO migrations (migration) VALUES (?), (?)", "1_createGophersTable.sql",
+		"1_createGophersTable.sql")
+	require.Nil(test, err)
+	err = os.Rename(filepath.Join(directory, "2_insertGophers.sql"), filepath.Join(directory, "2_addGophers.sql"))
+	require.Nil(test, err)
+
+	exitCode, _, stderr = runCommand(test, dbPath, []string{"repair", "-path=" + directory, "-yes"})
+
+	require.Equal(test, services.ExitCodeSuccess, exitCode, stderr)
+	rows, err := DB.Query("SELECT migration FROM migrations ORDER BY id")
+	require.Nil(test, err)
+	defer rows.Close()
+	var registeredNames []string
+	for rows.Next() {
+		name := ""
+		require.Nil(test, rows.Scan(&name))
+		registeredNames = append(registeredNames, name)
+	}
+	assert.Equal(test, []string{"1_createGophersTable.sql", "2_addGophers.sql"}, registeredNames)
+}
+
+func TestRunningTheHelpAndVersionCommands(test *testing.T) {
+	test.Parallel()
+
+	exitCode, stdout, _ := runCommand(test, "", []string{"help", "create"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "[executable] create [-options]")
+	assert.Contains(test, stdout, services.EnvVarNewMigrationName)
+
+	exitCode, _, stderr := runCommand(test, "", []string{"help", "creat"})
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, exitCode)
+	assert.Contains(test, stderr, "unknown command [creat]")
+
+	exitCode, stdout, _ = runCommand(test, "", []string{"version"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "migrations ")
+}
+
+func TestMigratingWithANonTerminalStdinDoesNotAskForConfirmation(test *testing.T) {
+	test.Parallel()
+
+	devNull, err := os.Open(os.DevNull)
+	require.Nil(test, err)
+	defer devNull.Close()
+	dbPath := filepath.Join(test.TempDir(), "db.sqlite")
+
+	exitCode, stdout, _ := runCommand(
+		test,
+		dbPath,
+		[]string{"migrate", "-path=./fixtures/sqlite"},
+		migrations.WithStdin(devNull),
+	)
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.NotContains(test, stdout, "to confirm")
+	assert.Contains(test, stdout, "20200421001000_insertGophers.sql")
+
+	exitCode, _, _ = runCommand(test, dbPath, []string{"check", "-path=./fixtures/sqlite"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+}
+
+func TestMigratingAProtectedDBMustBeAllowed(test *testing.T) {
+	test.Parallel()
+
+	dbPath := filepath.Join(test.TempDir(), "db.sqlite")
+
+	exitCode, stdout, _ := runCommand(test, dbPath, []string{"protect"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "The DB is protected as [production]")
+
+	exitCode, stdout, stderr := runCommand(test, dbPath, []string{"migrate", "-path=./fixtures/sqlite"})
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, exitCode)
+	assert.Contains(test, stdout, "Database: "+dbPath)
+	assert.Contains(test, stdout, "Migrations to run: 2")
+	assert.Contains(test, stderr, "the DB is protected as [production]")
+
+	exitCode, stdout, _ = runCommand(test, dbPath, []string{"migrate", "-path=./fixtures/sqlite", "-allow-protected"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "20200421001000_insertGophers.sql")
+
+	exitCode, _, stderr = runCommand(test, dbPath, []string{"unprotect"})
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, exitCode)
+	assert.Contains(test, stderr, "unprotect changes a protected DB")
+
+	exitCode, stdout, _ = runCommand(test, dbPath, []string{"unprotect", "-allow-protected"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "The DB is not protected anymore")
+
+	exitCode, stdout, _ = runCommand(test, dbPath, []string{"migrate", "-path=./fixtures/sqlite"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.NotContains(test, stdout, "protected")
+}
+
+func TestChangingTheMigrationsTableOfAProtectedDBMustBeAllowed(test *testing.T) {
+	test.Parallel()
+
+	dbPath := filepath.Join(test.TempDir(), "db.sqlite")
+	exitCode, _, _ := runCommand(test, dbPath, []string{"protect"})
+	require.Equal(test, services.ExitCodeSuccess, exitCode)
+
+	for _, args := range [][]string{
+		{"baseline", "-path=./fixtures/sqlite", "-to=20200318001000"},
+		{"mark-applied", "-path=./fixtures/sqlite", "-name=20200318001000_createGophersTable.sql"},
+		{"unmark", "-path=./fixtures/sqlite", "-name=20200318001000_createGophersTable.sql"},
+		{"repair", "-path=./fixtures/sqlite", "-yes"},
+		{"redo", "-path=./fixtures/sqlite", "-dev"},
+	} {
+		exitCode, _, stderr := runCommand(test, dbPath, args)
+
+		assert.Equal(test, services.ExitCodeInvalidArguments, exitCode, args[0])
+		assert.Contains(test, stderr, "the DB is protected as [production]", args[0])
+	}
+
+	exitCode, stdout, _ := runCommand(
+		test,
+		dbPath,
+		[]string{"baseline", "-path=./fixtures/sqlite", "-to=20200318001000", "-allow-protected"},
+	)
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "20200318001000_createGophersTable.sql")
+}
+
+func TestRunningTheCheckAndWaitCommands(test *testing.T) {
+	test.Parallel()
+
+	dbPath := filepath.Join(test.TempDir(), "db.sqlite")
+
+	exitCode, _, stderr := runCommand(test, dbPath, []string{"check", "-path=./fixtures/sqlite"})
+
+	assert.Equal(test, services.ExitCodeMigrationsPending, exitCode)
+	assert.Contains(test, stderr, "The schema is not up to date (2 migration(s) pending, 0 failed)")
+
+	exitCode, stdout, _ := runCommand(test, dbPath, []string{"wait", "-path=./fixtures/sqlite", "-timeout=50ms"})
+
+	assert.Equal(test, services.ExitCodeMigrationsPending, exitCode)
+	assert.Contains(test, stdout, "Waiting for 2 migration(s)")
+
+	DB, err := sql.Open("sqlite", dbPath)
+	require.Nil(test, err)
+	defer DB.Close()
+	pending, err := migrations.Pending(DB, "./fixtures/sqlite")
+	require.Nil(test, err)
+	assert.Len(test, pending, 2)
+
+	exitCode, _, _ = runCommand(test, dbPath, []string{"migrate", "-path=./fixtures/sqlite"})
+	require.Equal(test, services.ExitCodeSuccess, exitCode)
+
+	exitCode, stdout, _ = runCommand(test, dbPath, []string{"check", "-path=./fixtures/sqlite"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "The schema is up to date")
+
+	exitCode, _, _ = runCommand(test, dbPath, []string{"wait", "-path=./fixtures/sqlite", "-timeout=50ms"})
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+
+	pending, err = migrations.Pending(DB, "./fixtures/sqlite")
+	require.Nil(test, err)
+	assert.Empty(test, pending)
+}
+
+type countGophers struct {
+	DB      *sql.DB
+	display services.Display
+}
+
+func (command countGophers) Run() error {
+	var gophers int
+	err := command.DB.QueryRow("SELECT COUNT(*) FROM gophers").Scan(&gophers)
+	if err != nil {
+		command.display.DisplayError(err)
+		return err
+	}
+
+	command.display.DisplayInfo(strings.Repeat("gopher ", gophers))
+
+	return nil
+}
+
+func TestRunningACustomCommand(test *testing.T) {
+	test.Parallel()
+
+	dbPath := filepath.Join(test.TempDir(), "db.sqlite")
+	exitCode, _, _ := runCommand(test, dbPath, []string{"migrate", "-path=./fixtures/sqlite"})
+	require.Equal(test, services.ExitCodeSuccess, exitCode)
+
+	var DB *sql.DB
+	var greeting string
+	count := migrations.WithCommand(migrations.CustomCommand{
+		Spec: services.CommandSpec{
+			Name:            "count",
+			Summary:         "Count the gophers",
+			RequiredOptions: []string{"greeting"},
+		},
+		Options: []services.OptionSpec{{Name: "greeting", Description: "what to say first"}},
+		NeedsDB: true,
+		Build: func(commandContext migrations.CommandContext) (commands.Command, error) {
+			DB = commandContext.DB
+			greeting = commandContext.Arguments.GetOption("greeting")
+			return countGophers{DB: commandContext.DB, display: commandContext.Display}, nil
+		},
+	})
+
+	exitCode, stdout, _ := runCommand(test, dbPath, []string{"count", "-greeting=hi"}, count)
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Equal(test, "hi", greeting)
+	assert.Contains(test, stdout, "gopher gopher")
+	require.NotNil(test, DB)
+	assert.EqualError(test, DB.Ping(), "sql: database is closed")
+
+	exitCode, stdout, _ = runCommand(test, dbPath, []string{"help"}, count)
+
+	assert.Equal(test, services.ExitCodeSuccess, exitCode)
+	assert.Contains(test, stdout, "Count the gophers")
+
+	exitCode, _, stderr := runCommand(test, dbPath, []string{"count"}, count)
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, exitCode)
+	assert.Contains(test, stderr, "missing 'greeting' option for command 'count'")
+}
+
+func TestRunningACustomCommandRetriesToConnectToTheDB(test *testing.T) {
+	test.Parallel()
+
+	isBuilt := false
+	ping := migrations.WithCommand(migrations.CustomCommand{
+		Spec:    services.CommandSpec{Name: "ping", Summary: "Ping the DB"},
+		NeedsDB: true,
+		Build: func(commandContext migrations.CommandContext) (commands.Command, error) {
+			isBuilt = true
+			return nil, nil
+		},
+	})
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	setupDB := func() (*sql.DB, error) {
+		// Nothing listens on port 1, so connecting is refused (a retryable error).
+		return sql.Open("mysql", "user:password@tcp(127.0.0.1:1)/db")
+	}
+	args := []string{"ping", "-connect-timeout=300ms", "-connect-backoff=50ms"}
+
+	exitCode := migrations.RunCommand(context.Background(), args, stdout, stderr, setupDB, ping)
+
+	assert.Equal(test, services.ExitCodeDBUnavailable, exitCode)
+	assert.Contains(test, stdout.String()+stderr.String(), "Could not connect to the DB (attempt 2)")
+	assert.False(test, isBuilt)
+}
+
+func TestRegisteringACustomCommandThatClashesWithABuiltInOneFails(test *testing.T) {
+	test.Parallel()
+
+	migrate := migrations.WithCommand(migrations.CustomCommand{
+		Spec: services.CommandSpec{Name: "migrate"},
+		Build: func(migrations.CommandContext) (commands.Command, error) {
+			return nil, nil
+		},
+	})
+
+	exitCode, _, stderr := runCommand(test, "", []string{"migrate"}, migrate)
+
+	assert.Equal(test, services.ExitCodeUnknownError, exitCode)
+	assert.Contains(test, stderr, "the command [migrate] is already registered")
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Baseline is a command that registers every migration up to an order as run, without running them.
+type Baseline struct {
+	bookkeeper services.Bookkeeper
+	display    services.ExtendedDisplay
+	args       services.Arguments
+}
+
+// NewBaselineCommand builds a Baseline.
+func NewBaselineCommand(
+	bookkeeper services.Bookkeeper,
+	display services.ExtendedDisplay,
+	args services.Arguments,
+) Baseline {
+	return Baseline{
+		bookkeeper: bookkeeper,
+		display:    display,
+		args:       args,
+	}
+}
+
+var _ Command = Baseline{}
+
+// Run registers the migrations and displays them.
+func (command Baseline) Run() error {
+	result, err := command.bookkeeper.Baseline(command.args.BaselineOrder)
+	if err != nil {
+		command.display.DisplayErrorWithMessage(err, "something went wrong while registering migrations")
+		return err
+	}
+
+	command.display.DisplayBaseline(result)
+
+	return nil
+}
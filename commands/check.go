@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Check is a command that checks whether every migration has been run, without running them.
+type Check struct {
+	checker services.Checker
+	display services.ExtendedDisplay
+}
+
+// NewCheckCommand builds a Check.
+func NewCheckCommand(checker services.Checker, display services.ExtendedDisplay) Check {
+	return Check{
+		checker: checker,
+		display: display,
+	}
+}
+
+var _ Command = Check{}
+
+// Run displays the pending and failed migrations, returning a PendingMigrationsError if there are any.
+func (command Check) Run() error {
+	status, err := command.checker.Check()
+	if err != nil {
+		command.display.DisplayErrorWithMessage(err, "something went wrong while checking migrations")
+		return err
+	}
+
+	command.display.DisplayReadiness(status)
+	if !status.IsReady() {
+		return services.NewPendingMigrationsError(status)
+	}
+
+	return nil
+}
+
+// Wait is a command that waits until every migration has been run (e.g. by a migration job), without running them.
+type Wait struct {
+	ctx     context.Context
+	checker services.Checker
+	display services.ExtendedDisplay
+	args    services.Arguments
+}
+
+// NewWaitCommand builds a Wait. It stops waiting when the context is done.
+func NewWaitCommand(
+	ctx context.Context,
+	checker services.Checker,
+	display services.ExtendedDisplay,
+	args services.Arguments,
+) Wait {
+	return Wait{
+		ctx:     ctx,
+		checker: checker,
+		display: display,
+		args:    args,
+	}
+}
+
+var _ Command = Wait{}
+
+// Run checks the migrations every services.DefaultWaitPollInterval until the schema is up to date, returning a
+// PendingMigrationsError if it's not after the 'timeout' option.
+func (command Wait) Run() error {
+	ctx, cancel := context.WithTimeout(command.ctx, command.args.WaitTimeout)
+	defer cancel()
+
+	status, err := command.checker.Wait(ctx, services.DefaultWaitPollInterval, command.display.DisplayWaiting)
+	if err != nil && services.ExitCode(err) != services.ExitCodeMigrationsPending {
+		command.display.DisplayErrorWithMessage(err, "something went wrong while waiting for migrations")
+		return err
+	}
+
+	command.display.DisplayReadiness(status)
+
+	return err
+}
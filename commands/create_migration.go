@@ -2,7 +2,6 @@ package commands
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/jimenezmaximiliano/migrations/repositories"
@@ -11,47 +10,86 @@ import (
 
 // CreateMigration is a command that creates a migration file.
 type CreateMigration struct {
-	fileRepo repositories.FileRepository
-	display  services.Display
-	args     services.Arguments
+	fileRepo  repositories.FileRepository
+	numberer  services.Numberer
+	templater services.Templater
+	display   services.Display
+	args      services.Arguments
 }
 
 // NewCreateMigrationCommand builds a CreateMigration.
 func NewCreateMigrationCommand(
 	fileRepo repositories.FileRepository,
+	numberer services.Numberer,
+	templater services.Templater,
 	display services.Display,
 	args services.Arguments,
 ) CreateMigration {
 	return CreateMigration{
-		fileRepo: fileRepo,
-		display:  display,
-		args:     args,
+		fileRepo:  fileRepo,
+		numberer:  numberer,
+		templater: templater,
+		display:   display,
+		args:      args,
 	}
 }
 
 // Command represents a command line command that can be run.
+// Run displays its own errors, and returns them so the caller can pick an exit code (see services.ExitCode).
 type Command interface {
-	Run()
+	Run() error
 }
 
 var _ Command = CreateMigration{}
 
-// Run creates a migration file with a sample content using a sort of unique name based on a timestamp.
-func (command CreateMigration) Run() {
+// Run creates a migration file (and its down migration, if the template has one) using a sort of unique name based on
+// a timestamp or a sequential number (see services.Numberer).
+func (command CreateMigration) Run() error {
+	name, err := services.SanitizeMigrationName(command.args.MigrationName, command.args.NameStyle)
+	if err != nil {
+		command.display.DisplayError(err)
+		return err
+	}
+
 	now := time.Now()
-	fileName := fmt.Sprintf("%d_%s", now.UnixNano(), command.args.MigrationName)
+	order, prefix, err := command.numberer.GetNextOrder(command.args.MigrationsPath, now)
+	if err != nil {
+		command.display.DisplayError(err)
+		return err
+	}
 
-	filePath := command.args.MigrationsPath + fileName
-	extension := filePath[len(filePath)-4:]
-	if extension != ".sql" {
-		filePath += ".sql"
+	filePath := fmt.Sprintf("%s%s_%s.sql", command.args.MigrationsPath, prefix, name)
+	templateData := services.TemplateData{
+		Name:  name,
+		Order: order,
+		Date:  now,
+	}
+	migration, err := command.templater.Render(command.args.MigrationsPath, command.args.TemplatePath, templateData)
+	if err != nil {
+		command.display.DisplayError(err)
+		return err
 	}
 
-	err := command.fileRepo.CreateMigration(filePath, "SELECT 1;")
+	err = command.fileRepo.CreateMigration(filePath, migration.Up)
 	if err != nil {
 		command.display.DisplayError(err)
-		os.Exit(1)
+		return err
 	}
 
 	command.display.DisplayInfo(fmt.Sprintf("migration file created at %s", filePath))
+
+	if migration.Down == "" {
+		return nil
+	}
+
+	downFilePath := repositories.GetDownMigrationPath(filePath)
+	err = command.fileRepo.CreateMigration(downFilePath, migration.Down)
+	if err != nil {
+		command.display.DisplayError(err)
+		return err
+	}
+
+	command.display.DisplayInfo(fmt.Sprintf("down migration file created at %s", downFilePath))
+
+	return nil
 }
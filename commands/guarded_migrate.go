@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// GuardedMigrate is a command that shows the DBs the migrations are about to run on and asks for confirmation before
+// running them (see services.Guard).
+type GuardedMigrate struct {
+	guards   []services.Guard
+	prompter adapters.Prompter
+	migrate  Command
+	display  services.ExtendedDisplay
+	args     services.Arguments
+}
+
+// NewGuardedMigrateCommand builds a GuardedMigrate that runs the given migrate command once confirmed.
+func NewGuardedMigrateCommand(
+	guard services.Guard,
+	prompter adapters.Prompter,
+	migrate Command,
+	display services.ExtendedDisplay,
+	args services.Arguments,
+) GuardedMigrate {
+	return NewGuardedMigrateSchemasCommand([]services.Guard{guard}, prompter, migrate, display, args)
+}
+
+// NewGuardedMigrateSchemasCommand builds a GuardedMigrate that runs the given migrate command on several DBs (e.g.
+// MigrateSchemas) once confirmed, with a Guard per DB.
+func NewGuardedMigrateSchemasCommand(
+	guards []services.Guard,
+	prompter adapters.Prompter,
+	migrate Command,
+	display services.ExtendedDisplay,
+	args services.Arguments,
+) GuardedMigrate {
+	return GuardedMigrate{
+		guards:   guards,
+		prompter: prompter,
+		migrate:  migrate,
+		display:  display,
+		args:     args,
+	}
+}
+
+var _ Command = GuardedMigrate{}
+
+// Run displays the migration preview of every DB and runs the migrations. A protected DB with pending migrations
+// needs the 'allow-protected' option (or services.EnvVarAllowProtected). If the input is a terminal, the user has to
+// type the name of the DB to confirm ("yes" for several DBs), unless the 'yes' option is set, and declining returns a
+// NotConfirmedError.
+func (command GuardedMigrate) Run() error {
+	previews := make([]services.MigrationPreview, 0, len(command.guards))
+	pendingMigrations := 0
+	for _, guard := range command.guards {
+		preview, err := guard.GetMigrationPreview()
+		if err != nil {
+			command.display.DisplayErrorWithMessage(err, "something went wrong while checking the DB")
+			return err
+		}
+
+		command.display.DisplayMigrationPreview(preview)
+		previews = append(previews, preview)
+		pendingMigrations += len(preview.PendingMigrations)
+	}
+
+	if pendingMigrations == 0 {
+		return command.migrate.Run()
+	}
+
+	err := command.checkProtectedDBs(previews)
+	if err != nil {
+		command.display.DisplayError(err)
+		return err
+	}
+
+	if command.args.AssumeYes || !command.prompter.IsInteractive() {
+		return command.migrate.Run()
+	}
+
+	question, answer := getConfirmation(previews, pendingMigrations)
+	confirmed, err := command.prompter.ConfirmTyped(question, answer)
+	if err != nil {
+		err = services.NewArgumentsError(err)
+		command.display.DisplayErrorWithMessage(err, "use the 'yes' option to migrate without confirmation")
+		return err
+	}
+
+	if !confirmed {
+		err := services.NewNotConfirmedError(errors.Errorf(
+			"the migrations were not confirmed (%s was expected), nothing has been migrated",
+			answer,
+		))
+		command.display.DisplayError(err)
+		return err
+	}
+
+	return command.migrate.Run()
+}
+
+// checkProtectedDBs fails if a protected DB has pending migrations, unless it's allowed.
+func (command GuardedMigrate) checkProtectedDBs(previews []services.MigrationPreview) error {
+	if command.args.AllowProtected {
+		return nil
+	}
+
+	for _, preview := range previews {
+		if !preview.IsProtected() || len(preview.PendingMigrations) == 0 {
+			continue
+		}
+
+		db := "the DB"
+		if len(previews) > 1 {
+			db = fmt.Sprintf("the DB [%s]", preview.Target.Database)
+		}
+
+		return services.NewArgumentsError(errors.Errorf(
+			"%s is protected as [%s] (use the 'allow-protected' option or set %s=true to migrate it)",
+			db,
+			preview.ProtectedEnvironment,
+			services.EnvVarAllowProtected,
+		))
+	}
+
+	return nil
+}
+
+// getConfirmation returns the question to confirm the migrations and the answer that confirms them.
+func getConfirmation(previews []services.MigrationPreview, pendingMigrations int) (string, string) {
+	if len(previews) == 1 {
+		question := fmt.Sprintf("Run %d migration(s) on %s?", pendingMigrations, previews[0].Target.Database)
+		return question, previews[0].GetConfirmationAnswer()
+	}
+
+	return fmt.Sprintf("Run %d migration(s) on %d DBs?", pendingMigrations, len(previews)), "yes"
+}
@@ -0,0 +1,163 @@
+package commands_test
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/commands"
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// migrateCommand counts how many times it's run.
+type migrateCommand struct {
+	runs *int
+}
+
+func (command migrateCommand) Run() error {
+	*command.runs++
+
+	return nil
+}
+
+func getSchemaGuard(test *testing.T, schema string, protectedEnvironment string) *mocks.Guard {
+	migration, err := models.NewMigration("/tmp/1_a.sql", "SELECT 1;", models.StatusNotRun)
+	require.Nil(test, err)
+
+	guard := &mocks.Guard{}
+	guard.On("GetMigrationPreview").Return(services.MigrationPreview{
+		Target:               repositories.DBTarget{Driver: repositories.DialectMySQL, Database: schema},
+		PendingMigrations:    []models.Migration{migration},
+		ProtectedEnvironment: protectedEnvironment,
+	}, nil).Once()
+
+	return guard
+}
+
+func TestMigratingSeveralSchemasRefusesProtectedOnes(test *testing.T) {
+	test.Parallel()
+
+	tenantA := getSchemaGuard(test, "tenant_a", "")
+	defer tenantA.AssertExpectations(test)
+	tenantB := getSchemaGuard(test, "tenant_b", "production")
+	defer tenantB.AssertExpectations(test)
+	display := &mocks.ExtendedDisplay{}
+	defer display.AssertExpectations(test)
+	display.On("DisplayMigrationPreview", mock.Anything).Twice()
+	display.On("DisplayError", mock.MatchedBy(func(err error) bool {
+		return assert.Contains(test, err.Error(), "the DB [tenant_b] is protected as [production]")
+	})).Once()
+	prompter := &mocks.Prompter{}
+	defer prompter.AssertExpectations(test)
+	runs := 0
+
+	command := commands.NewGuardedMigrateSchemasCommand(
+		[]services.Guard{tenantA, tenantB},
+		prompter,
+		migrateCommand{runs: &runs},
+		display,
+		services.Arguments{AssumeYes: true},
+	)
+
+	err := command.Run()
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+	assert.Equal(test, 0, runs)
+}
+
+func TestMigratingSeveralSchemasAsksForConfirmation(test *testing.T) {
+	test.Parallel()
+
+	tenantA := getSchemaGuard(test, "tenant_a", "")
+	defer tenantA.AssertExpectations(test)
+	tenantB := getSchemaGuard(test, "tenant_b", "production")
+	defer tenantB.AssertExpectations(test)
+	display := &mocks.ExtendedDisplay{}
+	defer display.AssertExpectations(test)
+	display.On("DisplayMigrationPreview", mock.Anything).Twice()
+	display.On("DisplayError", mock.Anything).Once()
+	prompter := &mocks.Prompter{}
+	defer prompter.AssertExpectations(test)
+	prompter.On("IsInteractive").Return(true)
+	prompter.On("ConfirmTyped", "Run 2 migration(s) on 2 DBs?", "yes").Return(false, nil).Once()
+	runs := 0
+
+	command := commands.NewGuardedMigrateSchemasCommand(
+		[]services.Guard{tenantA, tenantB},
+		prompter,
+		migrateCommand{runs: &runs},
+		display,
+		services.Arguments{AllowProtected: true},
+	)
+
+	err := command.Run()
+
+	assert.Equal(test, services.ExitCodeNotConfirmed, services.ExitCode(err))
+	assert.Equal(test, 0, runs)
+}
+
+func TestDecliningTheMigrationsLeavesTheDBUntouched(test *testing.T) {
+	test.Parallel()
+
+	collection := models.Collection{}
+	migration, err := models.NewMigration("/tmp/1_a.sql", "DROP TABLE a;", models.StatusNotRun)
+	require.Nil(test, err)
+	require.Nil(test, collection.Add(migration))
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil).Once()
+	targetRows := &mocks.DBRows{}
+	defer targetRows.AssertExpectations(test)
+	targetRows.On("Next").Return(false).Once()
+	targetRows.On("Close").Return(nil).Once()
+	tablesRows := &mocks.DBRows{}
+	defer tablesRows.AssertExpectations(test)
+	tablesRows.On("Next").Return(true).Once()
+	tablesRows.On("Scan", mock.AnythingOfType("*sql.NullString")).Return(nil).Once().Run(func(args mock.Arguments) {
+		*args[0].(*sql.NullString) = sql.NullString{String: "0", Valid: true}
+	})
+	tablesRows.On("Close").Return(nil).Once()
+	db := &mocks.DB{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil).Once()
+	db.On("Query", mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, "pragma_database_list")
+	})).Return(targetRows, nil).Once()
+	db.On("Query", mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, "sqlite_master")
+	})).Return(tablesRows, nil).Once()
+	dbRepository := repositories.NewDBRepositoryForDialect(db, repositories.DialectSQLite)
+	guard := services.NewGuardService(fetcher, dbRepository, "/tmp")
+	display := &mocks.ExtendedDisplay{}
+	defer display.AssertExpectations(test)
+	display.On("DisplayMigrationPreview", mock.Anything).Once()
+	display.On("DisplayError", mock.Anything).Once()
+	prompter := &mocks.Prompter{}
+	defer prompter.AssertExpectations(test)
+	prompter.On("IsInteractive").Return(true)
+	prompter.On("ConfirmTyped", mock.Anything, mock.Anything).Return(false, nil).Once()
+	runs := 0
+
+	command := commands.NewGuardedMigrateCommand(
+		guard,
+		prompter,
+		migrateCommand{runs: &runs},
+		display,
+		services.Arguments{},
+	)
+
+	err = command.Run()
+
+	assert.Equal(test, services.ExitCodeNotConfirmed, services.ExitCode(err))
+	assert.Equal(test, 0, runs)
+	for _, call := range db.Calls {
+		assert.NotEqual(test, "Exec", call.Method)
+	}
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Help is a command that displays the available commands or, given a command name, the help of that command.
+type Help struct {
+	registry *services.CommandRegistry
+	display  services.ExtendedDisplay
+	args     services.Arguments
+}
+
+// NewHelpCommand builds a Help.
+func NewHelpCommand(registry *services.CommandRegistry, display services.ExtendedDisplay, args services.Arguments) Help {
+	return Help{
+		registry: registry,
+		display:  display,
+		args:     args,
+	}
+}
+
+var _ Command = Help{}
+
+// Run displays the help, returning an ArgumentsError if the given command doesn't exist.
+func (command Help) Run() error {
+	if len(command.args.CommandArguments) == 0 {
+		command.display.DisplayHelp()
+		return nil
+	}
+
+	spec, isRegistered := command.registry.GetCommand(command.args.CommandArguments[0])
+	if !isRegistered {
+		err := services.NewArgumentsError(errors.Errorf("unknown command [%s]", command.args.CommandArguments[0]))
+		command.display.DisplayError(err)
+		command.display.DisplayHelp()
+		return err
+	}
+
+	command.display.DisplayCommandHelp(spec)
+
+	return nil
+}
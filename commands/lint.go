@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Lint is a command that checks the contents of the migrations for dangerous statements.
+type Lint struct {
+	linter  services.Linter
+	display services.ExtendedDisplay
+	args    services.Arguments
+}
+
+// NewLintCommand builds a Lint.
+func NewLintCommand(
+	linter services.Linter,
+	display services.ExtendedDisplay,
+	args services.Arguments,
+) Lint {
+	return Lint{
+		linter:  linter,
+		display: display,
+		args:    args,
+	}
+}
+
+var _ Command = Lint{}
+
+// Run displays every lint issue found on the migrations, returning a LintError if any of them is an error.
+func (command Lint) Run() error {
+	var issues []services.LintIssue
+	for _, migrationsPath := range command.args.GetMigrationsPaths() {
+		directoryIssues, err := command.linter.Lint(services.GetMigrationsDirectoryPath(migrationsPath))
+		if err != nil {
+			command.display.DisplayError(err)
+			return err
+		}
+		issues = append(issues, directoryIssues...)
+	}
+
+	command.display.DisplayLintIssues(issues)
+
+	if services.HasLintErrors(issues) {
+		return services.NewLintError(issues)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Mark is a command that registers (or unregisters) a single migration as run, without running it.
+type Mark struct {
+	bookkeeper services.Bookkeeper
+	display    services.ExtendedDisplay
+	args       services.Arguments
+	unmark     bool
+}
+
+// NewMarkAppliedCommand builds a Mark that registers a migration as run.
+func NewMarkAppliedCommand(
+	bookkeeper services.Bookkeeper,
+	display services.ExtendedDisplay,
+	args services.Arguments,
+) Mark {
+	return Mark{
+		bookkeeper: bookkeeper,
+		display:    display,
+		args:       args,
+	}
+}
+
+// NewUnmarkCommand builds a Mark that unregisters a migration, so it's run again.
+func NewUnmarkCommand(
+	bookkeeper services.Bookkeeper,
+	display services.ExtendedDisplay,
+	args services.Arguments,
+) Mark {
+	return Mark{
+		bookkeeper: bookkeeper,
+		display:    display,
+		args:       args,
+		unmark:     true,
+	}
+}
+
+var _ Command = Mark{}
+
+// Run registers (or unregisters) the migration given by the 'name' option and displays it.
+func (command Mark) Run() error {
+	var migration models.Migration
+	var err error
+	if command.unmark {
+		migration, err = command.bookkeeper.UnmarkAsRun(command.args.MigrationName)
+	} else {
+		migration, err = command.bookkeeper.MarkAsRun(command.args.MigrationName)
+	}
+
+	if err != nil {
+		command.display.DisplayError(err)
+		return err
+	}
+
+	command.display.DisplayManuallyMarkedMigration(migration)
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Migrate is a command that runs the migrations that have not been run yet.
+type Migrate struct {
+	runner  services.Runner
+	display services.Display
+}
+
+// NewMigrateCommand builds a Migrate.
+func NewMigrateCommand(runner services.Runner, display services.Display) Migrate {
+	return Migrate{
+		runner:  runner,
+		display: display,
+	}
+}
+
+var _ Command = Migrate{}
+
+// Run runs the migrations and displays the result, returning a MigrationError if any of them failed.
+func (command Migrate) Run() error {
+	result, err := command.runner.RunMigrations()
+	if err != nil {
+		command.display.DisplayErrorWithMessage(err, "something went wrong while running migrations")
+		return err
+	}
+
+	command.display.DisplayRunMigrations(result)
+
+	return services.ErrorFromRunMigrations(result)
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// MigrateSchemas is a command that runs the migrations that have not been run yet on several targets (e.g. one
+// schema per tenant).
+type MigrateSchemas struct {
+	multiRunner services.MultiRunner
+	targets     []services.RunTarget
+	display     services.ExtendedDisplay
+}
+
+// NewMigrateSchemasCommand builds a MigrateSchemas.
+func NewMigrateSchemasCommand(
+	multiRunner services.MultiRunner,
+	targets []services.RunTarget,
+	display services.ExtendedDisplay,
+) MigrateSchemas {
+	return MigrateSchemas{
+		multiRunner: multiRunner,
+		targets:     targets,
+		display:     display,
+	}
+}
+
+var _ Command = MigrateSchemas{}
+
+// Run runs the migrations on every target and displays a summary, returning the error of the first failed target, if
+// any (see MultiRunResult.Err).
+func (command MigrateSchemas) Run() error {
+	result, err := command.multiRunner.RunMigrations(command.targets)
+	if err != nil {
+		command.display.DisplayErrorWithMessage(err, "something went wrong while running migrations")
+		return err
+	}
+
+	command.display.DisplayMultiRunResult(result)
+
+	return result.Err()
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Plan is a command that outputs the migrations that would be run as a single SQL script.
+type Plan struct {
+	planner    services.Planner
+	fileSystem adapters.FileSystem
+	printer    adapters.Printer
+	output     io.Writer
+	display    services.Display
+	args       services.Arguments
+}
+
+// NewPlanCommand builds a Plan.
+func NewPlanCommand(
+	planner services.Planner,
+	fileSystem adapters.FileSystem,
+	printer adapters.Printer,
+	display services.Display,
+	args services.Arguments,
+) Plan {
+	return Plan{
+		planner:    planner,
+		fileSystem: fileSystem,
+		printer:    printer,
+		output:     os.Stdout,
+		display:    display,
+		args:       args,
+	}
+}
+
+var _ Command = Plan{}
+
+// WithOutput returns a copy of the Plan that writes the plan to the given writer instead of os.Stdout (when there is
+// no output file).
+func (command Plan) WithOutput(output io.Writer) Plan {
+	command.output = output
+
+	return command
+}
+
+// Run writes the plan to the output file, if any, or to stdout.
+func (command Plan) Run() error {
+	plan, err := command.planner.GetPlan()
+	if err != nil {
+		command.display.DisplayError(err)
+		return err
+	}
+
+	if command.args.OutputPath == "" {
+		return command.printer.Print(command.output, "%s", plan)
+	}
+
+	err = command.fileSystem.WriteFile(command.args.OutputPath, []byte(plan), fs.FileMode(0644))
+	if err != nil {
+		err = errors.Wrapf(err, "failed to write the plan to [%s]", command.args.OutputPath)
+		command.display.DisplayError(err)
+		return err
+	}
+
+	command.display.DisplayInfo(fmt.Sprintf("plan written to %s", command.args.OutputPath))
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Protect is a command that marks the DB as a protected environment (or removes the mark), so migrating it has to be
+// explicitly allowed.
+type Protect struct {
+	guard     services.Guard
+	display   services.Display
+	args      services.Arguments
+	unprotect bool
+}
+
+// NewProtectCommand builds a Protect that marks the DB as the environment given as argument (production by default).
+func NewProtectCommand(guard services.Guard, display services.Display, args services.Arguments) Protect {
+	return Protect{
+		guard:   guard,
+		display: display,
+		args:    args,
+	}
+}
+
+// NewUnprotectCommand builds a Protect that removes the mark.
+func NewUnprotectCommand(guard services.Guard, display services.Display, args services.Arguments) Protect {
+	return Protect{
+		guard:     guard,
+		display:   display,
+		args:      args,
+		unprotect: true,
+	}
+}
+
+var _ Command = Protect{}
+
+// Run marks (or unmarks) the DB. Removing the mark needs the 'allow-protected' option (or
+// services.EnvVarAllowProtected).
+func (command Protect) Run() error {
+	if command.unprotect {
+		return command.runUnprotect()
+	}
+
+	environment := services.DefaultProtectedEnvironment
+	if len(command.args.CommandArguments) > 0 {
+		environment = command.args.CommandArguments[0]
+	}
+
+	err := command.guard.Protect(environment)
+	if err != nil {
+		command.display.DisplayErrorWithMessage(err, "something went wrong while protecting the DB")
+		return err
+	}
+
+	command.display.DisplayInfo(fmt.Sprintf("The DB is protected as [%s]", environment))
+
+	return nil
+}
+
+func (command Protect) runUnprotect() error {
+	if !command.args.AllowProtected {
+		err := services.NewArgumentsError(errors.Errorf(
+			"unprotect changes a protected DB (use the 'allow-protected' option or set %s=true)",
+			services.EnvVarAllowProtected,
+		))
+		command.display.DisplayError(err)
+		return err
+	}
+
+	err := command.guard.Unprotect()
+	if err != nil {
+		command.display.DisplayErrorWithMessage(err, "something went wrong while removing the protection of the DB")
+		return err
+	}
+
+	command.display.DisplayInfo("The DB is not protected anymore")
+
+	return nil
+}
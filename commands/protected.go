@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// ProtectedDBCommand is a command that refuses to run another one, which changes the DB, on a protected DB (see
+// services.Guard) unless it's allowed.
+type ProtectedDBCommand struct {
+	guard   services.Guard
+	command Command
+	display services.Display
+	args    services.Arguments
+}
+
+// NewProtectedDBCommand builds a ProtectedDBCommand that runs the given command if the DB is not protected.
+func NewProtectedDBCommand(
+	guard services.Guard,
+	command Command,
+	display services.Display,
+	args services.Arguments,
+) ProtectedDBCommand {
+	return ProtectedDBCommand{
+		guard:   guard,
+		command: command,
+		display: display,
+		args:    args,
+	}
+}
+
+var _ Command = ProtectedDBCommand{}
+
+// Run runs the command. A protected DB needs the 'allow-protected' option (or services.EnvVarAllowProtected).
+func (command ProtectedDBCommand) Run() error {
+	if command.args.AllowProtected {
+		return command.command.Run()
+	}
+
+	environment, err := command.guard.GetProtectedEnvironment()
+	if err != nil {
+		command.display.DisplayErrorWithMessage(err, "something went wrong while checking the DB")
+		return err
+	}
+
+	if environment != "" {
+		err = services.NewArgumentsError(errors.Errorf(
+			"the DB is protected as [%s] (use the 'allow-protected' option or set %s=true to run %s on it)",
+			environment,
+			services.EnvVarAllowProtected,
+			command.args.Command,
+		))
+		command.display.DisplayError(err)
+		return err
+	}
+
+	return command.command.Run()
+}
@@ -0,0 +1,71 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jimenezmaximiliano/migrations/commands"
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestChangingAProtectedDBIsRefused(test *testing.T) {
+	test.Parallel()
+
+	guard := &mocks.Guard{}
+	defer guard.AssertExpectations(test)
+	guard.On("GetProtectedEnvironment").Return("production", nil).Once()
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	display.On("DisplayError", mock.MatchedBy(func(err error) bool {
+		return assert.Contains(test, err.Error(), "the DB is protected as [production]") &&
+			assert.Contains(test, err.Error(), "to run redo on it")
+	})).Once()
+	runs := 0
+
+	command := commands.NewProtectedDBCommand(
+		guard,
+		migrateCommand{runs: &runs},
+		display,
+		services.Arguments{Command: "redo"},
+	)
+
+	err := command.Run()
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+	assert.Equal(test, 0, runs)
+}
+
+func TestChangingANotProtectedDB(test *testing.T) {
+	test.Parallel()
+
+	guard := &mocks.Guard{}
+	defer guard.AssertExpectations(test)
+	guard.On("GetProtectedEnvironment").Return("", nil).Once()
+	runs := 0
+
+	command := commands.NewProtectedDBCommand(guard, migrateCommand{runs: &runs}, &mocks.Display{}, services.Arguments{})
+
+	assert.Nil(test, command.Run())
+	assert.Equal(test, 1, runs)
+}
+
+func TestChangingAProtectedDBWhenItsAllowed(test *testing.T) {
+	test.Parallel()
+
+	guard := &mocks.Guard{}
+	defer guard.AssertExpectations(test)
+	runs := 0
+
+	command := commands.NewProtectedDBCommand(
+		guard,
+		migrateCommand{runs: &runs},
+		&mocks.Display{},
+		services.Arguments{AllowProtected: true},
+	)
+
+	assert.Nil(test, command.Run())
+	assert.Equal(test, 1, runs)
+}
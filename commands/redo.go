@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Redo is a command that reverts the last run migrations and runs them again (local development only).
+type Redo struct {
+	redoer  services.Redoer
+	display services.ExtendedDisplay
+	args    services.Arguments
+}
+
+// NewRedoCommand builds a Redo.
+func NewRedoCommand(
+	redoer services.Redoer,
+	display services.ExtendedDisplay,
+	args services.Arguments,
+) Redo {
+	return Redo{
+		redoer:  redoer,
+		display: display,
+		args:    args,
+	}
+}
+
+var _ Command = Redo{}
+
+// Run redoes the migrations and displays the result. It refuses to run unless the 'dev' option
+// (or services.EnvVarDevelopment) is set.
+func (command Redo) Run() error {
+	if !command.args.Development {
+		err := services.NewArgumentsError(errors.Errorf(
+			"redo reverts migrations and it's meant for local development only (use the 'dev' option or set %s=true)",
+			services.EnvVarDevelopment,
+		))
+		command.display.DisplayError(err)
+		return err
+	}
+
+	result, err := command.redoer.Redo(command.args.RedoSteps)
+	command.display.DisplayRedo(result)
+	if err != nil {
+		command.display.DisplayErrorWithMessage(err, "something went wrong while redoing migrations")
+		return err
+	}
+
+	return nil
+}
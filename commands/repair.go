@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Repair is a command that reconciles the migrations table with the migrations directory.
+type Repair struct {
+	repairer services.Repairer
+	prompter adapters.Prompter
+	display  services.ExtendedDisplay
+	args     services.Arguments
+}
+
+// NewRepairCommand builds a Repair.
+func NewRepairCommand(
+	repairer services.Repairer,
+	prompter adapters.Prompter,
+	display services.ExtendedDisplay,
+	args services.Arguments,
+) Repair {
+	return Repair{
+		repairer: repairer,
+		prompter: prompter,
+		display:  display,
+		args:     args,
+	}
+}
+
+var _ Command = Repair{}
+
+// Run displays the repair plan and applies it after confirmation (unless the 'yes' option is set). Declining returns a
+// NotConfirmedError.
+func (command Repair) Run() error {
+	plan, err := command.repairer.GetRepairPlan()
+	if err != nil {
+		command.display.DisplayErrorWithMessage(err, "failed to check the migrations table")
+		return err
+	}
+
+	command.display.DisplayRepairPlan(plan)
+	if len(plan.Actions) == 0 {
+		return nil
+	}
+
+	if !command.args.AssumeYes {
+		confirmed, err := command.prompter.Confirm("Apply the repair plan to the migrations table?")
+		if err != nil {
+			err = services.NewArgumentsError(err)
+			command.display.DisplayErrorWithMessage(err, "use the 'yes' option to repair without confirmation")
+			return err
+		}
+
+		if !confirmed {
+			err := services.NewNotConfirmedError(errors.New("the repair plan was not confirmed, nothing has been repaired"))
+			command.display.DisplayError(err)
+			return err
+		}
+	}
+
+	err = command.repairer.Repair(plan)
+	if err != nil {
+		command.display.DisplayErrorWithMessage(err, "something went wrong while repairing the migrations table")
+		return err
+	}
+
+	command.display.DisplayRepaired(plan)
+
+	return nil
+}
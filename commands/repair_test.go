@@ -0,0 +1,37 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jimenezmaximiliano/migrations/commands"
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestDecliningTheRepairPlan(test *testing.T) {
+	test.Parallel()
+
+	plan := services.RepairPlan{Actions: []services.RepairAction{
+		{Kind: services.RepairActionRemoveDuplicates, MigrationName: "1_a.sql"},
+	}}
+	repairer := &mocks.Repairer{}
+	defer repairer.AssertExpectations(test)
+	repairer.On("GetRepairPlan").Return(plan, nil).Once()
+	prompter := &mocks.Prompter{}
+	defer prompter.AssertExpectations(test)
+	prompter.On("Confirm", "Apply the repair plan to the migrations table?").Return(false, nil).Once()
+	display := &mocks.ExtendedDisplay{}
+	defer display.AssertExpectations(test)
+	display.On("DisplayRepairPlan", plan).Once()
+	display.On("DisplayError", mock.Anything).Once()
+
+	command := commands.NewRepairCommand(repairer, prompter, display, services.Arguments{})
+
+	err := command.Run()
+
+	assert.Equal(test, services.ExitCodeNotConfirmed, services.ExitCode(err))
+	repairer.AssertNotCalled(test, "Repair", mock.Anything)
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Squash is a command that collapses every migration up to an order into a single migration.
+type Squash struct {
+	squasher services.Squasher
+	display  services.ExtendedDisplay
+	args     services.Arguments
+}
+
+// NewSquashCommand builds a Squash.
+func NewSquashCommand(
+	squasher services.Squasher,
+	display services.ExtendedDisplay,
+	args services.Arguments,
+) Squash {
+	return Squash{
+		squasher: squasher,
+		display:  display,
+		args:     args,
+	}
+}
+
+var _ Command = Squash{}
+
+// Run squashes the migrations and displays the result.
+func (command Squash) Run() error {
+	name := command.args.MigrationName
+	if name != "" {
+		var err error
+		name, err = services.SanitizeMigrationName(name, command.args.NameStyle)
+		if err != nil {
+			command.display.DisplayError(err)
+			return err
+		}
+	}
+
+	result, err := command.squasher.Squash(command.args.MigrationsPath, command.args.SquashOrder, name)
+	if err != nil {
+		command.display.DisplayErrorWithMessage(err, "something went wrong while squashing migrations")
+		return err
+	}
+
+	command.display.DisplaySquash(result)
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Validate is a command that checks the migrations directory without connecting to the DB.
+type Validate struct {
+	validator services.Validator
+	display   services.ExtendedDisplay
+	args      services.Arguments
+}
+
+// NewValidateCommand builds a Validate.
+func NewValidateCommand(
+	validator services.Validator,
+	display services.ExtendedDisplay,
+	args services.Arguments,
+) Validate {
+	return Validate{
+		validator: validator,
+		display:   display,
+		args:      args,
+	}
+}
+
+var _ Command = Validate{}
+
+// Run displays every problem found on the migrations directories, returning a ValidationError if there is any.
+func (command Validate) Run() error {
+	problems, err := command.validator.Validate(
+		command.args.MigrationsPath,
+		command.args.GetAdditionalMigrationsPaths()...,
+	)
+	if err != nil {
+		command.display.DisplayError(err)
+		return err
+	}
+
+	command.display.DisplayValidationProblems(problems)
+
+	if len(problems) > 0 {
+		return services.NewValidationError(problems)
+	}
+
+	return nil
+}
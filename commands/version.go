@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// Version is a command that displays the version of the migrations module.
+type Version struct {
+	readBuildInfo services.ReadBuildInfo
+	display       services.Display
+}
+
+// NewVersionCommand builds a Version.
+func NewVersionCommand(readBuildInfo services.ReadBuildInfo, display services.Display) Version {
+	return Version{
+		readBuildInfo: readBuildInfo,
+		display:       display,
+	}
+}
+
+var _ Command = Version{}
+
+// Run displays the version.
+func (command Version) Run() error {
+	command.display.DisplayInfo("migrations " + services.GetVersion(command.readBuildInfo))
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/commands"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// CommandContext is what a CustomCommand gets to build its command: the parsed arguments (see
+// services.Arguments.GetOption for its custom options), the display used by the built-in commands and, if the
+// command needs it, the DB connection.
+type CommandContext struct {
+	// Context is the one given to RunCommand (context.Background() with RunMigrationsCommand).
+	Context   context.Context
+	Arguments services.Arguments
+	Display   services.ExtendedDisplay
+	// DB is nil unless CustomCommand.NeedsDB is set. It's closed after the command runs.
+	DB *sql.DB
+}
+
+// CustomCommand is a command added to the built-in ones (see WithCommand).
+type CustomCommand struct {
+	// Spec documents the command for the help command. Its options can be built-in options or the ones in Options.
+	Spec services.CommandSpec
+	// Options are the options the command adds. Like the built-in ones, they can be set with a command option, an
+	// environment variable (OptionSpec.EnvVar) or the config file.
+	Options []services.OptionSpec
+	// NeedsDB opens the DB connection (with the SetupDB given to RunMigrationsCommand) before building the command. If
+	// the 'connect-timeout' option is set, it waits for the DB to be reachable.
+	NeedsDB bool
+	Build   func(commandContext CommandContext) (commands.Command, error)
+}
+
+// WithCommand adds a custom command (e.g. seeding the DB) to RunMigrationsCommand. Its name and options cannot clash
+// with the built-in ones.
+func WithCommand(command CustomCommand) CommandOption {
+	return func(settings *commandSettings) {
+		if settings.err != nil {
+			return
+		}
+
+		settings.err = registerCustomCommand(settings, command)
+	}
+}
+
+func registerCustomCommand(settings *commandSettings, command CustomCommand) error {
+	if command.Build == nil {
+		return errors.Errorf("the command [%s] has no Build function", command.Spec.Name)
+	}
+
+	for _, option := range command.Options {
+		err := settings.registry.RegisterOption(option, false)
+		if err != nil {
+			return errors.Wrapf(err, "could not register the command [%s]", command.Spec.Name)
+		}
+	}
+
+	err := settings.registry.RegisterCommand(command.Spec)
+	if err != nil {
+		return errors.Wrapf(err, "could not register the command [%s]", command.Spec.Name)
+	}
+
+	settings.customCommands[command.Spec.Name] = command
+
+	return nil
+}
+
+func getCustomCommand(
+	ctx context.Context,
+	setupDB SetupDB,
+	displayService services.ExtendedDisplay,
+	arguments services.Arguments,
+	customCommand CustomCommand,
+) (commands.Command, error) {
+	commandContext := CommandContext{
+		Context:   ctx,
+		Arguments: arguments,
+		Display:   displayService,
+	}
+
+	if customCommand.NeedsDB {
+		DB, err := openDB(setupDB)
+		if err != nil {
+			return nil, err
+		}
+		commandContext.DB = DB
+
+		if arguments.ConnectionRetry.Timeout > 0 {
+			dbRepository := withConnectionRetry(ctx, getDBRepository(DB), arguments, displayService.DisplayConnectionAttempt)
+			err = dbRepository.Ping()
+			if err != nil {
+				return nil, services.NewDBConnectionError(errors.Wrap(err, "failed to connect to the DB"))
+			}
+		}
+	}
+
+	return customCommand.Build(commandContext)
+}
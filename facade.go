@@ -1,8 +1,15 @@
 package migrations
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"io"
 	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/pkg/errors"
 
 	"github.com/jimenezmaximiliano/migrations/adapters"
 	"github.com/jimenezmaximiliano/migrations/commands"
@@ -23,55 +30,408 @@ func RunMigrations(DB *sql.DB, migrationsDirectoryAbsolutePath string) (models.C
 	return migrationRunner.RunMigrations()
 }
 
+// RunMigrationsFromDirectories runs the migrations of several directories, merged into one run ordered by their order
+// (which must be unique across directories). The migrations of every directory but the first one are registered on the
+// migrations table prefixed by the name of their source ({source}/{name}): name them with {source}={path}, or the base
+// name of the directory is used.
+func RunMigrationsFromDirectories(DB *sql.DB, migrationsDirectoryAbsolutePaths []string) (models.Collection, error) {
+	if len(migrationsDirectoryAbsolutePaths) == 0 {
+		return models.Collection{}, services.NewArgumentsError(errors.New("missing migrations directories"))
+	}
+
+	arguments := services.Arguments{
+		MigrationsPath:  migrationsDirectoryAbsolutePaths[0],
+		MigrationsPaths: migrationsDirectoryAbsolutePaths,
+	}
+	fileRepository := repositories.NewFileRepository(adapters.IOUtilAdapter{})
+	migrationRunner := getMigrationRunner(DB, fileRepository, arguments)
+
+	return migrationRunner.RunMigrations()
+}
+
+// RunMigrationsWithMetrics runs the migrations like RunMigrations does, reporting the result and the duration of
+// each migration to the given metrics (see services.NewMetricsCollector).
+func RunMigrationsWithMetrics(
+	DB *sql.DB,
+	migrationsDirectoryAbsolutePath string,
+	metrics services.Metrics,
+) (models.Collection, error) {
+	arguments := services.Arguments{
+		MigrationsPath: migrationsDirectoryAbsolutePath,
+	}
+	fileRepository := repositories.NewFileRepository(adapters.IOUtilAdapter{})
+	migrationRunner := getMigrationRunner(DB, fileRepository, arguments, services.WithMetrics(metrics))
+
+	return migrationRunner.RunMigrations()
+}
+
+// RunMigrationsWithConnectionRetry runs the migrations like RunMigrations does, retrying to connect to the DB (e.g.
+// while it's starting) with an exponential backoff. Each failed attempt is notified to the progress, if any.
+func RunMigrationsWithConnectionRetry(
+	ctx context.Context,
+	DB *sql.DB,
+	migrationsDirectoryAbsolutePath string,
+	retry services.ConnectionRetry,
+	progress services.ConnectionProgress,
+) (models.Collection, error) {
+	arguments := services.Arguments{
+		MigrationsPath: migrationsDirectoryAbsolutePath,
+	}
+	fileRepository := repositories.NewFileRepository(adapters.IOUtilAdapter{})
+	migrationRunner := getMigrationRunner(
+		DB,
+		fileRepository,
+		arguments,
+		services.WithConnectionRetry(ctx, retry, progress),
+	)
+
+	return migrationRunner.RunMigrations()
+}
+
+// Baseline creates the migrations table (if needed) and registers every migration up to the given order (inclusive)
+// as run, without running it. Useful to adopt this package on a DB that already has a schema.
+// Returns the migrations that have been registered.
+func Baseline(DB *sql.DB, migrationsDirectoryAbsolutePath string, order uint64) (models.Collection, error) {
+	fileRepository := repositories.NewFileRepository(adapters.IOUtilAdapter{})
+	dbRepository := getDBRepository(DB)
+	migrationFetcher := services.NewFetcherService(dbRepository, fileRepository)
+
+	return services.NewBookkeepingService(migrationFetcher, dbRepository, migrationsDirectoryAbsolutePath).
+		Baseline(order)
+}
+
+// Pending returns the migrations that have not been run yet (or have failed) on the DB, e.g. for readiness probes that
+// must not serve traffic until the schema is up to date. It never runs migrations (it only creates the migrations
+// table if needed).
+func Pending(DB *sql.DB, migrationsDirectoryAbsolutePath string) ([]models.Migration, error) {
+	fileRepository := repositories.NewFileRepository(adapters.IOUtilAdapter{})
+	dbRepository := getDBRepository(DB)
+	migrationFetcher := services.NewFetcherService(dbRepository, fileRepository)
+
+	status, err := services.NewCheckService(migrationFetcher, dbRepository, migrationsDirectoryAbsolutePath).Check()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(status.FailedMigrations, status.PendingMigrations...), nil
+}
+
 // SetupDB is a function that handles the configuration for the DB connection.
 type SetupDB func() (*sql.DB, error)
 
 // RunMigrationsCommand runs migrations as a command (it will output the results to stdout).
-func RunMigrationsCommand(setupDB SetupDB) {
-	displayService := getDisplayService()
-	arguments, argumentsAreValid := getArgumentService(displayService).ParseAndValidate()
-	if !argumentsAreValid {
-		os.Exit(1)
+// It exits with one of the services.ExitCode* codes depending on the category of the failure, if any.
+// If setupDB is nil, the connection is opened with the 'driver' and 'dsn' options (the driver must be registered,
+// see cmd/migrations). Custom commands can be added with WithCommand.
+// It reads os.Args, the environment variables and stdin (see RunCommand to use it as a library).
+func RunMigrationsCommand(setupDB SetupDB, options ...CommandOption) {
+	var args []string
+	if len(os.Args) > 0 {
+		args = os.Args[1:]
 	}
+	options = append([]CommandOption{WithEnvVars(os.Getenv), WithStdin(os.Stdin)}, options...)
 
-	DB, err := setupDB()
+	os.Exit(RunCommand(context.Background(), args, os.Stdout, os.Stderr, setupDB, options...))
+}
+
+// RunCommand runs a command like RunMigrationsCommand does, with the given command line arguments (without the
+// executable), writing to stdout and stderr. It returns one of the services.ExitCode* codes instead of exiting, and
+// it closes the DB connection (opened with setupDB) before returning.
+// It doesn't read environment variables or stdin unless WithEnvVars and WithStdin are given.
+func RunCommand(
+	ctx context.Context,
+	args []string,
+	stdout io.Writer,
+	stderr io.Writer,
+	setupDB SetupDB,
+	options ...CommandOption,
+) int {
+	settings := getCommandSettings(ctx, stdout, stderr, options...)
+	setupDisplayService := getDisplayService(services.ColorModeNever, settings)
+	if settings.err != nil {
+		setupDisplayService.DisplayErrorWithMessage(settings.err, "failed to setup the command")
+		return services.ExitCode(settings.err)
+	}
+
+	arguments, err := getArgumentService(setupDisplayService, settings).
+		WithArguments(args).
+		ParseAndValidateArguments()
 	if err != nil {
-		displayService.DisplayErrorWithMessage(err, "failed to setup the DB")
-		os.Exit(1)
+		return services.ExitCode(err)
 	}
 
+	displayService := getDisplayService(arguments.Color, settings)
+
+	setupDB, closeDB := getClosableSetupDB(setupDB, arguments)
+	defer closeDB()
+
+	command, err := getCommand(setupDB, displayService, arguments, settings)
+	if err != nil {
+		displayService.DisplayErrorWithMessage(err, "failed to setup the command")
+		return services.ExitCode(err)
+	}
+
+	return services.ExitCode(command.Run())
+}
+
+func getCommand(
+	setupDB SetupDB,
+	displayService services.ExtendedDisplay,
+	arguments services.Arguments,
+	settings commandSettings,
+) (commands.Command, error) {
 	fileRepository := repositories.NewFileRepository(adapters.IOUtilAdapter{})
-	migrationRunner := getMigrationRunner(DB, fileRepository, arguments)
 
+	// Commands that don't need a DB connection.
 	switch arguments.Command {
-	case "migrate":
-		result, err := migrationRunner.RunMigrations()
+	case "help":
+		return commands.NewHelpCommand(settings.registry, displayService, arguments), nil
+	case "version":
+		return commands.NewVersionCommand(debug.ReadBuildInfo, displayService), nil
+	case "create":
+		templater := services.NewTemplateService(adapters.IOUtilAdapter{}, adapters.GetGitAuthor)
+		numberer := services.NewNumberingService(
+			fileRepository,
+			adapters.IOUtilAdapter{},
+			arguments.Numbering,
+			arguments.NumberingPadding,
+			arguments.GetAdditionalMigrationsPaths()...,
+		)
+		return commands.NewCreateMigrationCommand(fileRepository, numberer, templater, displayService, arguments), nil
+	case "validate":
+		validator := services.NewValidatorService(adapters.IOUtilAdapter{})
+		return commands.NewValidateCommand(validator, displayService, arguments), nil
+	case "lint":
+		linter, err := services.NewLintService(fileRepository, services.DefaultLintRules(), arguments.LintSeverities)
 		if err != nil {
-			displayService.DisplayErrorWithMessage(err, "something went wrong while running migrations")
-			os.Exit(1)
+			return nil, err
 		}
-		displayService.DisplayRunMigrations(result)
-	case "create":
-		commands.NewCreateMigrationCommand(fileRepository, displayService, arguments).Run()
+		return commands.NewLintCommand(linter, displayService, arguments), nil
+	case "squash":
+		squasher := services.NewSquashService(fileRepository, adapters.IOUtilAdapter{})
+		return commands.NewSquashCommand(squasher, displayService, arguments), nil
 	}
 
-	os.Exit(0)
+	if customCommand, isCustom := settings.customCommands[arguments.Command]; isCustom {
+		return getCustomCommand(settings.ctx, setupDB, displayService, arguments, customCommand)
+	}
+
+	DB, err := openDB(setupDB)
+	if err != nil {
+		return nil, err
+	}
+
+	dbRepository := withConnectionRetry(
+		settings.ctx,
+		getDBRepository(DB),
+		arguments,
+		displayService.DisplayConnectionAttempt,
+	)
+	migrationFetcher := services.NewFetcherService(
+		dbRepository,
+		fileRepository,
+		arguments.GetAdditionalMigrationsPaths()...,
+	)
+
+	switch arguments.Command {
+	case "baseline", "mark-applied", "unmark", "repair", "redo":
+		command := getBookkeepingCommand(fileRepository, migrationFetcher, dbRepository, displayService, arguments, settings)
+		guard := services.NewGuardService(migrationFetcher, dbRepository, arguments.MigrationsPath)
+		return commands.NewProtectedDBCommand(guard, command, displayService, arguments), nil
+	case "plan":
+		planner := services.NewPlanService(migrationFetcher, dbRepository, getDialect(DB), arguments.MigrationsPath)
+		return commands.NewPlanCommand(
+			planner,
+			adapters.IOUtilAdapter{},
+			adapters.PrinterAdapter{},
+			displayService,
+			arguments,
+		).WithOutput(settings.stdout), nil
+	case "check":
+		checker := services.NewCheckService(migrationFetcher, dbRepository, arguments.MigrationsPath)
+		return commands.NewCheckCommand(checker, displayService), nil
+	case "wait":
+		checker := services.NewCheckService(migrationFetcher, dbRepository, arguments.MigrationsPath)
+		return commands.NewWaitCommand(settings.ctx, checker, displayService, arguments), nil
+	case "protect":
+		guard := services.NewGuardService(migrationFetcher, dbRepository, arguments.MigrationsPath)
+		return commands.NewProtectCommand(guard, displayService, arguments), nil
+	case "unprotect":
+		guard := services.NewGuardService(migrationFetcher, dbRepository, arguments.MigrationsPath)
+		return commands.NewUnprotectCommand(guard, displayService, arguments), nil
+	}
+
+	if len(arguments.Schemas) > 0 {
+		targets, err := getSchemaRunTargets(
+			settings.ctx,
+			DB,
+			fileRepository,
+			arguments,
+			displayService.DisplayConnectionAttempt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		multiRunner := services.NewMultiRunnerService(int(arguments.Concurrency))
+		return commands.NewGuardedMigrateSchemasCommand(
+			getSchemaGuards(settings.ctx, DB, fileRepository, arguments, displayService.DisplayConnectionAttempt),
+			adapters.NewTerminalPrompter(settings.stdin, settings.stdout),
+			commands.NewMigrateSchemasCommand(multiRunner, targets, displayService),
+			displayService,
+			arguments,
+		), nil
+	}
+
+	migrationRunner := services.NewRunnerService(
+		migrationFetcher,
+		dbRepository,
+		arguments.MigrationsPath,
+		services.WithProgress(services.NewDisplayProgress(displayService), services.DefaultHeartbeatInterval),
+	)
+
+	prompter := adapters.NewTerminalPrompter(settings.stdin, settings.stdout)
+
+	return commands.NewGuardedMigrateCommand(
+		services.NewGuardService(migrationFetcher, dbRepository, arguments.MigrationsPath),
+		prompter,
+		commands.NewMigrateCommand(migrationRunner, displayService),
+		displayService,
+		arguments,
+	), nil
 }
 
-func getMigrationRunner(DB *sql.DB, fileRepository repositories.FileRepository, arguments services.Arguments) services.Runner {
-	dbAdapter := adapters.NewDBAdapter(DB)
-	dbRepository := repositories.NewDBRepository(dbAdapter)
-	migrationFetcher := services.NewFetcherService(dbRepository, fileRepository)
+// getBookkeepingCommand returns the commands that change the migrations table without running pending migrations, so
+// they are refused on a protected DB (see commands.ProtectedDBCommand).
+func getBookkeepingCommand(
+	fileRepository repositories.FileRepository,
+	migrationFetcher services.Fetcher,
+	dbRepository repositories.DBRepository,
+	displayService services.ExtendedDisplay,
+	arguments services.Arguments,
+	settings commandSettings,
+) commands.Command {
+	switch arguments.Command {
+	case "mark-applied":
+		bookkeeper := services.NewBookkeepingService(migrationFetcher, dbRepository, arguments.MigrationsPath)
+		return commands.NewMarkAppliedCommand(bookkeeper, displayService, arguments)
+	case "unmark":
+		bookkeeper := services.NewBookkeepingService(migrationFetcher, dbRepository, arguments.MigrationsPath)
+		return commands.NewUnmarkCommand(bookkeeper, displayService, arguments)
+	case "repair":
+		repairer := services.NewRepairService(fileRepository, dbRepository, arguments.MigrationsPath)
+		prompter := adapters.NewTerminalPrompter(settings.stdin, settings.stdout)
+		return commands.NewRepairCommand(repairer, prompter, displayService, arguments)
+	case "redo":
+		redoer := services.NewRedoService(migrationFetcher, fileRepository, dbRepository, arguments.MigrationsPath)
+		return commands.NewRedoCommand(redoer, displayService, arguments)
+	}
+
+	bookkeeper := services.NewBookkeepingService(migrationFetcher, dbRepository, arguments.MigrationsPath)
+
+	return commands.NewBaselineCommand(bookkeeper, displayService, arguments)
+}
+
+func getMigrationRunner(
+	DB *sql.DB,
+	fileRepository repositories.FileRepository,
+	arguments services.Arguments,
+	options ...services.RunnerOption,
+) services.Runner {
+	dbRepository := getDBRepository(DB)
+	migrationFetcher := services.NewFetcherService(
+		dbRepository,
+		fileRepository,
+		arguments.GetAdditionalMigrationsPaths()...,
+	)
+
+	return services.NewRunnerService(migrationFetcher, dbRepository, arguments.MigrationsPath, options...)
+}
+
+// getClosableSetupDB returns a SetupDB that opens the DB connection with setupDB or, if it's nil, with the 'driver'
+// and 'dsn' options, and a function that closes the connection if it has been opened.
+func getClosableSetupDB(setupDB SetupDB, arguments services.Arguments) (SetupDB, func()) {
+	if setupDB == nil {
+		setupDB = getSetupDBFromDSN(arguments)
+	}
+
+	var DB *sql.DB
+	closableSetupDB := func() (*sql.DB, error) {
+		var err error
+		DB, err = setupDB()
+
+		return DB, err
+	}
+	closeDB := func() {
+		if DB != nil {
+			_ = DB.Close()
+		}
+	}
+
+	return closableSetupDB, closeDB
+}
+
+// withConnectionRetry returns a DBRepository that retries to connect to the DB if the 'connect-timeout' option is set
+// (see services.NewRetryingDBRepository), or the given one otherwise.
+func withConnectionRetry(
+	ctx context.Context,
+	dbRepository repositories.DBRepository,
+	arguments services.Arguments,
+	progress services.ConnectionProgress,
+) repositories.DBRepository {
+	if arguments.ConnectionRetry.Timeout <= 0 {
+		return dbRepository
+	}
+
+	return services.NewRetryingDBRepository(ctx, dbRepository, arguments.ConnectionRetry, progress)
+}
+
+// openDB opens the DB connection with setupDB, returning a DBSetupError if it fails.
+func openDB(setupDB SetupDB) (*sql.DB, error) {
+	DB, err := setupDB()
+	if err != nil {
+		return nil, services.NewDBSetupError(err)
+	}
+
+	return DB, nil
+}
+
+// getSetupDBFromDSN returns a SetupDB that opens a connection with the 'driver' and 'dsn' options.
+func getSetupDBFromDSN(arguments services.Arguments) SetupDB {
+	return func() (*sql.DB, error) {
+		driverName, dataSourceName, err := services.ResolveDataSource(arguments.Driver, arguments.DSN)
+		if err != nil {
+			return nil, err
+		}
+
+		DB, err := sql.Open(driverName, dataSourceName)
+
+		return DB, errors.Wrapf(err, "could not open a [%s] DB connection", driverName)
+	}
+}
+
+func getDBRepository(DB *sql.DB) repositories.DBRepository {
+	return repositories.NewDBRepositoryForDialect(adapters.NewDBAdapter(DB), getDialect(DB))
+}
+
+// getDialect guesses the SQL dialect from the type of the DB driver (MySQL unless it's a SQLite driver).
+func getDialect(DB *sql.DB) string {
+	if strings.Contains(strings.ToLower(fmt.Sprintf("%T", DB.Driver())), "sqlite") {
+		return repositories.DialectSQLite
+	}
 
-	return services.NewRunnerService(migrationFetcher, dbRepository, arguments.MigrationsPath)
+	return repositories.DialectMySQL
 }
 
-func getDisplayService() services.DisplayService {
-	printerAdapter := adapters.PrinterAdapter{}
+func getDisplayService(colorMode string, settings commandSettings) services.DisplayService {
+	printerAdapter := services.NewColorPrinter(adapters.PrinterAdapter{}, colorMode).WithEnvVars(settings.getEnvVar)
 
-	return services.NewDisplayService(printerAdapter)
+	return services.NewDisplayService(printerAdapter).
+		WithCommandRegistry(settings.registry).
+		WithWriters(settings.stdout, settings.stderr)
 }
 
-func getArgumentService(displayService services.Display) services.CommandArgumentService {
-	return services.NewCommandArgumentService(displayService, adapters.NewArgumentParser())
+func getArgumentService(displayService services.Display, settings commandSettings) services.CommandArgumentService {
+	return services.NewCommandArgumentService(displayService, adapters.NewArgumentParser()).
+		WithCommandRegistry(settings.registry).
+		WithEnvVars(settings.getEnvVar)
 }
@@ -2,11 +2,15 @@ package migrations_test
 
 import (
 	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
 
 	"github.com/jimenezmaximiliano/migrations"
 	"github.com/jimenezmaximiliano/migrations/models"
@@ -99,3 +103,84 @@ func TestRunningMigrationsStopsWhenAMigrationFails(test *testing.T) {
 	assert.Equal(test, models.StatusFailed, result.GetAll()[2].GetStatus())
 	assert.Equal(test, models.StatusNotRun, result.GetAll()[3].GetStatus())
 }
+
+func TestBaseliningMigrations(test *testing.T) {
+	db, err := sql.Open("mysql", "user:password@/db")
+	require.Nil(test, err)
+
+	assert.Nil(test, db.Ping())
+
+	_, err = db.Exec("DROP TABLE IF EXISTS gophers")
+	require.Nil(test, err)
+
+	_, err = db.Exec("DROP TABLE IF EXISTS migrations")
+	require.Nil(test, err)
+
+	result, err := migrations.Baseline(db, "./fixtures/create_and_insert", 20200318001000)
+	require.Nil(test, err)
+	require.Len(test, result.GetAll(), 1)
+	assert.Equal(test, "20200318001000_createGophersTable.sql", result.GetAll()[0].GetName())
+
+	// The gophers table was not created, so the remaining migration fails.
+	result, err = migrations.RunMigrations(db, "./fixtures/create_and_insert")
+	require.Nil(test, err)
+	require.Len(test, result.GetAll(), 1)
+	assert.Equal(test, models.StatusFailed, result.GetAll()[0].GetStatus())
+}
+
+func TestRunningMigrationsOnSQLite(test *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(test.TempDir(), "db.sqlite"))
+	require.Nil(test, err)
+	defer func() {
+		assert.Nil(test, db.Close())
+	}()
+
+	result, err := migrations.RunMigrations(db, "./fixtures/sqlite")
+	require.Nil(test, err)
+	require.Len(test, result.GetAll(), 2)
+
+	for _, currentMigration := range result.GetAll() {
+		assert.Equal(test, models.StatusSuccessful, currentMigration.GetStatus())
+	}
+
+	result, err = migrations.RunMigrations(db, "./fixtures/sqlite")
+	require.Nil(test, err)
+	assert.Empty(test, result.GetAll())
+
+	var gophers int
+	require.Nil(test, db.QueryRow("SELECT COUNT(*) FROM gophers").Scan(&gophers))
+	assert.Equal(test, 2, gophers)
+}
+
+func TestRunningMigrationsFromSeveralDirectoriesOnSQLite(test *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(test.TempDir(), "db.sqlite"))
+	require.Nil(test, err)
+	defer func() {
+		assert.Nil(test, db.Close())
+	}()
+
+	pluginDirectory := filepath.Join(test.TempDir(), "plugin")
+	require.Nil(test, os.Mkdir(pluginDirectory, 0755))
+	query := []byte("ALTER TABLE gophers ADD COLUMN color TEXT;")
+	require.Nil(test, ioutil.WriteFile(filepath.Join(pluginDirectory, "20200401001000_addColor.sql"), query, 0644))
+
+	directories := []string{"./fixtures/sqlite", pluginDirectory}
+	result, err := migrations.RunMigrationsFromDirectories(db, directories)
+	require.Nil(test, err)
+	require.Len(test, result.GetAll(), 3)
+	assert.Equal(test, "plugin/20200401001000_addColor.sql", models.GetQualifiedName(result.GetAll()[1]))
+
+	for _, currentMigration := range result.GetAll() {
+		assert.Equal(test, models.StatusSuccessful, currentMigration.GetStatus())
+	}
+
+	var registered int
+	err = db.QueryRow("SELECT COUNT(*) FROM migrations WHERE migration = 'plugin/20200401001000_addColor.sql'").
+		Scan(&registered)
+	require.Nil(test, err)
+	assert.Equal(test, 1, registered)
+
+	result, err = migrations.RunMigrationsFromDirectories(db, directories)
+	require.Nil(test, err)
+	assert.Empty(test, result.GetAll())
+}
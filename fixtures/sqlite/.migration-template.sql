@@ -0,0 +1,2 @@
+-- {{name}} ({{date}})
+SELECT 1;
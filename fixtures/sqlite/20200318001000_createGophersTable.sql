@@ -0,0 +1,4 @@
+CREATE TABLE gophers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT
+);
\ No newline at end of file
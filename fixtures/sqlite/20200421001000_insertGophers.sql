@@ -0,0 +1,2 @@
+INSERT INTO gophers (name) VALUES ('Maxi');
+INSERT INTO gophers (name) VALUES ('Gopher');
\ No newline at end of file
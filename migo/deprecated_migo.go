@@ -8,7 +8,7 @@ import (
 	"github.com/jimenezmaximiliano/migrations/services"
 )
 
-// Deprecated: use the migrations binary to create new migrations instead.
+// Deprecated: use the migrations binary (see cmd/migrations) to create new migrations instead.
 func main() {
 	commandService := services.NewCommandService(adapters.NewArgumentParser())
 	arguments := commandService.ParseArguments()
@@ -20,8 +20,8 @@ func (collection *Collection) Add(migration Migration) error {
 	for _, currentMigration := range collection.migrations {
 		if currentMigration.GetOrder() == migration.GetOrder() {
 			return errors.Errorf("two migrations cannot have the same order [%s] [%s]",
-				currentMigration.GetName(),
-				migration.GetName())
+				GetQualifiedName(currentMigration),
+				GetQualifiedName(migration))
 		}
 	}
 
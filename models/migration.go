@@ -35,17 +35,47 @@ type Migration interface {
 	GetError() error
 }
 
+// SourcedMigration is an optional interface of a Migration that comes from one of several migrations directories.
+type SourcedMigration interface {
+	GetSource() string
+	GetQualifiedName() string
+}
+
+// GetSource returns the source of the migration (see MigrationContainer.GetSource), empty if it doesn't implement
+// SourcedMigration.
+func GetSource(migration Migration) string {
+	sourcedMigration, ok := migration.(SourcedMigration)
+	if !ok {
+		return ""
+	}
+
+	return sourcedMigration.GetSource()
+}
+
+// GetQualifiedName returns the name the migration is registered with (see MigrationContainer.GetQualifiedName), its
+// name if it doesn't implement SourcedMigration.
+func GetQualifiedName(migration Migration) string {
+	sourcedMigration, ok := migration.(SourcedMigration)
+	if !ok {
+		return migration.GetName()
+	}
+
+	return sourcedMigration.GetQualifiedName()
+}
+
 type MigrationContainer struct {
 	absolutePath string
 	name         string
+	source       string
 	status       int8
 	query        string
 	err          error
 	order        uint64
 }
 
-// Ensure MigrationContainer implements Migration
+// Ensure MigrationContainer implements Migration and SourcedMigration
 var _ Migration = MigrationContainer{}
+var _ SourcedMigration = MigrationContainer{}
 
 // GetAbsolutePath returns the absolute path of the MigrationContainer file.
 func (thisMigration MigrationContainer) GetAbsolutePath() string {
@@ -57,6 +87,22 @@ func (thisMigration MigrationContainer) GetName() string {
 	return thisMigration.name
 }
 
+// GetSource returns the name of the migrations directory the MigrationContainer comes from (empty for the main
+// directory).
+func (thisMigration MigrationContainer) GetSource() string {
+	return thisMigration.source
+}
+
+// GetQualifiedName returns the name of the MigrationContainer prefixed by its source ({source}/{name}), if any.
+// It's the name registered on the migrations table, so migrations from different directories don't collide.
+func (thisMigration MigrationContainer) GetQualifiedName() string {
+	if thisMigration.source == "" {
+		return thisMigration.name
+	}
+
+	return thisMigration.source + "/" + thisMigration.name
+}
+
 // GetStatus returns the current status of the MigrationContainer using the constants on this package.
 func (thisMigration MigrationContainer) GetStatus() int8 {
 	return thisMigration.status
@@ -92,6 +138,7 @@ func (thisMigration MigrationContainer) NewAsFailed(err error) Migration {
 	return MigrationContainer{
 		absolutePath: thisMigration.absolutePath,
 		name:         thisMigration.name,
+		source:       thisMigration.source,
 		status:       StatusFailed,
 		query:        thisMigration.query,
 		err:          err,
@@ -101,14 +148,24 @@ func (thisMigration MigrationContainer) NewAsFailed(err error) Migration {
 
 // NewAsNotRun returns a copy of the MigrationContainer but with a StatusNotRun status.
 func (thisMigration MigrationContainer) NewAsNotRun() Migration {
-	newMigration, _ := NewMigration(thisMigration.GetAbsolutePath(), thisMigration.GetQuery(), StatusNotRun)
+	newMigration, _ := NewMigrationFromSource(
+		thisMigration.GetSource(),
+		thisMigration.GetAbsolutePath(),
+		thisMigration.GetQuery(),
+		StatusNotRun,
+	)
 
 	return newMigration
 }
 
 // NewAsSuccessful returns a copy of the MigrationContainer but with a StatusSuccessful status.
 func (thisMigration MigrationContainer) NewAsSuccessful() Migration {
-	newMigration, _ := NewMigration(thisMigration.GetAbsolutePath(), thisMigration.GetQuery(), StatusSuccessful)
+	newMigration, _ := NewMigrationFromSource(
+		thisMigration.GetSource(),
+		thisMigration.GetAbsolutePath(),
+		thisMigration.GetQuery(),
+		StatusSuccessful,
+	)
 
 	return newMigration
 }
@@ -121,6 +178,12 @@ func (thisMigration MigrationContainer) ShouldBeRunFirst(anotherMigration Migrat
 
 // NewMigration is a constructor for a Migration implementation.
 func NewMigration(absolutePath string, query string, status int8) (Migration, error) {
+	return NewMigrationFromSource("", absolutePath, query, status)
+}
+
+// NewMigrationFromSource is a constructor for a Migration implementation that comes from an additional migrations
+// directory (see GetSource).
+func NewMigrationFromSource(source string, absolutePath string, query string, status int8) (Migration, error) {
 	if status < -1 || status > 2 {
 		return MigrationContainer{}, errors.Errorf("MigrationContainer invalid status [%d]", status)
 	}
@@ -134,6 +197,7 @@ func NewMigration(absolutePath string, query string, status int8) (Migration, er
 	return MigrationContainer{
 		absolutePath: absolutePath,
 		name:         fileName,
+		source:       source,
 		status:       status,
 		query:        query,
 		order:        order,
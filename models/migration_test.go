@@ -27,6 +27,37 @@ func TestMigrationConstruction(test *testing.T) {
 	assert.Equal(test, validQuery, migration.GetQuery())
 	assert.Equal(test, status, migration.GetStatus())
 	assert.Equal(test, validOrder, migration.GetOrder())
+	assert.Equal(test, "", models.GetSource(migration))
+	assert.Equal(test, validName, models.GetQualifiedName(migration))
+}
+
+func TestMigrationFromAnotherSourceConstruction(test *testing.T) {
+	test.Parallel()
+
+	migration, err := models.NewMigrationFromSource("billing", validPath, validQuery, models.StatusNotRun)
+	require.Nil(test, err)
+
+	assert.Equal(test, validName, migration.GetName())
+	assert.Equal(test, "billing", models.GetSource(migration))
+	assert.Equal(test, "billing/"+validName, models.GetQualifiedName(migration))
+	assert.Equal(test, "billing", models.GetSource(migration.NewAsSuccessful()))
+	assert.Equal(test, "billing", models.GetSource(migration.NewAsFailed(errors.New("failed"))))
+	assert.Equal(test, "billing", models.GetSource(migration.NewAsNotRun()))
+}
+
+// unsourcedMigration is a Migration that doesn't implement models.SourcedMigration.
+type unsourcedMigration struct {
+	models.Migration
+}
+
+func TestTheQualifiedNameOfAMigrationWithoutASourceIsItsName(test *testing.T) {
+	test.Parallel()
+
+	migration, err := models.NewMigrationFromSource("billing", validPath, validQuery, models.StatusNotRun)
+	require.Nil(test, err)
+
+	assert.Equal(test, "", models.GetSource(unsourcedMigration{migration}))
+	assert.Equal(test, validName, models.GetQualifiedName(unsourcedMigration{migration}))
 }
 
 func TestMigrationConstructionFailsWithAnInvalidOrder(test *testing.T) {
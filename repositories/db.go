@@ -1,6 +1,10 @@
 package repositories
 
 import (
+	"database/sql"
+	"fmt"
+	"strings"
+
 	"github.com/pkg/errors"
 
 	"github.com/jimenezmaximiliano/migrations/adapters"
@@ -15,17 +19,88 @@ type DBRepository interface {
 	Ping() error
 }
 
+// DBTargetRepository is an optional interface of a DBRepository (see NewDBRepositoryForDialect) that describes the DB
+// the migrations run on and marks it as a protected environment (e.g. production).
+type DBTargetRepository interface {
+	GetTarget() (DBTarget, error)
+	GetProtectedEnvironment() (string, error)
+	SetProtectedEnvironment(environment string) error
+}
+
+// RunMigrationUnregisterer is an optional interface of a DBRepository (see NewDBRepositoryForDialect) that deletes the
+// rows of a migration from the migrations table.
+type RunMigrationUnregisterer interface {
+	UnregisterRunMigration(migrationFileName string) error
+}
+
+// RunMigrationsLister is an optional interface of a DBRepository (see NewDBRepositoryForDialect) that lists the run
+// migrations in the order they were run (the order of the id of the migrations table, not the one of the files).
+type RunMigrationsLister interface {
+	GetRunMigrationFileNames() ([]string, error)
+}
+
+// RunMigrationsRepairer is an optional interface of a DBRepository (see NewDBRepositoryForDialect) that fixes the
+// rows of a migration on the migrations table with a single statement, so the migration never loses all its rows.
+type RunMigrationsRepairer interface {
+	RemoveDuplicatedRunMigration(migrationFileName string) error
+	RenameRunMigration(migrationFileName string, newMigrationFileName string) error
+}
+
+// DBRepositoryWrapper is implemented by the DBRepository decorators (e.g. the one that retries to connect to the DB),
+// so the optional interfaces of the decorated DBRepository can be found (see UnwrapDBRepository).
+type DBRepositoryWrapper interface {
+	UnwrapDBRepository() DBRepository
+}
+
+// UnwrapDBRepository returns the DBRepository decorated by the given one (recursively), or the given one if it's not a
+// decorator (see DBRepositoryWrapper).
+func UnwrapDBRepository(dbRepository DBRepository) DBRepository {
+	for {
+		wrapper, isWrapper := dbRepository.(DBRepositoryWrapper)
+		if !isWrapper {
+			return dbRepository
+		}
+		dbRepository = wrapper.UnwrapDBRepository()
+	}
+}
+
+// DBTarget describes the DB the migrations run on, so it can be confirmed before running them.
+type DBTarget struct {
+	Driver string
+	// Host is the host and port of the DB server (empty for SQLite).
+	Host string
+	// Database is the name of the database (the file path for SQLite).
+	Database string
+}
+
+// SQL dialects supported by DBRepository. They only differ on the DDL of the migrations table.
+const (
+	DialectMySQL  = "mysql"
+	DialectSQLite = "sqlite"
+)
+
 type dbRepository struct {
-	db adapters.DB
+	db      adapters.DB
+	dialect string
 }
 
-// Ensure dbRepository implements DBRepository.
+// Ensure dbRepository implements DBRepository and its optional interfaces.
 var _ DBRepository = dbRepository{}
+var _ DBTargetRepository = dbRepository{}
+var _ RunMigrationUnregisterer = dbRepository{}
+var _ RunMigrationsRepairer = dbRepository{}
+var _ RunMigrationsLister = dbRepository{}
 
-// NewDBRepository returns an implementation of DbRepository.
+// NewDBRepository returns an implementation of DbRepository for MySQL.
 func NewDBRepository(db adapters.DB) DBRepository {
+	return NewDBRepositoryForDialect(db, DialectMySQL)
+}
+
+// NewDBRepositoryForDialect returns an implementation of DbRepository for the given SQL dialect.
+func NewDBRepositoryForDialect(db adapters.DB, dialect string) DBRepository {
 	return dbRepository{
-		db: db,
+		db:      db,
+		dialect: dialect,
 	}
 }
 
@@ -41,12 +116,7 @@ func (repository dbRepository) Ping() error {
 
 // CreateMigrationsTableIfNeeded creates the migrations table used to keep track of already run migrations.
 func (repository dbRepository) CreateMigrationsTableIfNeeded() error {
-	query := `
-		CREATE TABLE IF NOT EXISTS migrations (
-			id INTEGER PRIMARY KEY AUTO_INCREMENT,
-			migration TEXT
-		);`
-	_, err := repository.db.Exec(query)
+	_, err := repository.db.Exec(GetCreateMigrationsTableScript(repository.dialect))
 	if err != nil {
 		return errors.Wrap(err, "could not create the migrations table")
 	}
@@ -54,10 +124,14 @@ func (repository dbRepository) CreateMigrationsTableIfNeeded() error {
 	return nil
 }
 
-// GetAlreadyRunMigrationFilePaths returns a list of migration file paths that have been run already.
+// GetAlreadyRunMigrationFilePaths returns a list of migration file paths that have been run already (none if the
+// migrations table doesn't exist yet).
 func (repository dbRepository) GetAlreadyRunMigrationFilePaths(migrationsDirectoryAbsolutePath string) (paths []string, err error) {
 	rows, err := repository.db.Query("SELECT migration FROM migrations")
 	if err != nil {
+		if exists, existsErr := repository.tableExists("migrations"); existsErr == nil && !exists {
+			return nil, nil
+		}
 		return nil, errors.Wrapf(err, "could not get already run migrations from the migrations table")
 	}
 	defer func() {
@@ -71,6 +145,30 @@ func (repository dbRepository) GetAlreadyRunMigrationFilePaths(migrationsDirecto
 	return paths, err
 }
 
+// GetRunMigrationFileNames returns the file names registered on the migrations table, in the order they were run.
+func (repository dbRepository) GetRunMigrationFileNames() (fileNames []string, err error) {
+	rows, err := repository.db.Query("SELECT migration FROM migrations ORDER BY id")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the run migrations from the migrations table")
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		fileName := ""
+		err = rows.Scan(&fileName)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get the run migrations from the migrations table")
+		}
+		fileNames = append(fileNames, fileName)
+	}
+
+	return fileNames, nil
+}
+
 // RunMigrationQuery runs the migration query.
 func (repository dbRepository) RunMigrationQuery(query string) error {
 	_, err := repository.db.Exec(query)
@@ -85,6 +183,157 @@ func (repository dbRepository) RegisterRunMigration(migrationFileName string) er
 	return errors.Wrapf(err, "failed to register a run migration [%s]", migrationFileName)
 }
 
+// UnregisterRunMigration deletes the records of a migration from the migrations table, so it's run again.
+func (repository dbRepository) UnregisterRunMigration(migrationFileName string) error {
+	_, err := repository.db.Exec("DELETE FROM migrations WHERE migration = ?", migrationFileName)
+
+	return errors.Wrapf(err, "failed to unregister a run migration [%s]", migrationFileName)
+}
+
+// RemoveDuplicatedRunMigration deletes every record of a migration on the migrations table but the first one.
+func (repository dbRepository) RemoveDuplicatedRunMigration(migrationFileName string) error {
+	// The derived table lets MySQL read the table it deletes from.
+	_, err := repository.db.Exec(`
+		DELETE FROM migrations WHERE migration = ? AND id > (
+			SELECT id FROM (SELECT MIN(id) AS id FROM migrations WHERE migration = ?) AS first_migration
+		)`,
+		migrationFileName,
+		migrationFileName,
+	)
+
+	return errors.Wrapf(err, "failed to remove the duplicated records of a run migration [%s]", migrationFileName)
+}
+
+// RenameRunMigration updates the records of a migration on the migrations table with a new file name.
+func (repository dbRepository) RenameRunMigration(migrationFileName string, newMigrationFileName string) error {
+	_, err := repository.db.Exec(
+		"UPDATE migrations SET migration = ? WHERE migration = ?",
+		newMigrationFileName,
+		migrationFileName,
+	)
+
+	return errors.Wrapf(err, "failed to rename a run migration [%s] to [%s]", migrationFileName, newMigrationFileName)
+}
+
+// GetTarget returns the driver, host and name of the DB, as reported by the DB itself.
+func (repository dbRepository) GetTarget() (target DBTarget, err error) {
+	query := "SELECT CONCAT(@@hostname, ':', @@port), DATABASE()"
+	if repository.dialect == DialectSQLite {
+		query = "SELECT '', file FROM pragma_database_list WHERE name = 'main'"
+	}
+
+	rows, err := repository.db.Query(query)
+	if err != nil {
+		return DBTarget{}, errors.Wrap(err, "could not get the DB name")
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	target = DBTarget{Driver: repository.dialect}
+	if !rows.Next() {
+		return target, nil
+	}
+
+	var host, database sql.NullString
+	err = rows.Scan(&host, &database)
+	if err != nil {
+		return DBTarget{}, errors.Wrap(err, "could not get the DB name")
+	}
+	target.Host = host.String
+	target.Database = database.String
+
+	return target, nil
+}
+
+// GetProtectedEnvironment returns the environment the DB has been marked as (e.g. production) by
+// SetProtectedEnvironment, or an empty string if it's not protected.
+func (repository dbRepository) GetProtectedEnvironment() (environment string, err error) {
+	isProtectable, err := repository.tableExists("migrations_protection")
+	if err != nil || !isProtectable {
+		return "", errors.Wrap(err, "could not check if the DB is protected")
+	}
+
+	environment, err = repository.queryString("SELECT environment FROM migrations_protection")
+
+	return environment, errors.Wrap(err, "could not check if the DB is protected")
+}
+
+// SetProtectedEnvironment marks the DB as a protected environment (e.g. production), or removes the mark if the
+// environment is empty. The mark is kept on the migrations_protection table, created only when it's needed.
+func (repository dbRepository) SetProtectedEnvironment(environment string) error {
+	_, err := repository.db.Exec("CREATE TABLE IF NOT EXISTS migrations_protection (environment VARCHAR(255) NOT NULL)")
+	if err != nil {
+		return errors.Wrap(err, "could not create the migrations_protection table")
+	}
+
+	_, err = repository.db.Exec("DELETE FROM migrations_protection")
+	if err != nil || environment == "" {
+		return errors.Wrap(err, "could not remove the protection of the DB")
+	}
+
+	_, err = repository.db.Exec("INSERT INTO migrations_protection (environment) VALUES (?)", environment)
+
+	return errors.Wrapf(err, "could not protect the DB as [%s]", environment)
+}
+
+// tableExists returns true if the table exists on the current database.
+func (repository dbRepository) tableExists(table string) (bool, error) {
+	query := `
+		SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_name = '` + table + `'`
+	if repository.dialect == DialectSQLite {
+		query = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = '" + table + "'"
+	}
+
+	tables, err := repository.queryString(query)
+
+	return tables != "" && tables != "0", err
+}
+
+// queryString returns the first column of the first row of a query (an empty string if there are no rows).
+func (repository dbRepository) queryString(query string) (value string, err error) {
+	rows, err := repository.db.Query(query)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	if !rows.Next() {
+		return "", nil
+	}
+
+	var nullableValue sql.NullString
+	err = rows.Scan(&nullableValue)
+
+	return nullableValue.String, err
+}
+
+// GetRegisterRunMigrationScript returns the statement that RegisterRunMigration runs, with the value inlined, so it
+// can be run manually.
+func GetRegisterRunMigrationScript(migrationFileName string) string {
+	escapedFileName := strings.ReplaceAll(migrationFileName, "'", "''")
+
+	return fmt.Sprintf("INSERT INTO migrations (migration) VALUES ('%s');", escapedFileName)
+}
+
+// GetCreateMigrationsTableScript returns the statement that CreateMigrationsTableIfNeeded runs for a SQL dialect, so
+// it can be run manually.
+func GetCreateMigrationsTableScript(dialect string) string {
+	autoIncrement := "AUTO_INCREMENT"
+	if dialect == DialectSQLite {
+		autoIncrement = "AUTOINCREMENT"
+	}
+
+	return "CREATE TABLE IF NOT EXISTS migrations (id INTEGER PRIMARY KEY " + autoIncrement + ", migration TEXT);"
+}
+
 func getMigrationPathsFromRows(rows adapters.DBRows, migrationsDirectoryAbsolutePath string) ([]string, error) {
 	var migrationsAlreadyRun []string
 	for rows.Next() {
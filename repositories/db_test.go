@@ -1,7 +1,9 @@
 package repositories_test
 
 import (
+	"database/sql"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,6 +26,20 @@ func TestCreatingTheMigrationsTable(test *testing.T) {
 	assert.Nil(test, err)
 }
 
+func TestCreatingTheMigrationsTableOnSQLite(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DB{}
+	defer db.AssertExpectations(test)
+	db.On("Exec", mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, "AUTOINCREMENT") && !strings.Contains(query, "AUTO_INCREMENT")
+	})).Return(nil, nil)
+	repository := repositories.NewDBRepositoryForDialect(db, repositories.DialectSQLite)
+	err := repository.CreateMigrationsTableIfNeeded()
+
+	assert.Nil(test, err)
+}
+
 func TestCreatingTheMigrationsTableFailsIfThereWasAnError(test *testing.T) {
 	test.Parallel()
 
@@ -82,6 +98,31 @@ func TestGettingAlreadyRunMigrationFilePaths(test *testing.T) {
 	assert.Equal(test, "/tmp/migrationAlreadyRun.sql", filePaths[0])
 }
 
+func TestGettingTheRunMigrationFileNamesInTheOrderTheyWereRun(test *testing.T) {
+	test.Parallel()
+
+	fileNames := []string{"2_b.sql", "1_a.sql"}
+	rows := &mocks.DBRows{}
+	defer rows.AssertExpectations(test)
+	rows.On("Close").Return(nil).Once()
+	rows.On("Next").Return(true).Twice()
+	rows.On("Next").Return(false).Once()
+	scans := 0
+	rows.On("Scan", mock.AnythingOfType("*string")).Return(nil).Twice().Run(func(args mock.Arguments) {
+		*args[0].(*string) = fileNames[scans]
+		scans++
+	})
+	db := &mocks.DB{}
+	defer db.AssertExpectations(test)
+	db.On("Query", "SELECT migration FROM migrations ORDER BY id").Return(rows, nil).Once()
+	repository := repositories.NewDBRepository(db).(repositories.RunMigrationsLister)
+
+	runFileNames, err := repository.GetRunMigrationFileNames()
+
+	require.Nil(test, err)
+	assert.Equal(test, fileNames, runFileNames)
+}
+
 func TestGettingAlreadyRunMigrationFilePathsFailsIfTheQueryFails(test *testing.T) {
 	test.Parallel()
 
@@ -95,6 +136,24 @@ func TestGettingAlreadyRunMigrationFilePathsFailsIfTheQueryFails(test *testing.T
 	assert.Nil(test, filePaths)
 }
 
+func TestGettingAlreadyRunMigrationFilePathsWithoutAMigrationsTable(test *testing.T) {
+	test.Parallel()
+
+	rows := getSingleValueRows("0")
+	defer rows.AssertExpectations(test)
+	db := &mocks.DB{}
+	defer db.AssertExpectations(test)
+	db.On("Query", "SELECT migration FROM migrations").Return(nil, fmt.Errorf("no such table: migrations")).Once()
+	db.On("Query", mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, "sqlite_master") && strings.Contains(query, "'migrations'")
+	})).Return(rows, nil).Once()
+	repository := repositories.NewDBRepositoryForDialect(db, repositories.DialectSQLite)
+	filePaths, err := repository.GetAlreadyRunMigrationFilePaths("/tmp/")
+
+	require.Nil(test, err)
+	assert.Empty(test, filePaths)
+}
+
 func TestGettingAlreadyRunMigrationFilePathsFailsIfRowsCannotBeScanned(test *testing.T) {
 	test.Parallel()
 
@@ -164,3 +223,141 @@ func TestRegisteringARunMigrationFailsIfTheInsertFails(test *testing.T) {
 
 	assert.NotNil(test, err)
 }
+
+func TestGettingTheRegisterRunMigrationScript(test *testing.T) {
+	test.Parallel()
+
+	script := repositories.GetRegisterRunMigrationScript("1_gopher's.sql")
+
+	assert.Equal(test, "INSERT INTO migrations (migration) VALUES ('1_gopher''s.sql');", script)
+}
+
+func TestUnregisteringARunMigration(test *testing.T) {
+	test.Parallel()
+
+	const migrationName = "1_a.sql"
+	db := &mocks.DB{}
+	defer db.AssertExpectations(test)
+	db.On("Exec", "DELETE FROM migrations WHERE migration = ?", migrationName).Return(nil, nil)
+	repository := repositories.NewDBRepository(db).(repositories.RunMigrationUnregisterer)
+	err := repository.UnregisterRunMigration(migrationName)
+
+	assert.Nil(test, err)
+}
+
+func TestUnregisteringARunMigrationFailsIfTheDeleteFails(test *testing.T) {
+	test.Parallel()
+
+	const migrationName = "1_a.sql"
+	db := &mocks.DB{}
+	defer db.AssertExpectations(test)
+	db.On("Exec", mock.AnythingOfType("string"), migrationName).Return(nil, fmt.Errorf("db query error"))
+	repository := repositories.NewDBRepository(db).(repositories.RunMigrationUnregisterer)
+	err := repository.UnregisterRunMigration(migrationName)
+
+	assert.NotNil(test, err)
+}
+
+func getSingleValueRows(value string) *mocks.DBRows {
+	rows := &mocks.DBRows{}
+	rows.On("Close").Return(nil).Once()
+	rows.On("Next").Return(true).Once()
+	rows.On("Scan", mock.AnythingOfType("*sql.NullString")).Return(nil).Once().Run(func(args mock.Arguments) {
+		*args[0].(*sql.NullString) = sql.NullString{String: value, Valid: true}
+	})
+
+	return rows
+}
+
+func TestGettingTheProtectedEnvironmentOfANotProtectedDB(test *testing.T) {
+	test.Parallel()
+
+	rows := getSingleValueRows("0")
+	defer rows.AssertExpectations(test)
+	db := &mocks.DB{}
+	defer db.AssertExpectations(test)
+	db.On("Query", mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, "sqlite_master")
+	})).Return(rows, nil).Once()
+	repository := repositories.NewDBRepositoryForDialect(db, repositories.DialectSQLite).(repositories.DBTargetRepository)
+
+	environment, err := repository.GetProtectedEnvironment()
+
+	require.Nil(test, err)
+	assert.Empty(test, environment)
+}
+
+func TestGettingTheProtectedEnvironmentOfAProtectedDB(test *testing.T) {
+	test.Parallel()
+
+	tablesRows := getSingleValueRows("1")
+	defer tablesRows.AssertExpectations(test)
+	environmentRows := getSingleValueRows("production")
+	defer environmentRows.AssertExpectations(test)
+	db := &mocks.DB{}
+	defer db.AssertExpectations(test)
+	db.On("Query", mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, "information_schema.tables")
+	})).Return(tablesRows, nil).Once()
+	db.On("Query", "SELECT environment FROM migrations_protection").Return(environmentRows, nil).Once()
+	repository := repositories.NewDBRepository(db).(repositories.DBTargetRepository)
+
+	environment, err := repository.GetProtectedEnvironment()
+
+	require.Nil(test, err)
+	assert.Equal(test, "production", environment)
+}
+
+func TestProtectingTheDB(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DB{}
+	defer db.AssertExpectations(test)
+	db.On("Exec", mock.MatchedBy(func(query string) bool {
+		return strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS migrations_protection")
+	})).Return(nil, nil).Once()
+	db.On("Exec", "DELETE FROM migrations_protection").Return(nil, nil).Once()
+	db.On("Exec", "INSERT INTO migrations_protection (environment) VALUES (?)", "production").Return(nil, nil).Once()
+	repository := repositories.NewDBRepository(db).(repositories.DBTargetRepository)
+
+	assert.Nil(test, repository.SetProtectedEnvironment("production"))
+}
+
+func TestUnprotectingTheDB(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DB{}
+	defer db.AssertExpectations(test)
+	db.On("Exec", mock.MatchedBy(func(query string) bool {
+		return strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS migrations_protection")
+	})).Return(nil, nil).Once()
+	db.On("Exec", "DELETE FROM migrations_protection").Return(nil, nil).Once()
+	repository := repositories.NewDBRepository(db).(repositories.DBTargetRepository)
+
+	assert.Nil(test, repository.SetProtectedEnvironment(""))
+}
+
+func TestGettingTheTargetOfASQLiteDB(test *testing.T) {
+	test.Parallel()
+
+	rows := &mocks.DBRows{}
+	defer rows.AssertExpectations(test)
+	rows.On("Close").Return(nil).Once()
+	rows.On("Next").Return(true).Once()
+	rows.On("Scan", mock.AnythingOfType("*sql.NullString"), mock.AnythingOfType("*sql.NullString")).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			*args[1].(*sql.NullString) = sql.NullString{String: "/tmp/db.sqlite", Valid: true}
+		})
+	db := &mocks.DB{}
+	defer db.AssertExpectations(test)
+	db.On("Query", mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, "pragma_database_list")
+	})).Return(rows, nil)
+	repository := repositories.NewDBRepositoryForDialect(db, repositories.DialectSQLite).(repositories.DBTargetRepository)
+
+	target, err := repository.GetTarget()
+
+	require.Nil(test, err)
+	assert.Equal(test, repositories.DBTarget{Driver: repositories.DialectSQLite, Database: "/tmp/db.sqlite"}, target)
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"io/fs"
 	"os"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -10,6 +11,10 @@ import (
 	"github.com/jimenezmaximiliano/migrations/helpers"
 )
 
+// DownMigrationSuffix is the suffix of the file that reverts a migration ({number}_{string}.down.sql next to
+// {number}_{string}.sql). Down migrations are never run as regular migrations.
+const DownMigrationSuffix = ".down.sql"
+
 // FileRepository fetches migrations files from a given path.
 type FileRepository interface {
 	GetMigrationFilePaths(migrationsDirectoryAbsolutePath string) ([]string, error)
@@ -17,12 +22,30 @@ type FileRepository interface {
 	CreateMigration(migrationAbsolutePath, query string) error
 }
 
+// DownMigrationReader is an optional interface of a FileRepository (see NewFileRepository) that reads the down
+// migration of a migration (see DownMigrationSuffix).
+type DownMigrationReader interface {
+	GetDownMigrationQuery(migrationAbsolutePath string) (string, error)
+}
+
+// GetDownMigrationQuery returns the query that reverts a migration, read by the FileRepository if it implements
+// DownMigrationReader or, otherwise, as the query of the down migration file.
+func GetDownMigrationQuery(fileRepository FileRepository, migrationAbsolutePath string) (string, error) {
+	reader, canRead := fileRepository.(DownMigrationReader)
+	if !canRead {
+		return fileRepository.GetMigrationQuery(GetDownMigrationPath(migrationAbsolutePath))
+	}
+
+	return reader.GetDownMigrationQuery(migrationAbsolutePath)
+}
+
 type fileRepository struct {
 	fileSystem adapters.FileSystem
 }
 
-// Ensure fileRepository implements FileRepository.
+// Ensure fileRepository implements FileRepository and DownMigrationReader.
 var _ FileRepository = fileRepository{}
+var _ DownMigrationReader = fileRepository{}
 
 // NewFileRepository returns an implementation of FileRepository.
 func NewFileRepository(fileSystem adapters.FileSystem) FileRepository {
@@ -56,6 +79,33 @@ func (repository fileRepository) GetMigrationQuery(migrationAbsolutePath string)
 	return string(query), nil
 }
 
+// GetDownMigrationQuery returns the query that reverts a migration, given the migration file path.
+func (repository fileRepository) GetDownMigrationQuery(migrationAbsolutePath string) (string, error) {
+	downMigrationAbsolutePath := GetDownMigrationPath(migrationAbsolutePath)
+	query, err := repository.fileSystem.ReadFile(downMigrationAbsolutePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not read contents of a down migration file [%s]", downMigrationAbsolutePath)
+	}
+
+	return string(query), nil
+}
+
+// GetDownMigrationPath returns the path of the file that reverts a migration.
+func GetDownMigrationPath(migrationAbsolutePath string) string {
+	return strings.TrimSuffix(migrationAbsolutePath, ".sql") + DownMigrationSuffix
+}
+
+// IsDownMigrationPath returns true if the given path is the path of a down migration.
+func IsDownMigrationPath(path string) bool {
+	return strings.HasSuffix(path, DownMigrationSuffix)
+}
+
+// IsHiddenFile returns true if the file name starts with a dot (e.g. the .migration-template.sql template). Hidden
+// files on the migrations directory are never migrations.
+func IsHiddenFile(fileName string) bool {
+	return strings.HasPrefix(fileName, ".")
+}
+
 // CreateMigration creates a new file with th emigration content.
 func (repository fileRepository) CreateMigration(migrationAbsolutePath, query string) error {
 	err := repository.fileSystem.WriteFile(migrationAbsolutePath, []byte(query), fs.FileMode(0644))
@@ -69,7 +119,7 @@ func (repository fileRepository) CreateMigration(migrationAbsolutePath, query st
 func getMigrationFilePathsFromFiles(files []os.FileInfo, migrationsDirectoryAbsolutePath string) []string {
 	var migrationFilePaths []string
 	for _, file := range files {
-		if isNotASqlFile(file) {
+		if isNotASqlFile(file) || IsDownMigrationPath(file.Name()) || IsHiddenFile(file.Name()) {
 			continue
 		}
 		currentMigrationAbsolutePath := migrationsDirectoryAbsolutePath + file.Name()
@@ -76,6 +76,49 @@ func TestGettingMigrationFilePathsOmitsNonSqlFilesAndDiretories(test *testing.T)
 	assert.Len(test, paths, 1)
 }
 
+func TestGettingMigrationFilePathsOmitsHiddenFilesLikeTheTemplate(test *testing.T) {
+	test.Parallel()
+
+	file := &mocks.File{}
+	defer file.AssertExpectations(test)
+	file.On("Name").Return("1_a.sql")
+	file.On("IsDir").Return(false)
+	template := &mocks.File{}
+	template.On("Name").Return(".migration-template.sql")
+	template.On("IsDir").Return(false)
+	files := []os.FileInfo{template, file}
+	fileSystem := &mocks.FileSystem{}
+	defer fileSystem.AssertExpectations(test)
+	fileSystem.On("ReadDir", "/tmp/").Return(files, nil)
+	repository := repositories.NewFileRepository(fileSystem)
+	paths, err := repository.GetMigrationFilePaths("/tmp")
+
+	require.Nil(test, err)
+	assert.Equal(test, []string{"/tmp/1_a.sql"}, paths)
+}
+
+func TestGettingMigrationFilePathsOmitsDownMigrations(test *testing.T) {
+	test.Parallel()
+
+	file := &mocks.File{}
+	defer file.AssertExpectations(test)
+	file.On("Name").Return("1_a.sql")
+	file.On("IsDir").Return(false)
+	downFile := &mocks.File{}
+	defer downFile.AssertExpectations(test)
+	downFile.On("Name").Return("1_a.down.sql")
+	downFile.On("IsDir").Return(false)
+	files := []os.FileInfo{file, downFile}
+	fileSystem := &mocks.FileSystem{}
+	defer fileSystem.AssertExpectations(test)
+	fileSystem.On("ReadDir", "/tmp/").Return(files, nil)
+	repository := repositories.NewFileRepository(fileSystem)
+	paths, err := repository.GetMigrationFilePaths("/tmp")
+
+	require.Nil(test, err)
+	assert.Equal(test, []string{"/tmp/1_a.sql"}, paths)
+}
+
 func TestGettingMigrationFilePathsFailsIfItIsNotPossibleToReadTheDirectory(test *testing.T) {
 	test.Parallel()
 
@@ -103,6 +146,20 @@ func TestGettingAQuery(test *testing.T) {
 	assert.Nil(test, err)
 }
 
+func TestGettingADownQuery(test *testing.T) {
+	test.Parallel()
+
+	const query = "DROP TABLE a"
+	fileSystem := &mocks.FileSystem{}
+	defer fileSystem.AssertExpectations(test)
+	fileSystem.On("ReadFile", "/tmp/1_a.down.sql").Return([]byte(query), nil)
+	repository := repositories.NewFileRepository(fileSystem)
+	readQuery, err := repositories.GetDownMigrationQuery(repository, "/tmp/1_a.sql")
+
+	assert.Equal(test, query, readQuery)
+	assert.Nil(test, err)
+}
+
 func TestGettingAQueryFailsIfTheFileCannotBeRead(test *testing.T) {
 	test.Parallel()
 
@@ -2,7 +2,9 @@ package services
 
 import (
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -14,15 +16,103 @@ const (
 	EnvVarMigrationsPath   string = "MIGRATIONS_PATH"
 	EnvVarNewMigrationName string = "MIGRATIONS_NEW_MIGRATION_NAME"
 	EnvVarCommand          string = "MIGRATIONS_COMMAND"
+	EnvVarColor            string = "MIGRATIONS_COLOR"
+	EnvVarOutputPath       string = "MIGRATIONS_OUTPUT"
+	EnvVarLintSeverity     string = "MIGRATIONS_LINT_SEVERITY"
+	EnvVarBaselineOrder    string = "MIGRATIONS_BASELINE_TO"
+	EnvVarAssumeYes        string = "MIGRATIONS_YES"
+	EnvVarRedoSteps        string = "MIGRATIONS_REDO_STEPS"
+	EnvVarDevelopment      string = "MIGRATIONS_DEV"
+	EnvVarSquashUntil      string = "MIGRATIONS_SQUASH_UNTIL"
+	EnvVarTemplatePath     string = "MIGRATIONS_TEMPLATE"
+	EnvVarNumbering        string = "MIGRATIONS_NUMBERING"
+	EnvVarNumberingPadding string = "MIGRATIONS_NUMBERING_PADDING"
+	EnvVarNameStyle        string = "MIGRATIONS_NAME_STYLE"
+	EnvVarConfigPath       string = "MIGRATIONS_CONFIG"
+	EnvVarEnvironment      string = "MIGRATIONS_ENV"
+	EnvVarDriver           string = "MIGRATIONS_DRIVER"
+	EnvVarDatabaseURL      string = "MIGRATIONS_DATABASE_URL"
+	EnvVarSchemas          string = "MIGRATIONS_SCHEMAS"
+	EnvVarConcurrency      string = "MIGRATIONS_CONCURRENCY"
+	EnvVarAllowProtected   string = "MIGRATIONS_ALLOW_PROTECTED"
+	EnvVarWaitTimeout      string = "MIGRATIONS_WAIT_TIMEOUT"
+	EnvVarConnectTimeout   string = "MIGRATIONS_CONNECT_TIMEOUT"
+	EnvVarConnectBackoff   string = "MIGRATIONS_CONNECT_BACKOFF"
 )
 
-var ValidCommands = []string{"migrate", "create"}
+// ValidCommands are the names of the built-in commands (see DefaultCommandRegistry).
+var ValidCommands = DefaultCommandRegistry().GetCommandNames()
 
 // Arguments represents the command line arguments for the migrations commands.
 type Arguments struct {
-	MigrationsPath string
-	MigrationName  string
-	Command        string
+	// MigrationsPath is the main migrations directory (the first one of MigrationsPaths).
+	MigrationsPath  string
+	MigrationsPaths []string
+	MigrationName   string
+	Command         string
+	// CommandArguments are the positional arguments given after the command (e.g. the topic of the help command).
+	CommandArguments []string
+	Color            string
+	OutputPath       string
+	LintSeverities   map[string]string
+	BaselineOrder    uint64
+	AssumeYes        bool
+	RedoSteps        uint64
+	Development      bool
+	SquashOrder      uint64
+	TemplatePath     string
+	Numbering        string
+	NumberingPadding uint64
+	NameStyle        string
+	Driver           string
+	DSN              string
+	Schemas          []string
+	Concurrency      uint64
+	// AllowProtected allows migrating a protected DB (see DBRepository.SetProtectedEnvironment). Unlike the other
+	// options, it cannot be set on the config file, so it's never allowed by accident.
+	AllowProtected bool
+	// WaitTimeout is how long the wait command waits for the schema to be up to date.
+	WaitTimeout time.Duration
+	// ConnectionRetry configures the retries to connect to the DB (no retries unless ConnectionRetry.Timeout is set).
+	ConnectionRetry ConnectionRetry
+	// Options are the values of the custom options (see CommandRegistry), by name. Bool options are "true" or "false".
+	Options map[string]string
+	// rawOrders are the values of the order options (to and until) as given, so a missing one isn't taken as order 0.
+	rawOrders map[string]string
+}
+
+// GetOption returns the value of a custom option (see CommandRegistry).
+func (args Arguments) GetOption(name string) string {
+	return args.Options[name]
+}
+
+// getRequiredOptionValue returns the value of a required option, built-in or custom, to check that it's been given.
+// It returns false for the built-in options the commands check themselves (e.g. dev for redo).
+func (args Arguments) getRequiredOptionValue(name string) (string, bool) {
+	switch name {
+	case "path":
+		return args.MigrationsPath, true
+	case "name":
+		return args.MigrationName, true
+	case "to", "until":
+		return args.rawOrders[name], true
+	}
+
+	return args.GetOption(name), !isBuiltInOption(name)
+}
+
+// GetMigrationsPaths returns every migrations directory, the main one first.
+func (args Arguments) GetMigrationsPaths() []string {
+	if len(args.MigrationsPaths) == 0 {
+		return []string{args.MigrationsPath}
+	}
+
+	return args.MigrationsPaths
+}
+
+// GetAdditionalMigrationsPaths returns the migrations directories merged with the main one (see NewFetcherService).
+func (args Arguments) GetAdditionalMigrationsPaths() []string {
+	return args.GetMigrationsPaths()[1:]
 }
 
 // CommandArgument is the API to handle command arguments.
@@ -34,6 +124,11 @@ type CommandArgument interface {
 type CommandArgumentService struct {
 	displayService Display
 	parser         adapters.ArgumentParser
+	fileSystem     adapters.FileSystem
+	registry       *CommandRegistry
+	// rawArguments are the command line arguments, without the executable (os.Args[1:] if nil).
+	rawArguments []string
+	getEnvVar    func(key string) string
 }
 
 var _ CommandArgument = CommandArgumentService{}
@@ -43,40 +138,135 @@ func NewCommandArgumentService(displayService Display, parser adapters.ArgumentP
 	return CommandArgumentService{
 		displayService: displayService,
 		parser:         parser,
+		fileSystem:     adapters.IOUtilAdapter{},
+		registry:       DefaultCommandRegistry(),
+		getEnvVar:      os.Getenv,
 	}
 }
 
-// ParseAndValidate parses command line arguments and validates them. In case the validation fails, it'll return true
+// WithArguments returns a copy of the CommandArgumentService that parses the given command line arguments (without
+// the executable) instead of os.Args.
+func (service CommandArgumentService) WithArguments(rawArguments []string) CommandArgumentService {
+	service.rawArguments = append([]string{}, rawArguments...)
+
+	return service
+}
+
+// WithEnvVars returns a copy of the CommandArgumentService that reads environment variables with the given function
+// instead of os.Getenv.
+func (service CommandArgumentService) WithEnvVars(getEnvVar func(key string) string) CommandArgumentService {
+	service.getEnvVar = getEnvVar
+
+	return service
+}
+
+// WithCommandRegistry returns a copy of the CommandArgumentService that accepts the commands and options of the given
+// registry (e.g. DefaultCommandRegistry plus custom commands).
+func (service CommandArgumentService) WithCommandRegistry(registry *CommandRegistry) CommandArgumentService {
+	service.registry = registry
+
+	return service
+}
+
+// ParseAndValidate parses command line arguments and validates them. In case the validation fails, it'll return false
 // as the second returned value.
 func (service CommandArgumentService) ParseAndValidate() (Arguments, bool) {
-	args := service.parse()
+	args, err := service.ParseAndValidateArguments()
 
-	if !isCommandValid(args.Command) {
-		service.displayService.DisplayError(errors.Errorf("invalid 'command' argument: [%s]", args.Command))
+	return args, err == nil
+}
+
+// ParseAndValidateArguments parses command line arguments and validates them. In case the validation fails, it'll
+// return an ArgumentsError.
+func (service CommandArgumentService) ParseAndValidateArguments() (Arguments, error) {
+	args, err := service.parse()
+	if err == nil {
+		err = validateArguments(args, service.registry)
+	}
+	if err != nil {
+		service.displayService.DisplayError(err)
 		service.displayService.DisplayHelp()
-		return args, false
+		return args, NewArgumentsError(err)
 	}
 
-	if args.MigrationsPath == "" {
-		service.displayService.DisplayError(errors.Errorf("missing 'path' option for command '%s'", args.Command))
-		service.displayService.DisplayHelp()
-		return args, false
+	return args, nil
+}
+
+func validateArguments(args Arguments, registry *CommandRegistry) error {
+	spec, isCommandValid := registry.GetCommand(args.Command)
+	if !isCommandValid {
+		return errors.Errorf("invalid 'command' argument: [%s]", args.Command)
 	}
 
-	if args.Command == "create" && args.MigrationName == "" {
-		service.displayService.DisplayError(errors.Errorf("missing 'name' option for command '%s'", args.Command))
-		service.displayService.DisplayHelp()
-		return args, false
+	for _, name := range spec.RequiredOptions {
+		option, _ := registry.GetOption(name)
+		value, isChecked := args.getRequiredOptionValue(name)
+		if isChecked && (value == "" || option.IsBool && value == "false") {
+			return errors.Errorf("missing '%s' option for command '%s'", name, args.Command)
+		}
 	}
 
-	return args, true
+	if !isNumberingModeValid(args.Numbering) {
+		return errors.Errorf("invalid 'numbering' option: [%s] (valid options: %s)",
+			args.Numbering,
+			strings.Join(validNumberingModes, ", "))
+	}
+
+	if !isNameStyleValid(args.NameStyle) {
+		return errors.Errorf("invalid 'name-style' option: [%s] (valid options: %s)",
+			args.NameStyle,
+			strings.Join(validNameStyles, ", "))
+	}
+
+	if !isColorModeValid(args.Color) {
+		return errors.Errorf("invalid 'color' option: [%s] (valid options: %s)",
+			args.Color,
+			strings.Join(validColorModes, ", "))
+	}
+
+	return nil
 }
 
-func (service CommandArgumentService) parse() Arguments {
-	rawArgs := getRearrangedArguments()
+func (service CommandArgumentService) parse() (Arguments, error) {
+	rawArgs := getRearrangedArguments(service.getRawArguments())
 
-	pathOption := service.parser.OptionString("path", "")
+	pathOption := service.optionStrings("path")
 	nameOption := service.parser.OptionString("name", "")
+	colorOption := service.optionString("color")
+	outOption := service.optionString("out")
+	lintSeverityOption := service.optionString("lint-severity")
+	toOption := service.optionString("to")
+	yesOption := service.optionBool("yes")
+	stepsOption := service.optionString("steps")
+	devOption := service.optionBool("dev")
+	untilOption := service.optionString("until")
+	templateOption := service.optionString("template")
+	numberingOption := service.optionString("numbering")
+	paddingOption := service.optionString("padding")
+	nameStyleOption := service.optionString("name-style")
+	configOption := service.optionString("config")
+	envOption := service.optionString("env")
+	driverOption := service.optionString("driver")
+	dsnOption := service.optionString("dsn")
+	schemasOption := service.optionString("schemas")
+	concurrencyOption := service.optionString("concurrency")
+	allowProtectedOption := service.optionBool("allow-protected")
+	timeoutOption := service.optionString("timeout")
+	connectTimeoutOption := service.optionString("connect-timeout")
+	connectBackoffOption := service.optionString("connect-backoff")
+
+	customOptions := service.registry.GetCustomOptions()
+	customStringOptions := map[string]*string{}
+	customBoolOptions := map[string]*bool{}
+	customOptionNames := make([]string, len(customOptions))
+	for index, option := range customOptions {
+		customOptionNames[index] = option.Name
+		if option.IsBool {
+			customBoolOptions[option.Name] = service.optionBool(option.Name)
+			continue
+		}
+		customStringOptions[option.Name] = service.optionString(option.Name)
+	}
 
 	// Parse command line arguments.
 	err := service.parser.ParseArguments(rawArgs)
@@ -85,36 +275,306 @@ func (service CommandArgumentService) parse() Arguments {
 		// Let it continue, so we can check environment variables and apply default values.
 	}
 
-	return Arguments{
-		MigrationsPath: parseMigrationsDirectoryPath(pathOption),
-		MigrationName:  parseNewMigrationName(nameOption),
-		Command:        service.parseCommand(),
+	config, err := LoadConfig(
+		service.fileSystem,
+		service.parseOption(configOption, EnvVarConfigPath, ""),
+		service.parseOption(envOption, EnvVarEnvironment, ""),
+		customOptionNames...,
+	)
+	if err != nil {
+		return Arguments{}, err
+	}
+
+	args := Arguments{
+		MigrationsPaths:  service.parseMigrationsDirectoryPaths(pathOption(), config.Get("path", "")),
+		MigrationName:    service.parseNewMigrationName(nameOption),
+		Command:          service.parseCommand(),
+		CommandArguments: service.parseCommandArguments(),
+		Color:            service.parseOption(colorOption, EnvVarColor, config.Get("color", ColorModeAuto)),
+		OutputPath:       service.parseOption(outOption, EnvVarOutputPath, config.Get("out", "")),
+		AssumeYes:        service.parseBoolOption("yes", yesOption, EnvVarAssumeYes, ""),
+		Development:      service.parseBoolOption("dev", devOption, EnvVarDevelopment, config.Get("dev", "")),
+		TemplatePath:     service.parseOption(templateOption, EnvVarTemplatePath, config.Get("template", "")),
+		Numbering:        service.parseOption(numberingOption, EnvVarNumbering, config.Get("numbering", NumberingTimestamp)),
+		NameStyle:        service.parseOption(nameStyleOption, EnvVarNameStyle, config.Get("name-style", NameStyleCamelCase)),
+		Driver:           service.parseOption(driverOption, EnvVarDriver, config.Get("driver", "")),
+		DSN:              service.parseOption(dsnOption, EnvVarDatabaseURL, config.Get("dsn", "")),
+		AllowProtected:   service.parseBoolOption("allow-protected", allowProtectedOption, EnvVarAllowProtected, ""),
+	}
+
+	if len(args.MigrationsPaths) > 0 {
+		args.MigrationsPath = args.MigrationsPaths[0]
+	}
+
+	args.LintSeverities, err = ParseLintSeverities(
+		service.parseOption(lintSeverityOption, EnvVarLintSeverity, config.Get("lint-severity", "")),
+	)
+	if err != nil {
+		return args, err
+	}
+
+	args.rawOrders = map[string]string{
+		"to":    service.parseOption(toOption, EnvVarBaselineOrder, ""),
+		"until": service.parseOption(untilOption, EnvVarSquashUntil, ""),
+	}
+	args.BaselineOrder, err = parseOrder(args.rawOrders["to"], "to")
+	if err != nil {
+		return args, err
+	}
+
+	args.SquashOrder, err = parseOrder(args.rawOrders["until"], "until")
+	if err != nil {
+		return args, err
+	}
+
+	rawPadding := service.parseOption(
+		paddingOption,
+		EnvVarNumberingPadding,
+		config.Get("padding", strconv.Itoa(DefaultNumberingPadding)),
+	)
+	args.NumberingPadding, err = strconv.ParseUint(rawPadding, 10, 64)
+	if err != nil || args.NumberingPadding > 20 {
+		return args, errors.Errorf("invalid 'padding' option: [%s] (it must be a number up to 20)", rawPadding)
+	}
+
+	args.Schemas = splitList(service.parseOption(schemasOption, EnvVarSchemas, config.Get("schemas", "")))
+
+	rawConcurrency := service.parseOption(concurrencyOption, EnvVarConcurrency, config.Get("concurrency", "1"))
+	args.Concurrency, err = strconv.ParseUint(rawConcurrency, 10, 64)
+	if err != nil || args.Concurrency == 0 {
+		return args, errors.Errorf("invalid 'concurrency' option: [%s] (it must be a positive number)", rawConcurrency)
+	}
+
+	args.WaitTimeout, err = parseDuration(
+		service.parseOption(timeoutOption, EnvVarWaitTimeout, config.Get("timeout", DefaultWaitTimeout.String())),
+		"timeout",
+		false,
+	)
+	if err != nil {
+		return args, err
+	}
+
+	args.ConnectionRetry.Timeout, err = parseDuration(
+		service.parseOption(connectTimeoutOption, EnvVarConnectTimeout, config.Get("connect-timeout", "0s")),
+		"connect-timeout",
+		true,
+	)
+	if err != nil {
+		return args, err
+	}
+
+	args.ConnectionRetry.InitialBackoff, err = parseDuration(
+		service.parseOption(
+			connectBackoffOption,
+			EnvVarConnectBackoff,
+			config.Get("connect-backoff", DefaultConnectionInitialBackoff.String()),
+		),
+		"connect-backoff",
+		false,
+	)
+	if err != nil {
+		return args, err
+	}
+
+	args.Options = make(map[string]string, len(customOptions))
+	for _, option := range customOptions {
+		defaultValue := config.Get(option.Name, option.Default)
+		if option.IsBool {
+			args.Options[option.Name] = strconv.FormatBool(
+				service.parseBoolOption(option.Name, customBoolOptions[option.Name], option.EnvVar, defaultValue),
+			)
+			continue
+		}
+		args.Options[option.Name] = service.parseOption(customStringOptions[option.Name], option.EnvVar, defaultValue)
+	}
+
+	args.RedoSteps, err = parseSteps(service.parseOption(stepsOption, EnvVarRedoSteps, config.Get("steps", "1")))
+	if err != nil {
+		return args, err
+	}
+
+	return args, nil
+}
+
+// parseOrder parses the order of a migration (the number on its file name), if any.
+func parseOrder(rawOrder string, optionName string) (uint64, error) {
+	if rawOrder == "" {
+		return 0, nil
 	}
+
+	order, err := strconv.ParseUint(rawOrder, 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid '%s' option: [%s] (it must be a migration order)", optionName, rawOrder)
+	}
+
+	return order, nil
 }
 
-func parseMigrationsDirectoryPath(pathOption *string) string {
+// parseDuration parses the duration of an option (e.g. 5m or 30s), which must be positive unless it can be zero.
+func parseDuration(rawDuration string, optionName string, canBeZero bool) (time.Duration, error) {
+	duration, err := time.ParseDuration(rawDuration)
+	if err != nil || duration < 0 || duration == 0 && !canBeZero {
+		return 0, errors.Errorf("invalid '%s' option: [%s] (it must be a positive duration, e.g. 5m)",
+			optionName,
+			rawDuration)
+	}
+
+	return duration, nil
+}
+
+// parseSteps parses a positive number of migrations.
+func parseSteps(rawSteps string) (uint64, error) {
+	steps, err := strconv.ParseUint(rawSteps, 10, 64)
+	if err != nil || steps == 0 {
+		return 0, errors.Errorf("invalid 'steps' option: [%s] (it must be a positive number)", rawSteps)
+	}
+
+	return steps, nil
+}
+
+// parseMigrationsDirectoryPaths parses the migrations directories, given as a repeated 'path' option or as
+// comma-separated lists.
+func (service CommandArgumentService) parseMigrationsDirectoryPaths(pathOption []string, configValue string) []string {
 	// Parse the path command option.
-	if pathOption != nil && *pathOption != "" {
-		return helpers.AddTrailingSlashToPathIfNeeded(*pathOption)
+	if len(pathOption) > 0 {
+		return splitMigrationsDirectoryPaths(pathOption...)
 	}
 
 	// Parse the path environment variable.
-	pathEnvVar := os.Getenv(EnvVarMigrationsPath)
+	pathEnvVar := service.getEnvVar(EnvVarMigrationsPath)
 	if pathEnvVar != "" {
-		return helpers.AddTrailingSlashToPathIfNeeded(pathEnvVar)
+		return splitMigrationsDirectoryPaths(pathEnvVar)
 	}
 
-	return ""
+	// Use the config file value.
+	return splitMigrationsDirectoryPaths(configValue)
 }
 
-func parseNewMigrationName(nameOption *string) string {
+func splitMigrationsDirectoryPaths(values ...string) []string {
+	var paths []string
+	for _, path := range splitList(values...) {
+		paths = append(paths, helpers.AddTrailingSlashToPathIfNeeded(path))
+	}
+
+	return paths
+}
+
+// splitList splits comma-separated lists, ignoring empty values.
+func splitList(values ...string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items
+}
+
+func (service CommandArgumentService) parseNewMigrationName(nameOption *string) string {
 	// Parse the name command option.
 	if nameOption != nil && *nameOption != "" {
 		return *nameOption
 	}
 
 	// Parse the name environment variable.
-	return os.Getenv(EnvVarNewMigrationName)
+	return service.getEnvVar(EnvVarNewMigrationName)
+}
+
+// parseOption returns the value of a command option, falling back to an environment variable and then to a default
+// value.
+func (service CommandArgumentService) parseOption(option *string, envVar string, defaultValue string) string {
+	if option != nil && *option != "" {
+		return *option
+	}
+
+	envVarValue := ""
+	if envVar != "" {
+		envVarValue = service.getEnvVar(envVar)
+	}
+	if envVarValue != "" {
+		return envVarValue
+	}
+
+	return defaultValue
+}
+
+// parseBoolOption returns the value of a command option if it's given (even if it's false) or, otherwise, true if an
+// environment variable (or else the default value) is set to a true value (1, true, yes).
+func (service CommandArgumentService) parseBoolOption(
+	name string,
+	option *bool,
+	envVar string,
+	defaultValue string,
+) bool {
+	if option != nil && (*option || service.isOptionSet(name)) {
+		return *option
+	}
+
+	switch strings.ToLower(service.parseOption(nil, envVar, defaultValue)) {
+	case "1", "true", "yes":
+		return true
+	}
+
+	return false
+}
+
+// optionString defines a string option, if the parser is an adapters.ExtendedArgumentParser. Otherwise, it returns nil
+// and the option can only be set with its environment variable or the config file, so a parser that only implements
+// adapters.ArgumentParser is only asked for the options it has always defined (path and name).
+func (service CommandArgumentService) optionString(name string) *string {
+	if _, isExtended := service.parser.(adapters.ExtendedArgumentParser); !isExtended {
+		return nil
+	}
+
+	return service.parser.OptionString(name, "")
+}
+
+// optionBool defines a bool option, if the parser can (see adapters.ExtendedArgumentParser). Otherwise, it returns nil
+// and the option can only be set with its environment variable or the config file.
+func (service CommandArgumentService) optionBool(name string) *bool {
+	parser, isExtended := service.parser.(adapters.ExtendedArgumentParser)
+	if !isExtended {
+		return nil
+	}
+
+	return parser.OptionBool(name, false)
+}
+
+// optionStrings defines an option that can be given more than once or, if the parser cannot (see
+// adapters.ExtendedArgumentParser), once. The returned function reads its values after parsing.
+func (service CommandArgumentService) optionStrings(name string) func() []string {
+	parser, isExtended := service.parser.(adapters.ExtendedArgumentParser)
+	if isExtended {
+		values := parser.OptionStrings(name)
+		return func() []string {
+			if values == nil {
+				return nil
+			}
+
+			return *values
+		}
+	}
+
+	value := service.parser.OptionString(name, "")
+	return func() []string {
+		if value == nil || *value == "" {
+			return nil
+		}
+
+		return []string{*value}
+	}
+}
+
+// isOptionSet returns true if the option has been given on the command line, if the parser can tell it (see
+// adapters.OptionSetChecker).
+func (service CommandArgumentService) isOptionSet(name string) bool {
+	checker, canCheck := service.parser.(adapters.OptionSetChecker)
+
+	return canCheck && checker.IsOptionSet(name)
 }
 
 func (service CommandArgumentService) parseCommand() string {
@@ -124,7 +584,7 @@ func (service CommandArgumentService) parseCommand() string {
 		return positionalArguments[0]
 	}
 
-	commandEnvVar := os.Getenv(EnvVarCommand)
+	commandEnvVar := service.getEnvVar(EnvVarCommand)
 	if commandEnvVar != "" {
 		return commandEnvVar
 	}
@@ -133,12 +593,36 @@ func (service CommandArgumentService) parseCommand() string {
 	return "migrate"
 }
 
-func getRearrangedArguments() []string {
+// parseCommandArguments returns the positional arguments after the command, if the command is given as an argument.
+func (service CommandArgumentService) parseCommandArguments() []string {
+	positionalArguments := service.parser.PositionalArguments()
+	if len(positionalArguments) < 2 {
+		return nil
+	}
+
+	return positionalArguments[1:]
+}
+
+// getRawArguments returns the command line arguments, without the executable.
+func (service CommandArgumentService) getRawArguments() []string {
+	if service.rawArguments != nil {
+		return service.rawArguments
+	}
+
 	if len(os.Args) == 0 {
 		return nil
 	}
 
-	args := os.Args[1:]
+	return os.Args[1:]
+}
+
+// getRearrangedArguments moves the options before the positional arguments, since flags stop parsing at the first
+// positional argument.
+func getRearrangedArguments(args []string) []string {
+	if args == nil {
+		return nil
+	}
+
 	rearrangedArgs := make([]string, len(args))
 	options := make([]string, 0)
 	nonOptions := make([]string, 0)
@@ -166,9 +650,9 @@ func getRearrangedArguments() []string {
 	return rearrangedArgs
 }
 
-func isCommandValid(command string) bool {
-	for _, validCommand := range ValidCommands {
-		if validCommand == command {
+func containsString(haystack []string, needle string) bool {
+	for _, value := range haystack {
+		if value == needle {
 			return true
 		}
 	}
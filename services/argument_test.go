@@ -3,9 +3,11 @@ package services_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jimenezmaximiliano/migrations/mocks"
 	"github.com/jimenezmaximiliano/migrations/services"
@@ -39,6 +41,35 @@ func TestParsingValidCommandLineArgs(test *testing.T) {
 	assert.Equal(test, "migrate", args.Command)
 }
 
+func TestParsingValidCommandLineArgsWithAnExtendedParser(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "migrate", "-path=/tmp"}
+	path := "/tmp"
+	name := ""
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "name", mock.AnythingOfType("string")).
+		Return(&name)
+	parser.On("PositionalArguments").
+		Return([]string{"migrate"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, ok := service.ParseAndValidate()
+
+	assert.True(test, ok)
+	assert.Equal(test, "/tmp/", args.MigrationsPath)
+	assert.Equal(test, "", args.MigrationName)
+	assert.Equal(test, "migrate", args.Command)
+}
+
 func TestParsingValidEnvVarArgs(test *testing.T) {
 	var err error
 	display := &mocks.Display{}
@@ -82,6 +113,50 @@ func TestParsingValidEnvVarArgs(test *testing.T) {
 	assert.Equal(test, "migrate", args.Command)
 }
 
+func TestParsingValidEnvVarArgsWithAnExtendedParser(test *testing.T) {
+	var err error
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{}
+
+	err = os.Setenv(services.EnvVarCommand, "migrate")
+	defer func() {
+		assert.Nil(test, os.Unsetenv(services.EnvVarCommand))
+	}()
+	assert.Nil(test, err)
+	err = os.Setenv(services.EnvVarNewMigrationName, "newMigrationName")
+	defer func() {
+		assert.Nil(test, os.Unsetenv(services.EnvVarNewMigrationName))
+	}()
+	assert.Nil(test, err)
+	err = os.Setenv(services.EnvVarMigrationsPath, "/tmp/")
+	defer func() {
+		assert.Nil(test, os.Unsetenv(services.EnvVarMigrationsPath))
+	}()
+	assert.Nil(test, err)
+
+	parser.On("OptionStrings", "path").
+		Return(nil)
+	parser.On("OptionString", "name", mock.AnythingOfType("string")).
+		Return(nil)
+	parser.On("PositionalArguments").
+		Return(nil)
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, ok := service.ParseAndValidate()
+
+	assert.True(test, ok)
+	assert.Equal(test, "/tmp/", args.MigrationsPath)
+	assert.Equal(test, "newMigrationName", args.MigrationName)
+	assert.Equal(test, "migrate", args.Command)
+}
+
 func TestParsingValidCommandLineArgsInDifferentOrder(test *testing.T) {
 	display := &mocks.Display{}
 	defer display.AssertExpectations(test)
@@ -166,6 +241,35 @@ func TestWrongCommand(test *testing.T) {
 	assert.False(test, ok)
 }
 
+func TestWrongCommandReturnsAnArgumentsError(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "oops"}
+	path := "/tmp"
+	name := ""
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "name", mock.AnythingOfType("string")).
+		Return(&name)
+	parser.On("PositionalArguments").
+		Return([]string{"oops"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	display.On("DisplayError", mock.MatchedBy(func(err error) bool { return true })).Return(nil)
+	display.On("DisplayHelp").Return(nil)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	_, err := service.ParseAndValidateArguments()
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
 func TestMissingOption(test *testing.T) {
 	display := &mocks.Display{}
 	defer display.AssertExpectations(test)
@@ -194,6 +298,35 @@ func TestMissingOption(test *testing.T) {
 	assert.False(test, ok)
 }
 
+func TestMissingOptionWithAnExtendedParser(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "migrate"}
+	path := ""
+	name := ""
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "name", mock.AnythingOfType("string")).
+		Return(&name)
+	parser.On("PositionalArguments").
+		Return([]string{"migrate"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	display.On("DisplayError", mock.MatchedBy(func(err error) bool { return true })).Return(nil)
+	display.On("DisplayHelp").Return(nil)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	_, ok := service.ParseAndValidate()
+
+	assert.False(test, ok)
+}
+
 func TestMissingOptionOnCreate(test *testing.T) {
 	display := &mocks.Display{}
 	defer display.AssertExpectations(test)
@@ -221,3 +354,628 @@ func TestMissingOptionOnCreate(test *testing.T) {
 
 	assert.False(test, ok)
 }
+
+func TestParsingTheColorOption(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "migrate", "-path=/tmp", "-color=always"}
+	path := "/tmp"
+	color := "always"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "color", mock.AnythingOfType("string")).
+		Return(&color)
+	parser.On("PositionalArguments").
+		Return([]string{"migrate"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, ok := service.ParseAndValidate()
+
+	assert.True(test, ok)
+	assert.Equal(test, services.ColorModeAlways, args.Color)
+}
+
+func TestColorDefaultsToAuto(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "migrate", "-path=/tmp"}
+	path := "/tmp"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("PositionalArguments").
+		Return([]string{"migrate"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, ok := service.ParseAndValidate()
+
+	assert.True(test, ok)
+	assert.Equal(test, services.ColorModeAuto, args.Color)
+}
+
+func TestInvalidColorOption(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "migrate", "-path=/tmp", "-color=rainbow"}
+	path := "/tmp"
+	color := "rainbow"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "color", mock.AnythingOfType("string")).
+		Return(&color)
+	parser.On("PositionalArguments").
+		Return([]string{"migrate"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	display.On("DisplayError", mock.MatchedBy(func(err error) bool { return true })).Return(nil)
+	display.On("DisplayHelp").Return(nil)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	_, ok := service.ParseAndValidate()
+
+	assert.False(test, ok)
+}
+
+func TestParsingTheBaselineOrder(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "baseline", "-path=/tmp", "-to=1627676757857350000"}
+	path := "/tmp"
+	to := "1627676757857350000"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "to", mock.AnythingOfType("string")).
+		Return(&to)
+	parser.On("PositionalArguments").
+		Return([]string{"baseline"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, ok := service.ParseAndValidate()
+
+	assert.True(test, ok)
+	assert.Equal(test, uint64(1627676757857350000), args.BaselineOrder)
+}
+
+func TestMissingOptionOnBaseline(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "baseline", "-path=/tmp"}
+	path := "/tmp"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("PositionalArguments").
+		Return([]string{"baseline"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	display.On("DisplayError", mock.MatchedBy(func(err error) bool { return true })).Return(nil)
+	display.On("DisplayHelp").Return(nil)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	_, err := service.ParseAndValidateArguments()
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestMissingOptionOnSquash(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "squash", "-path=/tmp"}
+	path := "/tmp"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("PositionalArguments").
+		Return([]string{"squash"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	display.On("DisplayError", mock.MatchedBy(func(err error) bool {
+		return assert.Contains(test, err.Error(), "missing 'until' option for command 'squash'")
+	})).Return(nil)
+	display.On("DisplayHelp").Return(nil)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	_, err := service.ParseAndValidateArguments()
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestABaselineToOrderZeroIsNotMissing(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "baseline", "-path=/tmp", "-to=0"}
+	path := "/tmp"
+	to := "0"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "to", mock.AnythingOfType("string")).
+		Return(&to)
+	parser.On("PositionalArguments").
+		Return([]string{"baseline"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, err := service.ParseAndValidateArguments()
+
+	require.Nil(test, err)
+	assert.Equal(test, uint64(0), args.BaselineOrder)
+}
+
+func TestMissingNameOnMarkApplied(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "mark-applied", "-path=/tmp"}
+	path := "/tmp"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("PositionalArguments").
+		Return([]string{"mark-applied"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	display.On("DisplayError", mock.MatchedBy(func(err error) bool { return true })).Return(nil)
+	display.On("DisplayHelp").Return(nil)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	_, err := service.ParseAndValidateArguments()
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestParsingTheYesOptionFromAnEnvVar(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "repair", "-path=/tmp"}
+	err := os.Setenv(services.EnvVarAssumeYes, "true")
+	require.Nil(test, err)
+	defer func() {
+		assert.Nil(test, os.Unsetenv(services.EnvVarAssumeYes))
+	}()
+	path := "/tmp"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("PositionalArguments").
+		Return([]string{"repair"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, err := service.ParseAndValidateArguments()
+
+	require.Nil(test, err)
+	assert.True(test, args.AssumeYes)
+}
+
+func TestInvalidStepsOption(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "redo", "-path=/tmp", "-steps=0"}
+	path := "/tmp"
+	steps := "0"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "steps", mock.AnythingOfType("string")).
+		Return(&steps)
+	parser.On("PositionalArguments").
+		Return([]string{"redo"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	display.On("DisplayError", mock.MatchedBy(func(err error) bool { return true })).Return(nil)
+	display.On("DisplayHelp").Return(nil)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	_, err := service.ParseAndValidateArguments()
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestInvalidNumberingOption(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "create", "-path=/tmp", "-name=a", "-numbering=random"}
+	path := "/tmp"
+	name := "a"
+	numbering := "random"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "name", mock.AnythingOfType("string")).
+		Return(&name)
+	parser.On("OptionString", "numbering", mock.AnythingOfType("string")).
+		Return(&numbering)
+	parser.On("PositionalArguments").
+		Return([]string{"create"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	display.On("DisplayError", mock.MatchedBy(func(err error) bool { return true })).Return(nil)
+	display.On("DisplayHelp").Return(nil)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	_, err := service.ParseAndValidateArguments()
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestParsingArgumentsWithAParserThatOnlyDefinesStringOptions(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "migrate", "-path=/tmp"}
+	err := os.Setenv(services.EnvVarAssumeYes, "true")
+	require.Nil(test, err)
+	defer func() {
+		assert.Nil(test, os.Unsetenv(services.EnvVarAssumeYes))
+	}()
+	path := "/tmp"
+	empty := ""
+
+	parser.On("OptionString", "path", mock.AnythingOfType("string")).
+		Return(&path)
+	parser.On("OptionString", mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Return(&empty)
+	parser.On("PositionalArguments").
+		Return([]string{"migrate"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, err := service.ParseAndValidateArguments()
+
+	require.Nil(test, err)
+	assert.Equal(test, []string{"/tmp/"}, args.MigrationsPaths)
+	assert.True(test, args.AssumeYes)
+}
+
+// expectOtherOptions makes the parser return empty values for the options that are not relevant to a test.
+func expectOtherOptions(parser *mocks.ExtendedArgumentParser) {
+	empty := ""
+	parser.On("OptionString", mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Return(&empty).
+		Maybe()
+	no := false
+	parser.On("OptionBool", mock.AnythingOfType("string"), mock.AnythingOfType("bool")).
+		Return(&no).
+		Maybe()
+	parser.On("OptionStrings", mock.AnythingOfType("string")).
+		Return(&[]string{}).
+		Maybe()
+}
+
+func TestParsingTheDSNFromAnEnvVar(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "migrate", "-path=/tmp", "-driver=sqlite"}
+	err := os.Setenv(services.EnvVarDatabaseURL, "/tmp/db.sqlite")
+	require.Nil(test, err)
+	defer func() {
+		assert.Nil(test, os.Unsetenv(services.EnvVarDatabaseURL))
+	}()
+	path := "/tmp"
+	driver := "sqlite"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "driver", mock.AnythingOfType("string")).
+		Return(&driver)
+	parser.On("PositionalArguments").
+		Return([]string{"migrate"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, err := service.ParseAndValidateArguments()
+
+	require.Nil(test, err)
+	assert.Equal(test, "sqlite", args.Driver)
+	assert.Equal(test, "/tmp/db.sqlite", args.DSN)
+}
+
+func TestParsingSeveralMigrationsPaths(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "migrate", "-path=/app/core", "-path=/app/billing,/app/users/"}
+	paths := []string{"/app/core", "/app/billing,/app/users/"}
+
+	parser.On("OptionStrings", "path").
+		Return(&paths)
+	parser.On("PositionalArguments").
+		Return([]string{"migrate"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, err := service.ParseAndValidateArguments()
+
+	require.Nil(test, err)
+	assert.Equal(test, "/app/core/", args.MigrationsPath)
+	assert.Equal(test, []string{"/app/core/", "/app/billing/", "/app/users/"}, args.MigrationsPaths)
+	assert.Equal(test, []string{"/app/billing/", "/app/users/"}, args.GetAdditionalMigrationsPaths())
+}
+
+func TestParsingSchemasAndConcurrency(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "migrate", "-path=/tmp", "-schemas=tenant_a, tenant_b", "-concurrency=4"}
+	path := "/tmp"
+	schemas := "tenant_a, tenant_b"
+	concurrency := "4"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "schemas", mock.AnythingOfType("string")).
+		Return(&schemas)
+	parser.On("OptionString", "concurrency", mock.AnythingOfType("string")).
+		Return(&concurrency)
+	parser.On("PositionalArguments").
+		Return([]string{"migrate"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, err := service.ParseAndValidateArguments()
+
+	require.Nil(test, err)
+	assert.Equal(test, []string{"tenant_a", "tenant_b"}, args.Schemas)
+	assert.Equal(test, uint64(4), args.Concurrency)
+}
+
+func TestParsingTheWaitTimeout(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "wait", "-path=/tmp", "-timeout=90s"}
+	path := "/tmp"
+	timeout := "90s"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "timeout", mock.AnythingOfType("string")).
+		Return(&timeout)
+	parser.On("PositionalArguments").
+		Return([]string{"wait"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, err := service.ParseAndValidateArguments()
+
+	require.Nil(test, err)
+	assert.Equal(test, 90*time.Second, args.WaitTimeout)
+}
+
+func TestParsingTheConnectionRetry(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "migrate", "-path=/tmp", "-connect-timeout=1m", "-connect-backoff=1s"}
+	path := "/tmp"
+	connectTimeout := "1m"
+	connectBackoff := "1s"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "connect-timeout", mock.AnythingOfType("string")).
+		Return(&connectTimeout)
+	parser.On("OptionString", "connect-backoff", mock.AnythingOfType("string")).
+		Return(&connectBackoff)
+	parser.On("PositionalArguments").
+		Return([]string{"migrate"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, err := service.ParseAndValidateArguments()
+
+	require.Nil(test, err)
+	assert.Equal(test, time.Minute, args.ConnectionRetry.Timeout)
+	assert.Equal(test, time.Second, args.ConnectionRetry.InitialBackoff)
+}
+
+func TestInvalidWaitTimeout(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "wait", "-path=/tmp", "-timeout=5"}
+	path := "/tmp"
+	timeout := "5"
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "timeout", mock.AnythingOfType("string")).
+		Return(&timeout)
+	parser.On("PositionalArguments").
+		Return([]string{"wait"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	display.On("DisplayError", mock.MatchedBy(func(err error) bool { return true })).Return(nil)
+	display.On("DisplayHelp").Return(nil)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	_, err := service.ParseAndValidateArguments()
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestParsingTheHelpCommandWithATopicAndWithoutAPath(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "help", "create"}
+
+	parser.On("PositionalArguments").
+		Return([]string{"help", "create"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, err := service.ParseAndValidateArguments()
+
+	require.Nil(test, err)
+	assert.Equal(test, "help", args.Command)
+	assert.Equal(test, []string{"create"}, args.CommandArguments)
+	assert.Empty(test, args.MigrationsPath)
+}
+
+func getSeedCommandRegistry(test *testing.T) *services.CommandRegistry {
+	registry := services.DefaultCommandRegistry()
+	require.Nil(test, registry.RegisterOption(services.OptionSpec{Name: "fixtures", EnvVar: "SEED_FIXTURES"}, false))
+	require.Nil(test, registry.RegisterOption(services.OptionSpec{Name: "truncate", IsBool: true}, false))
+	require.Nil(test, registry.RegisterOption(services.OptionSpec{Name: "batch", Default: "100"}, false))
+	require.Nil(test, registry.RegisterCommand(services.CommandSpec{
+		Name:            "seed",
+		Options:         []string{"truncate", "batch"},
+		RequiredOptions: []string{"path", "fixtures"},
+	}))
+
+	return registry
+}
+
+func TestParsingTheOptionsOfACustomCommand(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "seed", "-path=/tmp", "-truncate"}
+	err := os.Setenv("SEED_FIXTURES", "/app/fixtures")
+	require.Nil(test, err)
+	defer func() {
+		assert.Nil(test, os.Unsetenv("SEED_FIXTURES"))
+	}()
+	yes := true
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{"/tmp"})
+	parser.On("OptionBool", "truncate", false).
+		Return(&yes)
+	parser.On("PositionalArguments").
+		Return([]string{"seed"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser).WithCommandRegistry(getSeedCommandRegistry(test))
+
+	args, err := service.ParseAndValidateArguments()
+
+	require.Nil(test, err)
+	assert.Equal(test, "seed", args.Command)
+	assert.Equal(test, "/app/fixtures", args.GetOption("fixtures"))
+	assert.Equal(test, "true", args.GetOption("truncate"))
+	assert.Equal(test, "100", args.GetOption("batch"))
+}
+
+func TestParsingACustomCommandWithoutARequiredOptionFails(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	os.Args = []string{"", "seed", "-path=/tmp"}
+
+	parser.On("OptionStrings", "path").
+		Return(&[]string{"/tmp"})
+	parser.On("PositionalArguments").
+		Return([]string{"seed"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+	display.On("DisplayError", mock.Anything).Return(nil)
+	display.On("DisplayHelp").Return(nil)
+
+	service := services.NewCommandArgumentService(display, parser).WithCommandRegistry(getSeedCommandRegistry(test))
+
+	_, err := service.ParseAndValidateArguments()
+
+	require.NotNil(test, err)
+	assert.Equal(test, "missing 'fixtures' option for command 'seed'", err.Error())
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/helpers"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+)
+
+// Bookkeeper handles the migrations table without running any migration.
+type Bookkeeper interface {
+	Baseline(order uint64) (models.Collection, error)
+	MarkAsRun(migrationFileName string) (models.Migration, error)
+	UnmarkAsRun(migrationFileName string) (models.Migration, error)
+}
+
+type bookkeepingService struct {
+	migrationFetcherService         Fetcher
+	dbRepository                    repositories.DBRepository
+	migrationsDirectoryAbsolutePath string
+}
+
+// Ensure bookkeepingService implements Bookkeeper.
+var _ Bookkeeper = bookkeepingService{}
+
+// NewBookkeepingService returns an implementation of Bookkeeper.
+func NewBookkeepingService(
+	migrationFetcherService Fetcher,
+	DBRepository repositories.DBRepository,
+	migrationsDirectoryAbsolutePath string,
+) Bookkeeper {
+	return bookkeepingService{
+		migrationFetcherService:         migrationFetcherService,
+		dbRepository:                    DBRepository,
+		migrationsDirectoryAbsolutePath: helpers.AddTrailingSlashToPathIfNeeded(migrationsDirectoryAbsolutePath),
+	}
+}
+
+// Baseline creates the migrations table if needed and registers every migration up to the given order (inclusive)
+// as run, without running it. Returns the migrations that have been registered.
+func (service bookkeepingService) Baseline(order uint64) (models.Collection, error) {
+	err := service.prepare()
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	allMigrations, err := service.migrationFetcherService.GetMigrations(service.migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	result := models.Collection{}
+	for _, migration := range allMigrations.GetMigrationsToRun() {
+		if migration.GetOrder() > order {
+			break
+		}
+
+		err = service.dbRepository.RegisterRunMigration(models.GetQualifiedName(migration))
+		if err != nil {
+			return result, NewBookkeepingError(err)
+		}
+
+		err = result.Add(migration.NewAsSuccessful())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// MarkAsRun registers a migration of the migrations directory as run, without running it (e.g. after applying it
+// manually). Returns the migration as successful.
+func (service bookkeepingService) MarkAsRun(migrationFileName string) (models.Migration, error) {
+	migration, err := service.getMigration(migrationFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !migration.ShouldBeRun() {
+		return nil, NewArgumentsError(errors.Errorf(
+			"the migration [%s] is already registered as run",
+			models.GetQualifiedName(migration),
+		))
+	}
+
+	err = service.dbRepository.RegisterRunMigration(models.GetQualifiedName(migration))
+	if err != nil {
+		return nil, NewBookkeepingError(err)
+	}
+
+	return migration.NewAsSuccessful(), nil
+}
+
+// UnmarkAsRun unregisters a migration of the migrations directory, so it's run again next time (the migration itself
+// is not reverted). Returns the migration as not run. It needs a DBRepository that implements
+// repositories.RunMigrationUnregisterer.
+func (service bookkeepingService) UnmarkAsRun(migrationFileName string) (models.Migration, error) {
+	migration, err := service.getMigration(migrationFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if migration.ShouldBeRun() {
+		return nil, NewArgumentsError(errors.Errorf(
+			"the migration [%s] is not registered as run",
+			models.GetQualifiedName(migration),
+		))
+	}
+
+	unregisterer, err := getRunMigrationUnregisterer(service.dbRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	err = unregisterer.UnregisterRunMigration(models.GetQualifiedName(migration))
+	if err != nil {
+		return nil, NewBookkeepingError(err)
+	}
+
+	return migration.NewAsNotRun(), nil
+}
+
+// getRunMigrationUnregisterer returns the repositories.RunMigrationUnregisterer implemented by the DBRepository, or a
+// BookkeepingError if it doesn't implement it.
+func getRunMigrationUnregisterer(
+	dbRepository repositories.DBRepository,
+) (repositories.RunMigrationUnregisterer, error) {
+	unregisterer, canUnregister := repositories.UnwrapDBRepository(dbRepository).(repositories.RunMigrationUnregisterer)
+	if !canUnregister {
+		return nil, NewBookkeepingError(errors.New("the DB repository cannot unregister migrations"))
+	}
+
+	return unregisterer, nil
+}
+
+// getMigration returns a migration of the migrations directories given its file name (the .sql extension is optional),
+// prefixed by its source for the migrations of additional directories (see GetMigrationSource).
+func (service bookkeepingService) getMigration(migrationFileName string) (models.Migration, error) {
+	err := service.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	allMigrations, err := service.migrationFetcherService.GetMigrations(service.migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasSuffix(migrationFileName, ".sql") {
+		migrationFileName += ".sql"
+	}
+
+	for _, migration := range allMigrations.GetAll() {
+		if models.GetQualifiedName(migration) == migrationFileName {
+			return migration, nil
+		}
+	}
+
+	return nil, NewArgumentsError(errors.Errorf(
+		"the migration [%s] does not exist on the migrations directory [%s]",
+		migrationFileName,
+		service.migrationsDirectoryAbsolutePath,
+	))
+}
+
+func (service bookkeepingService) prepare() error {
+	err := service.dbRepository.Ping()
+	if err != nil {
+		return NewDBConnectionError(errors.Wrap(err, "failed to connect to the DB"))
+	}
+
+	err = service.dbRepository.CreateMigrationsTableIfNeeded()
+	if err != nil {
+		return NewBookkeepingError(err)
+	}
+
+	return nil
+}
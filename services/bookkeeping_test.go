@@ -0,0 +1,238 @@
+package services_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestBaselining(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("RegisterRunMigration", "2_b.sql").Return(nil)
+	db.On("RegisterRunMigration", "3_c.sql").Return(nil)
+
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	collection := models.Collection{}
+	migration1, _ := models.NewMigration("/tmp/1_a.sql", "SELECT 1", models.StatusSuccessful)
+	require.Nil(test, collection.Add(migration1))
+	migration2, _ := models.NewMigration("/tmp/2_b.sql", "SELECT 2", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration2))
+	migration3, _ := models.NewMigration("/tmp/3_c.sql", "SELECT 3", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration3))
+	migration4, _ := models.NewMigration("/tmp/4_d.sql", "SELECT 4", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration4))
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+
+	service := services.NewBookkeepingService(fetcher, db, "/tmp")
+
+	result, err := service.Baseline(3)
+
+	require.Nil(test, err)
+	require.Len(test, result.GetAll(), 2)
+	assert.Equal(test, "2_b.sql", result.GetAll()[0].GetName())
+	assert.True(test, result.GetAll()[0].WasSuccessful())
+	assert.Equal(test, "3_c.sql", result.GetAll()[1].GetName())
+	db.AssertNotCalled(test, "RunMigrationQuery", "SELECT 2")
+}
+
+func TestBaseliningFailsIfTheDBConnectionDoesNotWork(test *testing.T) {
+	test.Parallel()
+
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(fmt.Errorf("db connection error"))
+
+	service := services.NewBookkeepingService(fetcher, db, "/tmp")
+
+	_, err := service.Baseline(3)
+
+	assert.Equal(test, services.ExitCodeDBUnavailable, services.ExitCode(err))
+}
+
+func TestBaseliningFailsIfAMigrationCannotBeRegistered(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("RegisterRunMigration", "1_a.sql").Return(fmt.Errorf("insert failed"))
+
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	collection := models.Collection{}
+	migration1, _ := models.NewMigration("/tmp/1_a.sql", "SELECT 1", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration1))
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+
+	service := services.NewBookkeepingService(fetcher, db, "/tmp")
+
+	_, err := service.Baseline(1)
+
+	assert.Equal(test, services.ExitCodeBookkeepingFailed, services.ExitCode(err))
+}
+
+func getBookkeepingFetcher(test *testing.T) *mocks.Fetcher {
+	fetcher := &mocks.Fetcher{}
+	collection := models.Collection{}
+	migration1, _ := models.NewMigration("/tmp/1_a.sql", "SELECT 1", models.StatusSuccessful)
+	require.Nil(test, collection.Add(migration1))
+	migration2, _ := models.NewMigration("/tmp/2_b.sql", "SELECT 2", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration2))
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+
+	return fetcher
+}
+
+func TestMarkingAMigrationAsRun(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("RegisterRunMigration", "2_b.sql").Return(nil)
+	fetcher := getBookkeepingFetcher(test)
+	defer fetcher.AssertExpectations(test)
+
+	service := services.NewBookkeepingService(fetcher, db, "/tmp")
+
+	migration, err := service.MarkAsRun("2_b")
+
+	require.Nil(test, err)
+	assert.Equal(test, "2_b.sql", migration.GetName())
+	assert.True(test, migration.WasSuccessful())
+}
+
+func TestMarkingAMigrationAsRunFailsIfItIsAlreadyRegistered(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	fetcher := getBookkeepingFetcher(test)
+	defer fetcher.AssertExpectations(test)
+
+	service := services.NewBookkeepingService(fetcher, db, "/tmp")
+
+	_, err := service.MarkAsRun("1_a.sql")
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+	db.AssertNotCalled(test, "RegisterRunMigration", "1_a.sql")
+}
+
+func TestMarkingAMigrationAsRunFailsIfTheFileDoesNotExist(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	fetcher := getBookkeepingFetcher(test)
+	defer fetcher.AssertExpectations(test)
+
+	service := services.NewBookkeepingService(fetcher, db, "/tmp")
+
+	_, err := service.MarkAsRun("3_c.sql")
+
+	require.NotNil(test, err)
+	assert.Contains(test, err.Error(), "3_c.sql")
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestUnmarkingAMigration(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	unregisterer := &mocks.RunMigrationUnregisterer{}
+	defer unregisterer.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	unregisterer.On("UnregisterRunMigration", "1_a.sql").Return(nil)
+	fetcher := getBookkeepingFetcher(test)
+	defer fetcher.AssertExpectations(test)
+
+	service := services.NewBookkeepingService(fetcher, unregisteringDBRepository{db, unregisterer}, "/tmp")
+
+	migration, err := service.UnmarkAsRun("1_a.sql")
+
+	require.Nil(test, err)
+	assert.Equal(test, "1_a.sql", migration.GetName())
+	assert.True(test, migration.ShouldBeRun())
+}
+
+func TestUnmarkingAMigrationFailsIfItIsNotRegistered(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	fetcher := getBookkeepingFetcher(test)
+	defer fetcher.AssertExpectations(test)
+
+	service := services.NewBookkeepingService(fetcher, db, "/tmp")
+
+	_, err := service.UnmarkAsRun("2_b.sql")
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestUnmarkingAMigrationFailsIfTheDeleteFails(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	unregisterer := &mocks.RunMigrationUnregisterer{}
+	defer unregisterer.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	unregisterer.On("UnregisterRunMigration", "1_a.sql").Return(fmt.Errorf("delete failed"))
+	fetcher := getBookkeepingFetcher(test)
+	defer fetcher.AssertExpectations(test)
+
+	service := services.NewBookkeepingService(fetcher, unregisteringDBRepository{db, unregisterer}, "/tmp")
+
+	_, err := service.UnmarkAsRun("1_a.sql")
+
+	assert.Equal(test, services.ExitCodeBookkeepingFailed, services.ExitCode(err))
+}
+
+func TestUnmarkingAMigrationFailsIfTheDBRepositoryCannotUnregisterMigrations(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	fetcher := getBookkeepingFetcher(test)
+	defer fetcher.AssertExpectations(test)
+
+	service := services.NewBookkeepingService(fetcher, db, "/tmp")
+
+	_, err := service.UnmarkAsRun("1_a.sql")
+
+	assert.Equal(test, services.ExitCodeBookkeepingFailed, services.ExitCode(err))
+}
+
+// unregisteringDBRepository is a DBRepository that implements repositories.RunMigrationUnregisterer.
+type unregisteringDBRepository struct {
+	*mocks.DBRepository
+	*mocks.RunMigrationUnregisterer
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/helpers"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+)
+
+const (
+	// DefaultWaitTimeout is how long the wait command waits for the schema to be up to date if no timeout is given.
+	DefaultWaitTimeout = 5 * time.Minute
+	// DefaultWaitPollInterval is how often the wait command checks the migrations.
+	DefaultWaitPollInterval = 2 * time.Second
+)
+
+// ReadinessStatus describes whether the schema of the DB is up to date with the migrations directory.
+type ReadinessStatus struct {
+	PendingMigrations []models.Migration
+	FailedMigrations  []models.Migration
+}
+
+// IsReady returns true if every migration has been run and none of them has failed.
+func (status ReadinessStatus) IsReady() bool {
+	return len(status.PendingMigrations) == 0 && len(status.FailedMigrations) == 0
+}
+
+// Checker checks whether the schema of the DB is up to date (e.g. for readiness probes). It never runs migrations.
+type Checker interface {
+	Check() (ReadinessStatus, error)
+	// Wait checks the migrations every pollInterval until the schema is up to date or the context is done. The DB
+	// being unreachable is not an error until then (it may be starting). Each check that is not ready is notified to
+	// the progress.
+	Wait(ctx context.Context, pollInterval time.Duration, progress WaitProgress) (ReadinessStatus, error)
+}
+
+// WaitProgress is notified of each check of Checker.Wait that is not ready: its status or the error that prevented
+// the check (e.g. the DB is unreachable).
+type WaitProgress func(status ReadinessStatus, err error, elapsed time.Duration)
+
+type checkService struct {
+	migrationFetcherService         Fetcher
+	dbRepository                    repositories.DBRepository
+	migrationsDirectoryAbsolutePath string
+}
+
+// Ensure checkService implements Checker.
+var _ Checker = checkService{}
+
+// NewCheckService returns an implementation of Checker.
+func NewCheckService(
+	migrationFetcherService Fetcher,
+	dbRepository repositories.DBRepository,
+	migrationsDirectoryAbsolutePath string,
+) Checker {
+	return checkService{
+		migrationFetcherService:         migrationFetcherService,
+		dbRepository:                    dbRepository,
+		migrationsDirectoryAbsolutePath: helpers.AddTrailingSlashToPathIfNeeded(migrationsDirectoryAbsolutePath),
+	}
+}
+
+// Check returns the migrations that have not been run yet and the ones that have failed. It only creates the
+// migrations table if needed (so a fresh DB is reported as not ready instead of failing).
+func (service checkService) Check() (ReadinessStatus, error) {
+	err := service.dbRepository.Ping()
+	if err != nil {
+		return ReadinessStatus{}, NewDBConnectionError(errors.Wrap(err, "failed to connect to the DB"))
+	}
+
+	err = service.dbRepository.CreateMigrationsTableIfNeeded()
+	if err != nil {
+		return ReadinessStatus{}, NewBookkeepingError(err)
+	}
+
+	allMigrations, err := service.migrationFetcherService.GetMigrations(service.migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return ReadinessStatus{}, err
+	}
+
+	status := ReadinessStatus{PendingMigrations: allMigrations.GetMigrationsToRun()}
+	for _, migration := range allMigrations.GetAll() {
+		if migration.HasFailed() {
+			status.FailedMigrations = append(status.FailedMigrations, migration)
+		}
+	}
+
+	return status, nil
+}
+
+// Wait checks the migrations until the schema is up to date. It returns a PendingMigrationsError (or the last
+// DBConnectionError) if the context is done before.
+func (service checkService) Wait(
+	ctx context.Context,
+	pollInterval time.Duration,
+	progress WaitProgress,
+) (ReadinessStatus, error) {
+	startedAt := time.Now()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := service.Check()
+		if err == nil && status.IsReady() {
+			return status, nil
+		}
+
+		if err != nil && ExitCode(err) != ExitCodeDBUnavailable {
+			return status, err
+		}
+
+		if progress != nil {
+			progress(status, err, time.Since(startedAt))
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return status, err
+			}
+			return status, NewPendingMigrationsError(status)
+		case <-ticker.C:
+		}
+	}
+}
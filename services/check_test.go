@@ -0,0 +1,136 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func getCheckFetcher(test *testing.T, statuses ...int8) *mocks.Fetcher {
+	fetcher := &mocks.Fetcher{}
+	collection := models.Collection{}
+	for index, status := range statuses {
+		path := fmt.Sprintf("/tmp/%d_a.sql", index+1)
+		migration, err := models.NewMigration(path, "SELECT 1", status)
+		require.Nil(test, err)
+		require.Nil(test, collection.Add(migration))
+	}
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+
+	return fetcher
+}
+
+func TestCheckingMigrations(test *testing.T) {
+	test.Parallel()
+
+	fetcher := getCheckFetcher(test, models.StatusSuccessful, models.StatusNotRun)
+	defer fetcher.AssertExpectations(test)
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+
+	service := services.NewCheckService(fetcher, db, "/tmp")
+
+	status, err := service.Check()
+
+	require.Nil(test, err)
+	assert.False(test, status.IsReady())
+	require.Len(test, status.PendingMigrations, 1)
+	assert.Equal(test, "2_a.sql", status.PendingMigrations[0].GetName())
+	assert.Empty(test, status.FailedMigrations)
+}
+
+func TestCheckingMigrationsWhenEveryMigrationHasBeenRun(test *testing.T) {
+	test.Parallel()
+
+	fetcher := getCheckFetcher(test, models.StatusSuccessful, models.StatusSuccessful)
+	defer fetcher.AssertExpectations(test)
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+
+	service := services.NewCheckService(fetcher, db, "/tmp")
+
+	status, err := service.Check()
+
+	require.Nil(test, err)
+	assert.True(test, status.IsReady())
+}
+
+func TestWaitingUntilTheDBIsUpAndTheMigrationsHaveBeenRun(test *testing.T) {
+	test.Parallel()
+
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	pending := models.Collection{}
+	migration, err := models.NewMigration("/tmp/1_a.sql", "SELECT 1", models.StatusNotRun)
+	require.Nil(test, err)
+	require.Nil(test, pending.Add(migration))
+	fetcher.On("GetMigrations", "/tmp/").Return(pending, nil).Once()
+	fetcher.On("GetMigrations", "/tmp/").Return(models.Collection{}, nil).Once()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(fmt.Errorf("connection refused")).Once()
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+
+	service := services.NewCheckService(fetcher, db, "/tmp")
+	var errs []error
+	progress := func(status services.ReadinessStatus, err error, elapsed time.Duration) {
+		errs = append(errs, err)
+	}
+
+	status, err := service.Wait(context.Background(), time.Millisecond, progress)
+
+	require.Nil(test, err)
+	assert.True(test, status.IsReady())
+	require.Len(test, errs, 2)
+	assert.Equal(test, services.ExitCodeDBUnavailable, services.ExitCode(errs[0]))
+	assert.Nil(test, errs[1])
+}
+
+func TestWaitingStopsWhenTheContextIsDone(test *testing.T) {
+	test.Parallel()
+
+	fetcher := getCheckFetcher(test, models.StatusNotRun)
+	defer fetcher.AssertExpectations(test)
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+
+	service := services.NewCheckService(fetcher, db, "/tmp")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	status, err := service.Wait(ctx, time.Millisecond, nil)
+
+	assert.Equal(test, services.ExitCodeMigrationsPending, services.ExitCode(err))
+	assert.Len(test, status.PendingMigrations, 1)
+}
+
+func TestWaitingFailsRightAwayOnErrorsThatAreNotRetryable(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil).Once()
+	db.On("CreateMigrationsTableIfNeeded").Return(fmt.Errorf("permission denied")).Once()
+
+	service := services.NewCheckService(&mocks.Fetcher{}, db, "/tmp")
+
+	_, err := service.Wait(context.Background(), time.Hour, nil)
+
+	assert.Equal(test, services.ExitCodeBookkeepingFailed, services.ExitCode(err))
+}
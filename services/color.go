@@ -0,0 +1,113 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+)
+
+const (
+	// ColorModeAuto colors the output only when it's written to a terminal and NO_COLOR is not set.
+	ColorModeAuto = "auto"
+	// ColorModeAlways colors the output even if it's piped.
+	ColorModeAlways = "always"
+	// ColorModeNever never colors the output.
+	ColorModeNever = "never"
+)
+
+// EnvVarNoColor disables colors on ColorModeAuto (see https://no-color.org).
+const EnvVarNoColor = "NO_COLOR"
+
+var validColorModes = []string{ColorModeAuto, ColorModeAlways, ColorModeNever}
+
+const (
+	ansiReset  = "\033[0m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiCyan   = "\033[36m"
+)
+
+// coloredTags maps the tags printed by DisplayService to their colored version (more specific tags go first).
+var coloredTags = []struct {
+	tag   string
+	color string
+}{
+	{fmt.Sprintf("[%s] Not run:", informationalMessage), ansiYellow},
+	{fmt.Sprintf("[%s]", informationalMessage), ansiCyan},
+	{fmt.Sprintf("[%s]", successfulMigration), ansiGreen},
+	{fmt.Sprintf("[%s]", failedMigration), ansiRed},
+	{fmt.Sprintf("[%s]", warningMessage), ansiYellow},
+	{"[ERROR]", ansiRed},
+}
+
+// ColorPrinter is an adapters.Printer decorator that colors the tags printed by DisplayService.
+type ColorPrinter struct {
+	printer    adapters.Printer
+	mode       string
+	isTerminal func(writer io.Writer) bool
+	getEnvVar  func(key string) string
+}
+
+// Ensure ColorPrinter implements adapters.Printer.
+var _ adapters.Printer = ColorPrinter{}
+
+// NewColorPrinter returns a ColorPrinter decorating the given printer using a color mode (ColorMode* constants).
+func NewColorPrinter(printer adapters.Printer, mode string) ColorPrinter {
+	return ColorPrinter{
+		printer:    printer,
+		mode:       mode,
+		isTerminal: adapters.IsTerminal,
+		getEnvVar:  os.Getenv,
+	}
+}
+
+// WithEnvVars returns a copy of the ColorPrinter that reads NO_COLOR with the given function instead of os.Getenv.
+func (printer ColorPrinter) WithEnvVars(getEnvVar func(key string) string) ColorPrinter {
+	printer.getEnvVar = getEnvVar
+
+	return printer
+}
+
+// Print outputs a string given a format, coloring the tags if needed.
+func (printer ColorPrinter) Print(writer io.Writer, format string, a ...interface{}) error {
+	if !printer.shouldColor(writer) {
+		return printer.printer.Print(writer, format, a...)
+	}
+
+	return printer.printer.Print(writer, "%s", colorTags(fmt.Sprintf(format, a...)))
+}
+
+func (printer ColorPrinter) shouldColor(writer io.Writer) bool {
+	switch printer.mode {
+	case ColorModeAlways:
+		return true
+	case ColorModeNever:
+		return false
+	}
+
+	return printer.getEnvVar(EnvVarNoColor) == "" && printer.isTerminal(writer)
+}
+
+func colorTags(message string) string {
+	for _, coloredTag := range coloredTags {
+		if strings.Contains(message, coloredTag.tag) {
+			return strings.Replace(message, coloredTag.tag, coloredTag.color+coloredTag.tag+ansiReset, 1)
+		}
+	}
+
+	return message
+}
+
+func isColorModeValid(mode string) bool {
+	for _, validMode := range validColorModes {
+		if validMode == mode {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package services_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestColoringTagsWhenColorIsAlwaysOn(test *testing.T) {
+	test.Parallel()
+
+	output := &bytes.Buffer{}
+	printer := services.NewColorPrinter(adapters.PrinterAdapter{}, services.ColorModeAlways)
+
+	err := printer.Print(output, "\n[%s] %s", "  OK  ", "1_gophers.sql")
+	require.Nil(test, err)
+
+	assert.Equal(test, "\n\033[32m[  OK  ]\033[0m 1_gophers.sql", output.String())
+}
+
+func TestNotColoringTagsWhenColorIsOff(test *testing.T) {
+	test.Parallel()
+
+	output := &bytes.Buffer{}
+	printer := services.NewColorPrinter(adapters.PrinterAdapter{}, services.ColorModeNever)
+
+	err := printer.Print(output, "\n[%s] %s", " FAIL ", "oops")
+	require.Nil(test, err)
+
+	assert.Equal(test, "\n[ FAIL ] oops", output.String())
+}
+
+func TestNotColoringTagsWhenTheOutputIsNotATerminal(test *testing.T) {
+	test.Parallel()
+
+	output := &bytes.Buffer{}
+	printer := services.NewColorPrinter(adapters.PrinterAdapter{}, services.ColorModeAuto)
+
+	err := printer.Print(output, "\n[ERROR] %s\n", "oops")
+	require.Nil(test, err)
+
+	assert.Equal(test, "\n[ERROR] oops\n", output.String())
+}
+
+func TestColoringRunMigrations(test *testing.T) {
+	test.Parallel()
+
+	var result string
+	printer := services.NewColorPrinter(&printLogger{Log: &result}, services.ColorModeAlways)
+	service := services.NewDisplayService(printer)
+
+	migrations := models.Collection{}
+	migration1, err := models.NewMigration("/tmp/1_gophers.sql", "SELECT 1;", models.StatusSuccessful)
+	require.Nil(test, err)
+	require.Nil(test, migrations.Add(migration1))
+	migration2, err := models.NewMigration("/tmp/2_fusilli_jerry.sql", "SELECT 1;", models.StatusNotRun)
+	require.Nil(test, err)
+	require.Nil(test, migrations.Add(migration2.NewAsFailed(errors.New("oops"))))
+	migration3, err := models.NewMigration("/tmp/3_walrus.sql", "SELECT 1;", models.StatusNotRun)
+	require.Nil(test, err)
+	require.Nil(test, migrations.Add(migration3))
+
+	service.DisplayRunMigrations(migrations)
+
+	assert.Contains(test, result, "\033[36m[ INFO ]\033[0m Run migrations")
+	assert.Contains(test, result, "\033[32m[  OK  ]\033[0m 1_gophers.sql")
+	assert.Contains(test, result, "\033[31m[ FAIL ]\033[0m Migration /tmp/2_fusilli_jerry.sql failed")
+	assert.Contains(test, result, "\033[33m[ INFO ] Not run:\033[0m 3_walrus.sql")
+}
@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestTheDefaultRegistryDocumentsEveryBuiltInCommand(test *testing.T) {
+	test.Parallel()
+
+	registry := services.DefaultCommandRegistry()
+
+	assert.Equal(test, services.ValidCommands, registry.GetCommandNames())
+	assert.Empty(test, registry.GetCustomOptions())
+	for _, spec := range registry.GetCommands() {
+		assert.NotEmpty(test, spec.Summary, spec.Name)
+		assert.NotEmpty(test, spec.Examples, spec.Name)
+	}
+
+	create, ok := registry.GetCommand("create")
+	require.True(test, ok)
+	assert.True(test, create.IsOptionRequired("name"))
+	assert.True(test, create.IsOptionRequired("path"))
+	assert.False(test, create.IsOptionRequired("template"))
+
+	help, ok := registry.GetCommand("help")
+	require.True(test, ok)
+	assert.False(test, help.IsOptionRequired("path"))
+
+	option, ok := registry.GetOption("path")
+	require.True(test, ok)
+	assert.Equal(test, services.EnvVarMigrationsPath, option.EnvVar)
+}
+
+func TestRegisteringACommandWithAnUnknownOptionFails(test *testing.T) {
+	test.Parallel()
+
+	registry := services.NewCommandRegistry()
+
+	err := registry.RegisterCommand(services.CommandSpec{Name: "seed", Options: []string{"fixtures"}})
+
+	assert.EqualError(test, err, "the option [fixtures] of the command [seed] is not registered")
+	assert.Empty(test, registry.GetCommands())
+}
+
+func TestRegisteringACommandTwiceFails(test *testing.T) {
+	test.Parallel()
+
+	registry := services.NewCommandRegistry()
+	require.Nil(test, registry.RegisterOption(services.OptionSpec{Name: "fixtures"}, false))
+	require.Nil(test, registry.RegisterOption(services.OptionSpec{Name: "verbose"}, true))
+	require.Nil(test, registry.RegisterCommand(services.CommandSpec{Name: "seed", Options: []string{"fixtures"}}))
+
+	err := registry.RegisterCommand(services.CommandSpec{Name: "seed"})
+
+	assert.EqualError(test, err, "the command [seed] is already registered")
+	assert.EqualError(test,
+		registry.RegisterOption(services.OptionSpec{Name: "fixtures"}, false),
+		"the option [fixtures] is already registered",
+	)
+	assert.Equal(test, []string{"seed"}, registry.GetCommandNames())
+	assert.Equal(test, []services.OptionSpec{{Name: "verbose"}}, registry.GetGlobalOptions())
+	assert.Equal(test, []services.OptionSpec{{Name: "fixtures"}, {Name: "verbose"}}, registry.GetCustomOptions())
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+)
+
+// DefaultConfigFilePaths are the config files looked up on the working directory if no config file is given (the
+// first one that exists is used). JSON is parsed as YAML.
+var DefaultConfigFilePaths = []string{"migrations.yaml", "migrations.yml", "migrations.json"}
+
+// configEnvironmentsKey is the key of the per-environment sections of a config file, which override the top-level
+// values for the environment given by the 'env' option.
+const configEnvironmentsKey = "environments"
+
+// configKeys are the options that can be set on a config file, with a function that validates their values.
+var configKeys = map[string]func(value string) error{
+	"path":            nil,
+	"color":           validateConfigValue(isColorModeValid, validColorModes),
+	"out":             nil,
+	"lint-severity":   func(value string) error { _, err := ParseLintSeverities(value); return err },
+	"steps":           func(value string) error { _, err := parseSteps(value); return err },
+	"dev":             validateConfigBool,
+	"template":        nil,
+	"numbering":       validateConfigValue(isNumberingModeValid, validNumberingModes),
+	"padding":         validateConfigUint,
+	"name-style":      validateConfigValue(isNameStyleValid, validNameStyles),
+	"driver":          nil,
+	"dsn":             nil,
+	"schemas":         nil,
+	"concurrency":     validateConfigUint,
+	"timeout":         validateConfigDuration("timeout", false),
+	"connect-timeout": validateConfigDuration("connect-timeout", true),
+	"connect-backoff": validateConfigDuration("connect-backoff", false),
+}
+
+// configListKeys are the options that can be given as a list on a config file (their values are joined with commas).
+var configListKeys = map[string]bool{"path": true, "schemas": true}
+
+// Config holds the option values read from a config file. Command options and environment variables take precedence
+// over them (flag > env > file > default).
+type Config struct {
+	values map[string]string
+}
+
+// Get returns the value of an option, or the default value if it's not set on the config file.
+func (config Config) Get(key string, defaultValue string) string {
+	value, isSet := config.values[key]
+	if !isSet {
+		return defaultValue
+	}
+
+	return value
+}
+
+// LoadConfig reads a config file (YAML or JSON) and applies the section of the given environment, if any.
+// Without a config file path, the DefaultConfigFilePaths are looked up (an empty Config is returned if none exists).
+// Custom keys are the options of custom commands (see CommandRegistry), allowed besides the built-in ones.
+func LoadConfig(
+	fileSystem adapters.FileSystem,
+	configFilePath string,
+	environment string,
+	customKeys ...string,
+) (Config, error) {
+	content, configFilePath, err := readConfigFile(fileSystem, configFilePath)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if configFilePath == "" {
+		if environment != "" {
+			return Config{}, errors.Errorf("the environment [%s] needs a config file (see the 'config' option)",
+				environment)
+		}
+
+		return Config{values: map[string]string{}}, nil
+	}
+
+	root := &yaml.Node{}
+	err = yaml.Unmarshal(content, root)
+	if err != nil {
+		return Config{}, errors.Wrapf(err, "invalid config file [%s]", configFilePath)
+	}
+
+	config := Config{values: map[string]string{}}
+	if len(root.Content) == 0 {
+		return config, nil
+	}
+
+	parser := configParser{filePath: configFilePath, customKeys: customKeys}
+	environments, err := parser.parseSection(root.Content[0], "", config.values, true)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if environment == "" {
+		return config, nil
+	}
+
+	environmentNode, isDefined := environments[environment]
+	if !isDefined {
+		return Config{}, errors.Errorf("config file [%s]: the environment [%s] is not defined under [%s]",
+			configFilePath,
+			environment,
+			configEnvironmentsKey)
+	}
+
+	_, err = parser.parseSection(environmentNode, configEnvironmentsKey+"."+environment+".", config.values, false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+func readConfigFile(fileSystem adapters.FileSystem, configFilePath string) ([]byte, string, error) {
+	if configFilePath != "" {
+		content, err := fileSystem.ReadFile(configFilePath)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "could not read the config file [%s]", configFilePath)
+		}
+
+		return content, configFilePath, nil
+	}
+
+	for _, defaultConfigFilePath := range DefaultConfigFilePaths {
+		content, err := fileSystem.ReadFile(defaultConfigFilePath)
+		if err == nil {
+			return content, defaultConfigFilePath, nil
+		}
+	}
+
+	return nil, "", nil
+}
+
+type configParser struct {
+	filePath   string
+	customKeys []string
+}
+
+// parseSection adds the values of a mapping node to values, and returns the per-environment sections (only allowed
+// at the top level).
+func (parser configParser) parseSection(
+	node *yaml.Node,
+	keyPrefix string,
+	values map[string]string,
+	isTopLevel bool,
+) (map[string]*yaml.Node, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, parser.errorf(node, strings.TrimSuffix(keyPrefix, "."), "it must be a mapping of options")
+	}
+
+	environments := map[string]*yaml.Node{}
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		keyNode, valueNode := node.Content[index], node.Content[index+1]
+		key := keyNode.Value
+
+		if key == configEnvironmentsKey && isTopLevel {
+			if valueNode.Kind != yaml.MappingNode {
+				return nil, parser.errorf(valueNode, key, "it must be a mapping of environments")
+			}
+			for environmentIndex := 0; environmentIndex+1 < len(valueNode.Content); environmentIndex += 2 {
+				environments[valueNode.Content[environmentIndex].Value] = valueNode.Content[environmentIndex+1]
+			}
+			continue
+		}
+
+		validate, isKnown := configKeys[key]
+		if !isKnown && !containsString(parser.customKeys, key) {
+			return nil, parser.errorf(keyNode, keyPrefix+key, "unknown key")
+		}
+
+		if valueNode.Kind == yaml.SequenceNode && configListKeys[key] {
+			value, err := parser.joinSequence(valueNode, keyPrefix+key)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+			continue
+		}
+
+		if valueNode.Kind != yaml.ScalarNode {
+			return nil, parser.errorf(valueNode, keyPrefix+key, "the value must be a string, a number or a boolean")
+		}
+
+		if validate != nil {
+			err := validate(valueNode.Value)
+			if err != nil {
+				return nil, parser.errorf(valueNode, keyPrefix+key, err.Error())
+			}
+		}
+
+		values[key] = valueNode.Value
+	}
+
+	return environments, nil
+}
+
+// joinSequence joins the values of a sequence node of strings with commas.
+func (parser configParser) joinSequence(node *yaml.Node, key string) (string, error) {
+	values := make([]string, len(node.Content))
+	for index, valueNode := range node.Content {
+		if valueNode.Kind != yaml.ScalarNode {
+			return "", parser.errorf(valueNode, key, "the values of the list must be strings")
+		}
+		values[index] = valueNode.Value
+	}
+
+	return strings.Join(values, ","), nil
+}
+
+func (parser configParser) errorf(node *yaml.Node, key string, problem string) error {
+	if key == "" {
+		return errors.Errorf("config file [%s], line %d: %s", parser.filePath, node.Line, problem)
+	}
+
+	return errors.Errorf("config file [%s], line %d: invalid key [%s]: %s", parser.filePath, node.Line, key, problem)
+}
+
+func validateConfigValue(isValid func(value string) bool, validValues []string) func(value string) error {
+	return func(value string) error {
+		if !isValid(value) {
+			return errors.Errorf("invalid value [%s] (valid values: %s)", value, strings.Join(validValues, ", "))
+		}
+
+		return nil
+	}
+}
+
+func validateConfigDuration(optionName string, canBeZero bool) func(value string) error {
+	return func(value string) error {
+		_, err := parseDuration(value, optionName, canBeZero)
+
+		return err
+	}
+}
+
+func validateConfigBool(value string) error {
+	_, err := strconv.ParseBool(value)
+	if err != nil {
+		return errors.Errorf("invalid value [%s] (it must be true or false)", value)
+	}
+
+	return nil
+}
+
+func validateConfigUint(value string) error {
+	_, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return errors.Errorf("invalid value [%s] (it must be a positive number)", value)
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package services_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+const configFileContent = `
+path: /app/migrations
+color: never
+numbering: sequential
+environments:
+  production:
+    color: always
+    dev: true
+`
+
+func TestLoadingAConfigFile(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "migrations.yaml", configFileContent)
+
+	config, err := services.LoadConfig(adapters.IOUtilAdapter{}, filepath.Join(directory, "migrations.yaml"), "")
+
+	require.Nil(test, err)
+	assert.Equal(test, "/app/migrations", config.Get("path", ""))
+	assert.Equal(test, "never", config.Get("color", ""))
+	assert.Equal(test, "", config.Get("dev", ""))
+	assert.Equal(test, "camel", config.Get("name-style", "camel"))
+}
+
+func TestLoadingAConfigFileForAnEnvironment(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "migrations.yaml", configFileContent)
+
+	config, err := services.LoadConfig(adapters.IOUtilAdapter{}, filepath.Join(directory, "migrations.yaml"), "production")
+
+	require.Nil(test, err)
+	assert.Equal(test, "/app/migrations", config.Get("path", ""))
+	assert.Equal(test, "always", config.Get("color", ""))
+	assert.Equal(test, "true", config.Get("dev", ""))
+}
+
+func TestLoadingAJSONConfigFile(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "migrations.json", `{"path": "/app/migrations", "padding": 6}`)
+
+	config, err := services.LoadConfig(adapters.IOUtilAdapter{}, filepath.Join(directory, "migrations.json"), "")
+
+	require.Nil(test, err)
+	assert.Equal(test, "6", config.Get("padding", ""))
+}
+
+func TestLoadingAConfigFileWithSeveralMigrationsPaths(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "migrations.yaml", "path:\n  - /app/core\n  - /app/billing\n")
+
+	config, err := services.LoadConfig(adapters.IOUtilAdapter{}, filepath.Join(directory, "migrations.yaml"), "")
+
+	require.Nil(test, err)
+	assert.Equal(test, "/app/core,/app/billing", config.Get("path", ""))
+}
+
+func TestLoadingAConfigFileWithCustomKeys(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "migrations.yaml", "path: /app/migrations\nfixtures: /app/fixtures\n")
+	configPath := filepath.Join(directory, "migrations.yaml")
+
+	config, err := services.LoadConfig(adapters.IOUtilAdapter{}, configPath, "", "fixtures")
+
+	require.Nil(test, err)
+	assert.Equal(test, "/app/fixtures", config.Get("fixtures", ""))
+
+	_, err = services.LoadConfig(adapters.IOUtilAdapter{}, configPath, "")
+
+	assert.Contains(test, err.Error(), "invalid key [fixtures]: unknown key")
+}
+
+func TestLoadingAnInvalidConfigFilePointsToTheOffendingKey(test *testing.T) {
+	test.Parallel()
+
+	cases := map[string]string{
+		"path: /app\ncolr: never\n":                            "line 2: invalid key [colr]: unknown key",
+		"color: sometimes\n":                                   "line 1: invalid key [color]: invalid value [sometimes]",
+		"color:\n  - never\n":                                  "line 2: invalid key [color]: the value must be",
+		"path:\n  - [/app]\n":                                  "line 2: invalid key [path]: the values of the list",
+		"environments:\n  production:\n    padding: many\n":    "line 3: invalid key [environments.production.padding]",
+		"environments:\n  production:\n    environments: {}\n": "invalid key [environments.production.environments]",
+		"- path\n": "line 1: it must be a mapping",
+		"environments:\n  production:\n    color: sometimes\n\n": "line 3",
+	}
+
+	for content, expectedError := range cases {
+		directory := test.TempDir()
+		writeFile(test, directory, "migrations.yaml", content)
+
+		_, err := services.LoadConfig(adapters.IOUtilAdapter{}, filepath.Join(directory, "migrations.yaml"), "production")
+
+		require.NotNil(test, err, content)
+		assert.Contains(test, err.Error(), expectedError, content)
+	}
+}
+
+func TestTheConfirmationCannotBeSkippedOnAConfigFile(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "migrations.yaml", "path: /app/migrations\nyes: true\n")
+
+	_, err := services.LoadConfig(adapters.IOUtilAdapter{}, filepath.Join(directory, "migrations.yaml"), "")
+
+	require.NotNil(test, err)
+	assert.Contains(test, err.Error(), "invalid key [yes]")
+}
+
+func TestABoolOptionSetToFalseTakesPrecedenceOverTheConfigFile(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "migrations.yaml", configFileContent)
+	configPath := filepath.Join(directory, "migrations.yaml")
+	parse := func(rawArguments ...string) (services.Arguments, error) {
+		return services.NewCommandArgumentService(&mocks.Display{}, adapters.NewArgumentParser()).
+			WithEnvVars(func(key string) string { return "" }).
+			WithArguments(append([]string{"redo", "-config=" + configPath, "-env=production"}, rawArguments...)).
+			ParseAndValidateArguments()
+	}
+
+	args, err := parse()
+
+	require.Nil(test, err)
+	assert.True(test, args.Development)
+
+	args, err = parse("-dev=false")
+
+	require.Nil(test, err)
+	assert.False(test, args.Development)
+}
+
+func TestLoadingAConfigFileFailsIfTheEnvironmentIsNotDefined(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "migrations.yaml", configFileContent)
+
+	_, err := services.LoadConfig(adapters.IOUtilAdapter{}, filepath.Join(directory, "migrations.yaml"), "staging")
+
+	require.NotNil(test, err)
+	assert.Contains(test, err.Error(), "staging")
+}
+
+func TestLoadingAMissingConfigFile(test *testing.T) {
+	test.Parallel()
+
+	_, err := services.LoadConfig(adapters.IOUtilAdapter{}, filepath.Join(test.TempDir(), "migrations.yaml"), "")
+
+	assert.NotNil(test, err)
+}
+
+func TestOptionsTakePrecedenceOverTheConfigFile(test *testing.T) {
+	display := &mocks.Display{}
+	defer display.AssertExpectations(test)
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+
+	directory := test.TempDir()
+	writeFile(test, directory, "migrations.yaml", configFileContent)
+	os.Args = []string{"", "migrate"}
+	configPath := filepath.Join(directory, "migrations.yaml")
+	numbering := "timestamp"
+	err := os.Setenv(services.EnvVarColor, "auto")
+	require.Nil(test, err)
+	defer func() {
+		assert.Nil(test, os.Unsetenv(services.EnvVarColor))
+	}()
+
+	parser.On("OptionString", "config", mock.AnythingOfType("string")).
+		Return(&configPath)
+	parser.On("OptionString", "numbering", mock.AnythingOfType("string")).
+		Return(&numbering)
+	parser.On("PositionalArguments").
+		Return([]string{"migrate"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandArgumentService(display, parser)
+
+	args, err := service.ParseAndValidateArguments()
+
+	require.Nil(test, err)
+	assert.Equal(test, "/app/migrations/", args.MigrationsPath)
+	assert.Equal(test, "auto", args.Color)
+	assert.Equal(test, "timestamp", args.Numbering)
+}
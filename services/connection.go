@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/repositories"
+)
+
+const (
+	// DefaultConnectionInitialBackoff is the delay before the first retry to connect to the DB.
+	DefaultConnectionInitialBackoff = 500 * time.Millisecond
+	// DefaultConnectionMaxBackoff is the maximum delay between retries to connect to the DB.
+	DefaultConnectionMaxBackoff = 10 * time.Second
+)
+
+// ConnectionRetry configures the retries to connect to the DB (e.g. while it's starting). The delay between attempts
+// starts at InitialBackoff and doubles up to MaxBackoff, until Timeout has passed since the first attempt. A zero
+// Timeout disables the retries.
+type ConnectionRetry struct {
+	Timeout        time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// ConnectionAttempt is an attempt to connect to the DB. Err is nil if it succeeded.
+type ConnectionAttempt struct {
+	Number int
+	Err    error
+	// IsRetryable is false if the error is not a transient one (e.g. the credentials are wrong).
+	IsRetryable bool
+	// NextAttemptIn is the delay before the next attempt (zero if there are no more attempts).
+	NextAttemptIn time.Duration
+}
+
+// ConnectionProgress is notified of each failed attempt to connect to the DB, and of the successful one if it's not
+// the first.
+type ConnectionProgress func(attempt ConnectionAttempt)
+
+// IsRetryableConnectionError returns true if the error is a transient one that happens while the DB is starting
+// (connection refused or reset, unresolvable host, timeouts and broken connections). Anything else (e.g. wrong
+// credentials or an unknown database) is not retried.
+func IsRetryableConnectionError(err error) bool {
+	for _, transientError := range []error{
+		syscall.ECONNREFUSED,
+		syscall.ECONNRESET,
+		syscall.EHOSTUNREACH,
+		syscall.ENETUNREACH,
+		driver.ErrBadConn,
+		io.EOF,
+		io.ErrUnexpectedEOF,
+	} {
+		if errors.Is(err, transientError) {
+			return true
+		}
+	}
+
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) {
+		return true
+	}
+
+	var netError net.Error
+	return errors.As(err, &netError) && netError.Timeout()
+}
+
+// retryingDBRepository is a DBRepository that retries to connect to the DB (see ConnectionRetry).
+type retryingDBRepository struct {
+	repositories.DBRepository
+	ctx      context.Context
+	retry    ConnectionRetry
+	progress ConnectionProgress
+}
+
+// NewRetryingDBRepository returns a DBRepository whose Ping retries to connect to the DB with an exponential backoff,
+// notifying each attempt to the progress (if any). It stops retrying when the context is done.
+func NewRetryingDBRepository(
+	ctx context.Context,
+	dbRepository repositories.DBRepository,
+	retry ConnectionRetry,
+	progress ConnectionProgress,
+) repositories.DBRepository {
+	if retry.InitialBackoff <= 0 {
+		retry.InitialBackoff = DefaultConnectionInitialBackoff
+	}
+	if retry.MaxBackoff <= 0 {
+		retry.MaxBackoff = DefaultConnectionMaxBackoff
+	}
+	if progress == nil {
+		progress = func(attempt ConnectionAttempt) {}
+	}
+
+	return retryingDBRepository{
+		DBRepository: dbRepository,
+		ctx:          ctx,
+		retry:        retry,
+		progress:     progress,
+	}
+}
+
+// UnwrapDBRepository returns the DBRepository whose Ping is retried (see repositories.DBRepositoryWrapper).
+func (repository retryingDBRepository) UnwrapDBRepository() repositories.DBRepository {
+	return repository.DBRepository
+}
+
+// Ping pings the DB until it succeeds, the error is not retryable or the timeout has passed.
+func (repository retryingDBRepository) Ping() error {
+	deadline := time.Now().Add(repository.retry.Timeout)
+	backoff := repository.retry.InitialBackoff
+
+	for number := 1; ; number++ {
+		err := repository.DBRepository.Ping()
+		attempt := ConnectionAttempt{Number: number, Err: err, IsRetryable: IsRetryableConnectionError(err)}
+		if err == nil {
+			if number > 1 {
+				repository.progress(attempt)
+			}
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if !attempt.IsRetryable || remaining <= 0 || repository.ctx.Err() != nil {
+			repository.progress(attempt)
+			return err
+		}
+
+		attempt.NextAttemptIn = backoff
+		if attempt.NextAttemptIn > remaining {
+			attempt.NextAttemptIn = remaining
+		}
+		repository.progress(attempt)
+
+		timer := time.NewTimer(attempt.NextAttemptIn)
+		select {
+		case <-repository.ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > repository.retry.MaxBackoff {
+			backoff = repository.retry.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package services_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func getConnectionRefusedError() error {
+	return errors.Wrap(&net.OpError{
+		Op:  "dial",
+		Net: "tcp",
+		Err: os.NewSyscallError("connect", syscall.ECONNREFUSED),
+	}, "could not connect to the DB")
+}
+
+func TestDetectingRetryableConnectionErrors(test *testing.T) {
+	test.Parallel()
+
+	cases := map[string]struct {
+		err         error
+		isRetryable bool
+	}{
+		"connection refused": {getConnectionRefusedError(), true},
+		"unknown host":       {&net.DNSError{Err: "no such host", Name: "db", IsNotFound: true}, true},
+		"wrong credentials":  {errors.New("Error 1045 (28000): Access denied for user 'app'@'%'"), false},
+		"no error":           {nil, false},
+	}
+
+	for name, testCase := range cases {
+		assert.Equal(test, testCase.isRetryable, services.IsRetryableConnectionError(testCase.err), name)
+	}
+}
+
+func TestPingingRetriesUntilTheDBIsUp(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(getConnectionRefusedError()).Twice()
+	db.On("Ping").Return(nil).Once()
+
+	var attempts []services.ConnectionAttempt
+	retry := services.ConnectionRetry{Timeout: time.Minute, InitialBackoff: time.Millisecond}
+	progress := func(attempt services.ConnectionAttempt) {
+		attempts = append(attempts, attempt)
+	}
+	repository := services.NewRetryingDBRepository(context.Background(), db, retry, progress)
+
+	require.Nil(test, repository.Ping())
+	require.Len(test, attempts, 3)
+	assert.Equal(test, time.Millisecond, attempts[0].NextAttemptIn)
+	assert.Equal(test, 2*time.Millisecond, attempts[1].NextAttemptIn)
+	assert.Equal(test, 3, attempts[2].Number)
+	assert.Nil(test, attempts[2].Err)
+}
+
+func TestPingingDoesNotRetryErrorsThatAreNotTransient(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(errors.New("Error 1045 (28000): Access denied")).Once()
+
+	var attempts []services.ConnectionAttempt
+	retry := services.ConnectionRetry{Timeout: time.Minute, InitialBackoff: time.Millisecond}
+	progress := func(attempt services.ConnectionAttempt) {
+		attempts = append(attempts, attempt)
+	}
+	repository := services.NewRetryingDBRepository(context.Background(), db, retry, progress)
+
+	assert.NotNil(test, repository.Ping())
+	require.Len(test, attempts, 1)
+	assert.False(test, attempts[0].IsRetryable)
+}
+
+func TestPingingGivesUpAfterTheTimeout(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(getConnectionRefusedError())
+
+	var lastAttempt services.ConnectionAttempt
+	retry := services.ConnectionRetry{
+		Timeout:        20 * time.Millisecond,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	}
+	progress := func(attempt services.ConnectionAttempt) {
+		assert.LessOrEqual(test, attempt.NextAttemptIn, 5*time.Millisecond)
+		lastAttempt = attempt
+	}
+	repository := services.NewRetryingDBRepository(context.Background(), db, retry, progress)
+
+	err := repository.Ping()
+
+	assert.True(test, errors.Is(err, syscall.ECONNREFUSED))
+	assert.True(test, lastAttempt.IsRetryable)
+	assert.Zero(test, lastAttempt.NextAttemptIn)
+	assert.Greater(test, lastAttempt.Number, 1)
+}
+
+func TestRunningMigrationsRetriesToConnectToTheDB(test *testing.T) {
+	test.Parallel()
+
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	fetcher.On("GetMigrations", "/tmp/").Return(models.Collection{}, nil)
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(getConnectionRefusedError()).Once()
+	db.On("Ping").Return(nil).Once()
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+
+	retry := services.ConnectionRetry{Timeout: time.Minute, InitialBackoff: time.Millisecond}
+	service := services.NewRunnerService(
+		fetcher,
+		db,
+		"/tmp",
+		services.WithConnectionRetry(context.Background(), retry, nil),
+	)
+
+	_, err := service.RunMigrations()
+
+	assert.Nil(test, err)
+}
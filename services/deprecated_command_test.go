@@ -67,3 +67,23 @@ func TestParsingArgumentsWithEmptyCommand(test *testing.T) {
 
 	assert.Equal(test, "migrate", arguments.Command)
 }
+
+func TestParsingArgumentsWithEmptyPathWithAnExtendedParser(test *testing.T) {
+	path := ""
+	name := "name"
+	parser := &mocks.ExtendedArgumentParser{}
+	defer parser.AssertExpectations(test)
+	parser.On("OptionStrings", "path").
+		Return(&[]string{path})
+	parser.On("OptionString", "name", mock.AnythingOfType("string")).
+		Return(&name)
+	parser.On("PositionalArguments").
+		Return([]string{"command"})
+	parser.On("ParseArguments", mock.AnythingOfType("[]string")).Return(nil)
+	expectOtherOptions(parser)
+
+	service := services.NewCommandService(parser)
+	arguments := service.ParseArguments()
+
+	assert.Equal(test, "", arguments.MigrationsPath)
+}
@@ -2,7 +2,9 @@ package services
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/jimenezmaximiliano/migrations/adapters"
 	"github.com/jimenezmaximiliano/migrations/models"
@@ -21,25 +23,71 @@ type Display interface {
 	DisplayInfo(message string)
 }
 
+// ExtendedDisplay is a Display that also handles the output of the commands and options added after it. It's a
+// separate interface, so the implementations of Display keep compiling.
+type ExtendedDisplay interface {
+	Display
+	DisplayCommandHelp(spec CommandSpec)
+	DisplayMigrationStarted(migration models.Migration)
+	DisplayMigrationStillRunning(migration models.Migration, elapsed time.Duration)
+	DisplayValidationProblems(problems []ValidationProblem)
+	DisplayLintIssues(issues []LintIssue)
+	DisplayBaseline(migrations models.Collection)
+	DisplayManuallyMarkedMigration(migration models.Migration)
+	DisplayRepairPlan(plan RepairPlan)
+	DisplayRepaired(plan RepairPlan)
+	DisplayRedo(result RedoResult)
+	DisplaySquash(result SquashResult)
+	DisplayMultiRunResult(result MultiRunResult)
+	DisplayMigrationPreview(preview MigrationPreview)
+	DisplayReadiness(status ReadinessStatus)
+	DisplayWaiting(status ReadinessStatus, err error, elapsed time.Duration)
+	DisplayConnectionAttempt(attempt ConnectionAttempt)
+}
+
 type DisplayService struct {
-	printer adapters.Printer
+	printer  adapters.Printer
+	registry *CommandRegistry
+	stdout   io.Writer
+	stderr   io.Writer
 }
 
-// Ensure DisplayService implements Display.
+// Ensure DisplayService implements Display and ExtendedDisplay.
 var _ Display = DisplayService{}
+var _ ExtendedDisplay = DisplayService{}
 
-// NewDisplayService returns an implementation of Display.
+// NewDisplayService returns an implementation of Display and ExtendedDisplay.
 func NewDisplayService(printer adapters.Printer) DisplayService {
 	return DisplayService{
-		printer: printer,
+		printer:  printer,
+		registry: DefaultCommandRegistry(),
+		stdout:   os.Stdout,
+		stderr:   os.Stderr,
 	}
 }
 
+// WithWriters returns a copy of the DisplayService that writes to the given writers instead of os.Stdout and
+// os.Stderr (errors and failures go to stderr).
+func (service DisplayService) WithWriters(stdout io.Writer, stderr io.Writer) DisplayService {
+	service.stdout = stdout
+	service.stderr = stderr
+
+	return service
+}
+
+// WithCommandRegistry returns a copy of the DisplayService that documents the commands of the given registry.
+func (service DisplayService) WithCommandRegistry(registry *CommandRegistry) DisplayService {
+	service.registry = registry
+
+	return service
+}
+
 const (
 	messageFormat        = "\n[%s] %s"
 	informationalMessage = " INFO "
 	successfulMigration  = "  OK  "
 	failedMigration      = " FAIL "
+	warningMessage       = " WARN "
 )
 
 // DisplayRunMigrations outputs the results of run migrations.
@@ -48,13 +96,13 @@ func (service DisplayService) DisplayRunMigrations(migrations models.Collection)
 	if migrations.IsEmpty() {
 		service.info("No migrations to run")
 		service.info("Done")
-		service.printer.Print(os.Stdout, "\n\n")
+		service.printer.Print(service.stdout, "\n\n")
 		return
 	}
 	migrationProcessHasFailed := false
 	for _, migration := range migrations.GetAll() {
 		if migration.WasSuccessful() {
-			service.success(migration.GetName())
+			service.success(models.GetQualifiedName(migration))
 			continue
 		}
 
@@ -64,7 +112,7 @@ func (service DisplayService) DisplayRunMigrations(migrations models.Collection)
 			continue
 		}
 
-		service.info(fmt.Sprintf("Not run: %s", migration.GetName()))
+		service.info(fmt.Sprintf("Not run: %s", models.GetQualifiedName(migration)))
 	}
 
 	if migrationProcessHasFailed {
@@ -72,48 +120,379 @@ func (service DisplayService) DisplayRunMigrations(migrations models.Collection)
 	}
 
 	service.info("Done")
-	_ = service.printer.Print(os.Stdout, "\n\n")
+	_ = service.printer.Print(service.stdout, "\n\n")
+}
+
+// DisplayMigrationStarted outputs the name of a migration that is about to be run.
+func (service DisplayService) DisplayMigrationStarted(migration models.Migration) {
+	service.info(fmt.Sprintf("Running %s", models.GetQualifiedName(migration)))
+}
+
+// DisplayMigrationStillRunning outputs the elapsed time of a migration that is taking a while.
+func (service DisplayService) DisplayMigrationStillRunning(migration models.Migration, elapsed time.Duration) {
+	service.info(fmt.Sprintf("%s still running (%s)", models.GetQualifiedName(migration), elapsed.Round(time.Second)))
+}
+
+// DisplayValidationProblems outputs the problems found on the migrations directory, if any.
+func (service DisplayService) DisplayValidationProblems(problems []ValidationProblem) {
+	service.info("Validate migrations")
+	for _, problem := range problems {
+		service.failure(fmt.Sprintf("%s: %s", problem.FilePath, problem.Problem))
+	}
+
+	if len(problems) == 0 {
+		service.success("No problems found")
+	} else {
+		service.failure(fmt.Sprintf("%d problem(s) found", len(problems)))
+	}
+
+	service.info("Done")
+	_ = service.printer.Print(service.stdout, "\n\n")
+}
+
+// DisplayLintIssues outputs the lint issues found on the migrations, if any.
+func (service DisplayService) DisplayLintIssues(issues []LintIssue) {
+	service.info("Lint migrations")
+	for _, issue := range issues {
+		message := fmt.Sprintf("%s: [%s] %s", issue.FilePath, issue.Rule, issue.Message)
+		if issue.Severity == LintSeverityError {
+			service.failure(message)
+			continue
+		}
+		service.warning(message)
+	}
+
+	if len(issues) == 0 {
+		service.success("No issues found")
+	} else {
+		service.info(fmt.Sprintf("%d issue(s) found", len(issues)))
+	}
+
+	service.info("Done")
+	_ = service.printer.Print(service.stdout, "\n\n")
+}
+
+// DisplayBaseline outputs the migrations that have been registered as run without running them.
+func (service DisplayService) DisplayBaseline(migrations models.Collection) {
+	service.info("Baseline")
+	if migrations.IsEmpty() {
+		service.info("No migrations to mark as run")
+	}
+
+	for _, migration := range migrations.GetAll() {
+		service.success(fmt.Sprintf("Marked as run (not executed): %s", models.GetQualifiedName(migration)))
+	}
+
+	service.info("Done")
+	_ = service.printer.Print(service.stdout, "\n\n")
+}
+
+// DisplayManuallyMarkedMigration outputs a migration that has been registered (or unregistered) as run manually.
+func (service DisplayService) DisplayManuallyMarkedMigration(migration models.Migration) {
+	if migration.WasSuccessful() {
+		service.success(fmt.Sprintf("Manually marked as run (not executed): %s", models.GetQualifiedName(migration)))
+	} else {
+		service.success(fmt.Sprintf("Manually unmarked (it will be run again, nothing was reverted): %s",
+			models.GetQualifiedName(migration)))
+	}
+
+	_ = service.printer.Print(service.stdout, "\n\n")
+}
+
+// DisplayRepairPlan outputs the fixes needed for the migrations table and the inconsistencies that cannot be fixed.
+func (service DisplayService) DisplayRepairPlan(plan RepairPlan) {
+	service.info("Repair plan")
+	if plan.IsEmpty() {
+		service.success("The migrations table is consistent with the migrations directory")
+	}
+
+	for _, action := range plan.Actions {
+		service.info(action.Description)
+	}
+
+	for _, unresolved := range plan.Unresolved {
+		service.warning(fmt.Sprintf("%s (it must be fixed manually)", unresolved))
+	}
+
+	_ = service.printer.Print(service.stdout, "\n")
+}
+
+// DisplayRepaired outputs the fixes applied to the migrations table.
+func (service DisplayService) DisplayRepaired(plan RepairPlan) {
+	for _, action := range plan.Actions {
+		service.success(fmt.Sprintf("Repaired: %s", action.Description))
+	}
+
+	service.info("Done")
+	_ = service.printer.Print(service.stdout, "\n\n")
+}
+
+// DisplayRedo outputs the migrations reverted by the redo command and the result of running them again.
+func (service DisplayService) DisplayRedo(result RedoResult) {
+	service.info("Redo")
+	for _, migration := range result.Reverted.GetAll() {
+		service.info(fmt.Sprintf("Reverted: %s", models.GetQualifiedName(migration)))
+	}
+
+	for _, migration := range result.Reapplied.GetAll() {
+		if migration.HasFailed() {
+			service.failure(fmt.Sprintf("Migration %s failed with error [%s]",
+				migration.GetAbsolutePath(),
+				migration.GetError()))
+			continue
+		}
+		service.success(models.GetQualifiedName(migration))
+	}
+
+	service.info("Done")
+	_ = service.printer.Print(service.stdout, "\n\n")
+}
+
+// DisplaySquash outputs the squashed migration and the archived migrations.
+func (service DisplayService) DisplaySquash(result SquashResult) {
+	service.info("Squash")
+	for _, filePath := range result.SquashedFilePaths {
+		service.info(fmt.Sprintf("Archived: %s", filePath))
+	}
+
+	service.success(fmt.Sprintf("Created: %s (%d migration(s) squashed)",
+		result.FilePath,
+		len(result.SquashedFilePaths)))
+	service.info("Done")
+	_ = service.printer.Print(service.stdout, "\n\n")
+}
+
+// DisplayMultiRunResult outputs a summary of the migrations run on each target.
+func (service DisplayService) DisplayMultiRunResult(result MultiRunResult) {
+	service.info(fmt.Sprintf("Run migrations on %d target(s)", len(result.TargetNames)))
+	for _, name := range result.TargetNames {
+		targetResult := result.Results[name]
+		successful := countSuccessful(targetResult.Migrations)
+		if targetResult.Err != nil {
+			service.failure(fmt.Sprintf("%s: %d migration(s) run, failed with error [%s]",
+				name,
+				successful,
+				targetResult.Err))
+			continue
+		}
+
+		if successful == 0 {
+			service.success(fmt.Sprintf("%s: no migrations to run", name))
+			continue
+		}
+
+		service.success(fmt.Sprintf("%s: %d migration(s) run", name, successful))
+	}
+
+	failed := len(result.GetFailedTargetNames())
+	if failed > 0 {
+		service.failure(fmt.Sprintf("%d target(s) succeeded, %d failed", len(result.TargetNames)-failed, failed))
+	} else {
+		service.success(fmt.Sprintf("%d target(s) succeeded", len(result.TargetNames)))
+	}
+
+	service.info("Done")
+	_ = service.printer.Print(service.stdout, "\n\n")
+}
+
+// DisplayMigrationPreview outputs the DB the migrations are about to run on, how many are pending, their destructive
+// statements and whether the DB is protected.
+func (service DisplayService) DisplayMigrationPreview(preview MigrationPreview) {
+	service.info("Migration target")
+	service.info(fmt.Sprintf("Driver: %s", preview.Target.Driver))
+	if preview.Target.Host != "" {
+		service.info(fmt.Sprintf("Host: %s", preview.Target.Host))
+	}
+	service.info(fmt.Sprintf("Database: %s", preview.Target.Database))
+	if preview.IsProtected() {
+		service.warning(fmt.Sprintf("The DB is protected as [%s]", preview.ProtectedEnvironment))
+	}
+
+	service.info(fmt.Sprintf("Migrations to run: %d", len(preview.PendingMigrations)))
+	for _, migration := range preview.PendingMigrations {
+		service.info(fmt.Sprintf("Pending: %s", models.GetQualifiedName(migration)))
+	}
+
+	for _, statement := range preview.DestructiveStatements {
+		service.warning(fmt.Sprintf("%s: [%s] %s", statement.FilePath, statement.Rule, statement.Message))
+	}
+
+	_ = service.printer.Print(service.stdout, "\n")
+}
+
+// DisplayReadiness outputs whether the schema is up to date, with the migrations that are pending or failed.
+func (service DisplayService) DisplayReadiness(status ReadinessStatus) {
+	service.info("Check migrations")
+	for _, migration := range status.FailedMigrations {
+		service.failure(fmt.Sprintf("Failed: %s", models.GetQualifiedName(migration)))
+	}
+
+	for _, migration := range status.PendingMigrations {
+		service.warning(fmt.Sprintf("Pending: %s", models.GetQualifiedName(migration)))
+	}
+
+	if status.IsReady() {
+		service.success("The schema is up to date")
+	} else {
+		service.failure(fmt.Sprintf("The schema is not up to date (%d migration(s) pending, %d failed)",
+			len(status.PendingMigrations),
+			len(status.FailedMigrations)))
+	}
+
+	service.info("Done")
+	_ = service.printer.Print(service.stdout, "\n\n")
+}
+
+// DisplayWaiting outputs a check of the wait command that is not ready: the number of pending migrations or the error
+// that prevented the check.
+func (service DisplayService) DisplayWaiting(status ReadinessStatus, err error, elapsed time.Duration) {
+	if err != nil {
+		service.info(fmt.Sprintf("Waiting for the DB (%s): %s", elapsed.Round(time.Second), err))
+		return
+	}
+
+	service.info(fmt.Sprintf("Waiting for %d migration(s) (%s)",
+		len(status.PendingMigrations)+len(status.FailedMigrations),
+		elapsed.Round(time.Second)))
+}
+
+// DisplayConnectionAttempt outputs an attempt to connect to the DB: whether it succeeded, will be retried or has
+// given up.
+func (service DisplayService) DisplayConnectionAttempt(attempt ConnectionAttempt) {
+	if attempt.Err == nil {
+		service.success(fmt.Sprintf("Connected to the DB (attempt %d)", attempt.Number))
+		return
+	}
+
+	message := fmt.Sprintf("Could not connect to the DB (attempt %d): %s", attempt.Number, attempt.Err)
+	switch {
+	case !attempt.IsRetryable:
+		service.failure(message + " (not retried, e.g. wrong credentials)")
+	case attempt.NextAttemptIn > 0:
+		service.warning(fmt.Sprintf("%s (retrying in %s)", message, attempt.NextAttemptIn.Round(time.Millisecond)))
+	default:
+		service.failure(message + " (giving up)")
+	}
+}
+
+func countSuccessful(migrations models.Collection) int {
+	successful := 0
+	for _, migration := range migrations.GetAll() {
+		if migration.WasSuccessful() {
+			successful++
+		}
+	}
+
+	return successful
 }
 
 func (service DisplayService) DisplayError(err error) {
-	_ = service.printer.Print(os.Stderr, "\n[ERROR] %s\n", err)
+	_ = service.printer.Print(service.stderr, "\n[ERROR] %s\n", err)
 }
 
 func (service DisplayService) DisplayErrorWithMessage(err error, message string) {
-	_ = service.printer.Print(os.Stderr, "\n[ERROR] %s: %s\n", message, err)
+	_ = service.printer.Print(service.stderr, "\n[ERROR] %s: %s\n", message, err)
 }
 
 func (service DisplayService) info(message string) {
-	_ = service.printer.Print(os.Stdout, messageFormat, informationalMessage, message)
+	_ = service.printer.Print(service.stdout, messageFormat, informationalMessage, message)
 }
 
 func (service DisplayService) success(message string) {
-	_ = service.printer.Print(os.Stdout, messageFormat, successfulMigration, message)
+	_ = service.printer.Print(service.stdout, messageFormat, successfulMigration, message)
 }
 
 func (service DisplayService) failure(message string) {
-	_ = service.printer.Print(os.Stderr, messageFormat, failedMigration, message)
+	_ = service.printer.Print(service.stderr, messageFormat, failedMigration, message)
+}
+
+func (service DisplayService) warning(message string) {
+	_ = service.printer.Print(service.stdout, messageFormat, warningMessage, message)
 }
 
 func (service DisplayService) DisplayInfo(message string) {
-	_ = service.printer.Print(os.Stdout, "\n%s\n\n", message)
+	_ = service.printer.Print(service.stdout, "\n%s\n\n", message)
 }
 
+// DisplayHelp outputs the usage and the available commands (see DisplayCommandHelp for the help of a command).
 func (service DisplayService) DisplayHelp() {
-	_ = service.printer.Print(os.Stdout, "\nUsage:\n")
-	_ = service.printer.Print(os.Stdout, "\t[executable] [command] [-options]\n\n")
-	_ = service.printer.Print(os.Stdout, "\tExamples:\n\n")
-	_ = service.printer.Print(os.Stdout, "\t\tgo run main.go migrate -path=/path/to/migrations/directory/\n")
-	_ = service.printer.Print(os.Stdout, "\t\t./myMigrationBinary migrate -path=/path/to/migrations/directory/\n\n")
-	_ = service.printer.Print(os.Stdout, "Available commands:\n\n")
-	_ = service.printer.Print(os.Stdout, "\tmigrate [-path]\n")
-	_ = service.printer.Print(os.Stdout, "\t./migrate migrate -path=/path/to/migrations/directory/\n\n")
-	_ = service.printer.Print(os.Stdout, "\tcreate [-name] [-path]\n")
+	_ = service.printer.Print(service.stdout, "\nUsage:\n")
+	_ = service.printer.Print(service.stdout, "\t[executable] [command] [-options]\n\n")
+	_ = service.printer.Print(service.stdout, "\tExamples:\n\n")
+	_ = service.printer.Print(service.stdout, "\t\tgo run main.go migrate -path=/path/to/migrations/directory/\n")
+	_ = service.printer.Print(service.stdout, "\t\t./myMigrationBinary migrate -path=/path/to/migrations/directory/\n\n")
+
+	_ = service.printer.Print(service.stdout, "Available commands:\n\n")
+	commands := service.registry.GetCommands()
+	width := 0
+	for _, spec := range commands {
+		width = maxInt(width, len(spec.Name))
+	}
+	for _, spec := range commands {
+		_ = service.printer.Print(service.stdout, "\t%-*s  %s\n", width, spec.Name, spec.Summary)
+	}
+	_ = service.printer.Print(service.stdout, "\n")
+
+	service.displayOptions("Global options", service.registry.GetGlobalOptions(), nil)
 	_ = service.printer.Print(
-		os.Stdout,
-		"\t./migrate create -path=/path/to/migrations/directory/ -name=createTableGophers\n\n",
+		service.stdout,
+		"Run [executable] help [command] to see the options and examples of a command.\n",
 	)
-	_ = service.printer.Print(os.Stdout, "\nDocumentation: https://github.com/jimenezmaximiliano/migrations\n\n")
+	_ = service.printer.Print(service.stdout, "\nDocumentation: https://github.com/jimenezmaximiliano/migrations\n\n")
+}
+
+// DisplayCommandHelp outputs the usage, options (with their environment variables) and examples of a command.
+func (service DisplayService) DisplayCommandHelp(spec CommandSpec) {
+	_ = service.printer.Print(service.stdout, "\nUsage:\n")
+	_ = service.printer.Print(service.stdout, "\t[executable] %s [-options]\n\n", spec.Name)
+	_ = service.printer.Print(service.stdout, "\t%s\n\n", spec.Summary)
+
+	var options []OptionSpec
+	for _, name := range append(append([]string{}, spec.RequiredOptions...), spec.Options...) {
+		option, _ := service.registry.GetOption(name)
+		option.Name = name
+		options = append(options, option)
+	}
+	if len(options) > 0 {
+		service.displayOptions("Options", options, spec.RequiredOptions)
+	}
+	service.displayOptions("Global options", service.registry.GetGlobalOptions(), nil)
+
+	if len(spec.Examples) > 0 {
+		_ = service.printer.Print(service.stdout, "Examples:\n\n")
+		for _, example := range spec.Examples {
+			_ = service.printer.Print(service.stdout, "\t[executable] %s\n", example)
+		}
+		_ = service.printer.Print(service.stdout, "\n")
+	}
+}
+
+// displayOptions outputs a list of options with their descriptions and environment variables.
+func (service DisplayService) displayOptions(title string, options []OptionSpec, requiredOptions []string) {
+	_ = service.printer.Print(service.stdout, "%s:\n\n", title)
+	width := 0
+	for _, option := range options {
+		width = maxInt(width, len(option.Name)+1)
+	}
+	for _, option := range options {
+		description := option.Description
+		if containsString(requiredOptions, option.Name) {
+			description = "(required) " + description
+		}
+		if option.EnvVar != "" {
+			description = fmt.Sprintf("%s [%s]", description, option.EnvVar)
+		}
+		_ = service.printer.Print(service.stdout, "\t%-*s  %s\n", width, "-"+option.Name, description)
+	}
+	_ = service.printer.Print(service.stdout, "\n")
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
 }
 
 // Deprecated: use DisplayError instead
@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
 	"github.com/jimenezmaximiliano/migrations/services"
 )
 
@@ -56,6 +58,28 @@ func TestDisplayHelp(test *testing.T) {
 	assert.Contains(test, result, "Usage")
 	assert.Contains(test, result, "Examples")
 	assert.Contains(test, result, "Documentation")
+	assert.Contains(test, result, "mark-applied")
+	assert.Contains(test, result, services.EnvVarConfigPath)
+	assert.Contains(test, result, "help [command]")
+}
+
+func TestDisplayCommandHelp(test *testing.T) {
+	test.Parallel()
+
+	var result string
+	printer := &printLogger{
+		Log: &result,
+	}
+	service := services.NewDisplayService(printer)
+	spec, ok := services.DefaultCommandRegistry().GetCommand("baseline")
+	require.True(test, ok)
+
+	service.DisplayCommandHelp(spec)
+
+	assert.Contains(test, result, "[executable] baseline [-options]")
+	assert.Contains(test, result, "(required) order of the last migration to register")
+	assert.Contains(test, result, services.EnvVarBaselineOrder)
+	assert.Contains(test, result, "[executable] baseline -path=/app/migrations/ -to=1627676757857350000")
 }
 
 func TestDisplaySetupError(test *testing.T) {
@@ -136,6 +160,86 @@ func TestDisplayingRunMigrationsWithMigrations(test *testing.T) {
 	assert.Contains(test, result, "3_walrus.sql")
 }
 
+func TestDisplayingMigrationProgress(test *testing.T) {
+	test.Parallel()
+
+	var result string
+	printer := &printLogger{
+		Log: &result,
+	}
+	service := services.NewDisplayService(printer)
+
+	migration, err := models.NewMigration("/tmp/1_gophers.sql", "SELECT 1;", models.StatusNotRun)
+	require.Nil(test, err)
+
+	service.DisplayMigrationStarted(migration)
+	service.DisplayMigrationStillRunning(migration, 2*time.Minute+10*time.Second+300*time.Millisecond)
+
+	assert.Contains(test, result, "Running 1_gophers.sql")
+	assert.Contains(test, result, "1_gophers.sql still running (2m10s)")
+}
+
+func TestDisplayingValidationProblems(test *testing.T) {
+	test.Parallel()
+
+	var result string
+	printer := &printLogger{
+		Log: &result,
+	}
+	service := services.NewDisplayService(printer)
+
+	service.DisplayValidationProblems([]services.ValidationProblem{
+		{FilePath: "/tmp/2_empty.sql", Problem: "the migration is empty"},
+	})
+
+	assert.Contains(test, result, "[ FAIL ] /tmp/2_empty.sql: the migration is empty")
+	assert.Contains(test, result, "1 problem(s) found")
+}
+
+func TestDisplayingNoValidationProblems(test *testing.T) {
+	test.Parallel()
+
+	var result string
+	printer := &printLogger{
+		Log: &result,
+	}
+	service := services.NewDisplayService(printer)
+
+	service.DisplayValidationProblems(nil)
+
+	assert.Contains(test, result, "No problems found")
+}
+
+func TestDisplayingAMultiRunResult(test *testing.T) {
+	test.Parallel()
+
+	var result string
+	printer := &printLogger{
+		Log: &result,
+	}
+	service := services.NewDisplayService(printer)
+
+	migrations := models.Collection{}
+	migration, err := models.NewMigration("/tmp/1_gophers.sql", "SELECT 1;", models.StatusSuccessful)
+	require.Nil(test, err)
+	require.Nil(test, migrations.Add(migration))
+
+	service.DisplayMultiRunResult(services.MultiRunResult{
+		TargetNames: []string{"tenant_a", "tenant_b", "tenant_c"},
+		Results: map[string]services.TargetResult{
+			"tenant_a": {Migrations: migrations},
+			"tenant_b": {},
+			"tenant_c": {Err: errors.New("could not connect")},
+		},
+	})
+
+	assert.Contains(test, result, "Run migrations on 3 target(s)")
+	assert.Contains(test, result, "tenant_a: 1 migration(s) run")
+	assert.Contains(test, result, "tenant_b: no migrations to run")
+	assert.Contains(test, result, "[ FAIL ] tenant_c: 0 migration(s) run, failed with error [could not connect]")
+	assert.Contains(test, result, "2 target(s) succeeded, 1 failed")
+}
+
 type printLogger struct {
 	Log *string
 }
@@ -145,3 +249,51 @@ func (logger printLogger) Print(writer io.Writer, format string, a ...interface{
 
 	return nil
 }
+
+func TestDisplayingTheMigrationPreview(test *testing.T) {
+	test.Parallel()
+
+	var result string
+	printer := &printLogger{
+		Log: &result,
+	}
+	service := services.NewDisplayService(printer)
+	migration, err := models.NewMigration("/tmp/1_dropGophers.sql", "DROP TABLE gophers", models.StatusNotRun)
+	require.Nil(test, err)
+
+	service.DisplayMigrationPreview(services.MigrationPreview{
+		Target:            repositories.DBTarget{Driver: "mysql", Host: "db:3306", Database: "app"},
+		PendingMigrations: []models.Migration{migration},
+		DestructiveStatements: []services.LintIssue{
+			{FilePath: "/tmp/1_dropGophers.sql", Rule: "drop-table", Message: "dropping a table loses its data"},
+		},
+		ProtectedEnvironment: "production",
+	})
+
+	assert.Contains(test, result, "Host: db:3306")
+	assert.Contains(test, result, "Database: app")
+	assert.Contains(test, result, "protected as [production]")
+	assert.Contains(test, result, "Migrations to run: 1")
+	assert.Contains(test, result, "[drop-table] dropping a table loses its data")
+}
+
+func TestDisplayingConnectionAttempts(test *testing.T) {
+	test.Parallel()
+
+	var result string
+	printer := &printLogger{
+		Log: &result,
+	}
+	service := services.NewDisplayService(printer)
+
+	service.DisplayConnectionAttempt(services.ConnectionAttempt{
+		Number:        1,
+		Err:           errors.New("connection refused"),
+		IsRetryable:   true,
+		NextAttemptIn: 2 * time.Second,
+	})
+	service.DisplayConnectionAttempt(services.ConnectionAttempt{Number: 2, Err: errors.New("access denied")})
+
+	assert.Contains(test, result, "Could not connect to the DB (attempt 1): connection refused (retrying in 2s)")
+	assert.Contains(test, result, "Could not connect to the DB (attempt 2): access denied (not retried")
+}
@@ -0,0 +1,59 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestResolvingDataSources(test *testing.T) {
+	test.Parallel()
+
+	cases := []struct {
+		driver         string
+		dsn            string
+		driverName     string
+		dataSourceName string
+	}{
+		{"", "user:password@/db", "mysql", "user:password@/db"},
+		{"mysql", "user:password@tcp(db:3306)/db", "mysql", "user:password@tcp(db:3306)/db"},
+		{"", "mysql://user:password@db:3306/db", "mysql", "user:password@tcp(db:3306)/db?multiStatements=true"},
+		{"mysql", "mysql://user@db/db?multiStatements=false", "mysql", "user@tcp(db)/db?multiStatements=false"},
+		{"sqlite", "/tmp/db.sqlite", "sqlite", "/tmp/db.sqlite"},
+		{"sqlite3", "file:db.sqlite?_pragma=foreign_keys(1)", "sqlite", "file:db.sqlite?_pragma=foreign_keys(1)"},
+		{"", "sqlite:///tmp/db.sqlite", "sqlite", "/tmp/db.sqlite"},
+		{"SQLite", "sqlite://db.sqlite", "sqlite", "db.sqlite"},
+	}
+
+	for _, testCase := range cases {
+		driverName, dataSourceName, err := services.ResolveDataSource(testCase.driver, testCase.dsn)
+
+		require.Nil(test, err, testCase.dsn)
+		assert.Equal(test, testCase.driverName, driverName, testCase.dsn)
+		assert.Equal(test, testCase.dataSourceName, dataSourceName, testCase.dsn)
+	}
+}
+
+func TestResolvingInvalidDataSources(test *testing.T) {
+	test.Parallel()
+
+	cases := []struct {
+		driver string
+		dsn    string
+	}{
+		{"mysql", ""},
+		{"postgres", "postgres://user@db/db"},
+		{"", "postgres://user@db/db"},
+		{"sqlite", "mysql://user@db/db"},
+		{"", "mysql://user:pass@db:port/db"},
+	}
+
+	for _, testCase := range cases {
+		_, _, err := services.ResolveDataSource(testCase.driver, testCase.dsn)
+
+		assert.NotNil(test, err, testCase.dsn)
+	}
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/models"
+)
+
+// Exit codes used by the migrations command, one per failure category.
+const (
+	// ExitCodeSuccess means the command finished without errors.
+	ExitCodeSuccess = 0
+	// ExitCodeUnknownError means the command failed for a reason without a specific category.
+	ExitCodeUnknownError = 1
+	// ExitCodeInvalidArguments means the command line arguments are invalid (fatal).
+	ExitCodeInvalidArguments = 2
+	// ExitCodeDBSetupFailed means the DB connection could not be configured (fatal).
+	ExitCodeDBSetupFailed = 3
+	// ExitCodeDBUnavailable means the DB could not be reached (retryable).
+	ExitCodeDBUnavailable = 4
+	// ExitCodeMigrationFailed means a migration query failed (fatal).
+	ExitCodeMigrationFailed = 5
+	// ExitCodeBookkeepingFailed means the migrations table could not be created or updated.
+	ExitCodeBookkeepingFailed = 6
+	// ExitCodeValidationFailed means problems were found on the migrations directory (fatal).
+	ExitCodeValidationFailed = 7
+	// ExitCodeLintFailed means the lint command found issues with an error severity (fatal).
+	ExitCodeLintFailed = 8
+	// ExitCodeMigrationsPending means the check and wait commands found migrations that have not been run (retryable).
+	ExitCodeMigrationsPending = 9
+	// ExitCodeNotConfirmed means the user declined the confirmation, so nothing was done (fatal).
+	ExitCodeNotConfirmed = 10
+)
+
+// ArgumentsError is returned when the command line arguments are invalid.
+type ArgumentsError struct {
+	err error
+}
+
+// NewArgumentsError wraps an error as an ArgumentsError.
+func NewArgumentsError(err error) error {
+	return ArgumentsError{err: err}
+}
+
+func (thisError ArgumentsError) Error() string {
+	return thisError.err.Error()
+}
+
+func (thisError ArgumentsError) Unwrap() error {
+	return thisError.err
+}
+
+// DBSetupError is returned when the DB connection could not be configured.
+type DBSetupError struct {
+	err error
+}
+
+// NewDBSetupError wraps an error as a DBSetupError.
+func NewDBSetupError(err error) error {
+	return DBSetupError{err: err}
+}
+
+func (thisError DBSetupError) Error() string {
+	return thisError.err.Error()
+}
+
+func (thisError DBSetupError) Unwrap() error {
+	return thisError.err
+}
+
+// DBConnectionError is returned when the DB could not be reached.
+type DBConnectionError struct {
+	err error
+}
+
+// NewDBConnectionError wraps an error as a DBConnectionError.
+func NewDBConnectionError(err error) error {
+	return DBConnectionError{err: err}
+}
+
+func (thisError DBConnectionError) Error() string {
+	return thisError.err.Error()
+}
+
+func (thisError DBConnectionError) Unwrap() error {
+	return thisError.err
+}
+
+// MigrationError is returned when a migration query failed.
+type MigrationError struct {
+	migration models.Migration
+}
+
+// NewMigrationError returns a MigrationError for the given failed migration.
+func NewMigrationError(migration models.Migration) error {
+	return MigrationError{migration: migration}
+}
+
+func (thisError MigrationError) Error() string {
+	if thisError.migration.GetError() == nil {
+		return errors.Errorf("migration %s failed", models.GetQualifiedName(thisError.migration)).Error()
+	}
+
+	return errors.Wrapf(
+		thisError.migration.GetError(),
+		"migration %s failed",
+		models.GetQualifiedName(thisError.migration),
+	).Error()
+}
+
+func (thisError MigrationError) Unwrap() error {
+	return thisError.migration.GetError()
+}
+
+// GetMigration returns the migration that failed.
+func (thisError MigrationError) GetMigration() models.Migration {
+	return thisError.migration
+}
+
+// BookkeepingError is returned when the migrations table could not be created or updated.
+type BookkeepingError struct {
+	err error
+}
+
+// NewBookkeepingError wraps an error as a BookkeepingError.
+func NewBookkeepingError(err error) error {
+	return BookkeepingError{err: err}
+}
+
+func (thisError BookkeepingError) Error() string {
+	return thisError.err.Error()
+}
+
+func (thisError BookkeepingError) Unwrap() error {
+	return thisError.err
+}
+
+// ValidationError is returned when problems were found on the migrations directory.
+type ValidationError struct {
+	problems []ValidationProblem
+}
+
+// NewValidationError returns a ValidationError for the given problems.
+func NewValidationError(problems []ValidationProblem) error {
+	return ValidationError{problems: problems}
+}
+
+func (thisError ValidationError) Error() string {
+	return fmt.Sprintf("%d problem(s) found on the migrations directory", len(thisError.problems))
+}
+
+// GetProblems returns the problems found on the migrations directory.
+func (thisError ValidationError) GetProblems() []ValidationProblem {
+	return thisError.problems
+}
+
+// LintError is returned when lint issues with an error severity were found.
+type LintError struct {
+	issues []LintIssue
+}
+
+// NewLintError returns a LintError for the given issues.
+func NewLintError(issues []LintIssue) error {
+	return LintError{issues: issues}
+}
+
+func (thisError LintError) Error() string {
+	return fmt.Sprintf("%d lint issue(s) found", len(thisError.issues))
+}
+
+// GetIssues returns the lint issues.
+func (thisError LintError) GetIssues() []LintIssue {
+	return thisError.issues
+}
+
+// NotConfirmedError is returned when the user declines the confirmation of a command.
+type NotConfirmedError struct {
+	err error
+}
+
+// NewNotConfirmedError wraps an error as a NotConfirmedError.
+func NewNotConfirmedError(err error) error {
+	return NotConfirmedError{err: err}
+}
+
+func (thisError NotConfirmedError) Error() string {
+	return thisError.err.Error()
+}
+
+func (thisError NotConfirmedError) Unwrap() error {
+	return thisError.err
+}
+
+// PendingMigrationsError is returned when the schema is not up to date: some migrations have not been run (or failed).
+type PendingMigrationsError struct {
+	status ReadinessStatus
+}
+
+// NewPendingMigrationsError returns a PendingMigrationsError for the given status.
+func NewPendingMigrationsError(status ReadinessStatus) error {
+	return PendingMigrationsError{status: status}
+}
+
+func (thisError PendingMigrationsError) Error() string {
+	return fmt.Sprintf("the schema is not up to date (%d migration(s) pending, %d failed)",
+		len(thisError.status.PendingMigrations),
+		len(thisError.status.FailedMigrations))
+}
+
+// GetStatus returns the status of the migrations.
+func (thisError PendingMigrationsError) GetStatus() ReadinessStatus {
+	return thisError.status
+}
+
+// ErrorFromRunMigrations returns a MigrationError if any of the given run migrations has failed.
+func ErrorFromRunMigrations(migrations models.Collection) error {
+	for _, migration := range migrations.GetAll() {
+		if migration.HasFailed() {
+			return NewMigrationError(migration)
+		}
+	}
+
+	return nil
+}
+
+// ExitCode returns the exit code that corresponds to the category of the given error.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+
+	switch {
+	case errors.As(err, &ArgumentsError{}):
+		return ExitCodeInvalidArguments
+	case errors.As(err, &DBSetupError{}):
+		return ExitCodeDBSetupFailed
+	case errors.As(err, &DBConnectionError{}):
+		return ExitCodeDBUnavailable
+	case errors.As(err, &MigrationError{}):
+		return ExitCodeMigrationFailed
+	case errors.As(err, &BookkeepingError{}):
+		return ExitCodeBookkeepingFailed
+	case errors.As(err, &ValidationError{}):
+		return ExitCodeValidationFailed
+	case errors.As(err, &LintError{}):
+		return ExitCodeLintFailed
+	case errors.As(err, &PendingMigrationsError{}):
+		return ExitCodeMigrationsPending
+	case errors.As(err, &NotConfirmedError{}):
+		return ExitCodeNotConfirmed
+	}
+
+	return ExitCodeUnknownError
+}
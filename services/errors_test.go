@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestExitCodes(test *testing.T) {
+	test.Parallel()
+
+	failedMigration, err := models.NewMigration("/tmp/1_a.sql", "SELECT 1", models.StatusNotRun)
+	require.Nil(test, err)
+	failedMigration = failedMigration.NewAsFailed(errors.New("syntax error"))
+
+	cases := map[string]struct {
+		err      error
+		exitCode int
+	}{
+		"no error":          {nil, services.ExitCodeSuccess},
+		"unknown error":     {errors.New("oops"), services.ExitCodeUnknownError},
+		"invalid arguments": {services.NewArgumentsError(errors.New("oops")), services.ExitCodeInvalidArguments},
+		"DB setup":          {services.NewDBSetupError(errors.New("oops")), services.ExitCodeDBSetupFailed},
+		"DB connection":     {services.NewDBConnectionError(errors.New("oops")), services.ExitCodeDBUnavailable},
+		"failed migration":  {services.NewMigrationError(failedMigration), services.ExitCodeMigrationFailed},
+		"bookkeeping":       {services.NewBookkeepingError(errors.New("oops")), services.ExitCodeBookkeepingFailed},
+		"not confirmed": {
+			services.NewNotConfirmedError(errors.New("oops")),
+			services.ExitCodeNotConfirmed,
+		},
+		"pending migrations": {
+			services.NewPendingMigrationsError(services.ReadinessStatus{}),
+			services.ExitCodeMigrationsPending,
+		},
+		"wrapped category": {
+			errors.Wrap(services.NewDBConnectionError(errors.New("oops")), "context"),
+			services.ExitCodeDBUnavailable,
+		},
+	}
+
+	for name, testCase := range cases {
+		assert.Equal(test, testCase.exitCode, services.ExitCode(testCase.err), name)
+	}
+}
+
+func TestGettingAnErrorFromRunMigrations(test *testing.T) {
+	test.Parallel()
+
+	collection := models.Collection{}
+	successful, err := models.NewMigration("/tmp/1_a.sql", "SELECT 1", models.StatusSuccessful)
+	require.Nil(test, err)
+	require.Nil(test, collection.Add(successful))
+
+	assert.Nil(test, services.ErrorFromRunMigrations(collection))
+
+	failed, err := models.NewMigration("/tmp/2_b.sql", "SELECT 1", models.StatusNotRun)
+	require.Nil(test, err)
+	require.Nil(test, collection.Add(failed.NewAsFailed(errors.New("syntax error"))))
+
+	err = services.ErrorFromRunMigrations(collection)
+
+	var migrationError services.MigrationError
+	require.True(test, errors.As(err, &migrationError))
+	assert.Equal(test, "2_b.sql", migrationError.GetMigration().GetName())
+	assert.Contains(test, err.Error(), "syntax error")
+}
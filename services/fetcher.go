@@ -1,6 +1,13 @@
 package services
 
 import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/helpers"
 	"github.com/jimenezmaximiliano/migrations/models"
 	"github.com/jimenezmaximiliano/migrations/repositories"
 )
@@ -11,38 +18,94 @@ type Fetcher interface {
 }
 
 type FetcherService struct {
-	dbRepository   repositories.DBRepository
-	fileRepository repositories.FileRepository
+	dbRepository                     repositories.DBRepository
+	fileRepository                   repositories.FileRepository
+	additionalDirectoryAbsolutePaths []string
 }
 
 // Ensure FetcherService implements Fetcher.
 var _ Fetcher = FetcherService{}
 
-// NewFetcherService returns an implementation of MigrationFetcherService.
+// NewFetcherService returns an implementation of MigrationFetcherService. The migrations of the additional
+// directories, if any, are merged with the ones of the directory given to GetMigrations (see GetMigrationSource).
 func NewFetcherService(
 	dbRepository repositories.DBRepository,
 	fileRepository repositories.FileRepository,
+	additionalDirectoryAbsolutePaths ...string,
 ) FetcherService {
 	return FetcherService{
-		dbRepository:   dbRepository,
-		fileRepository: fileRepository,
+		dbRepository:                     dbRepository,
+		fileRepository:                   fileRepository,
+		additionalDirectoryAbsolutePaths: additionalDirectoryAbsolutePaths,
+	}
+}
+
+// migrationSourceName is the name given to an additional migrations directory as {source}={path}.
+var migrationSourceName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// GetMigrationSource returns the source of the migrations of an additional migrations directory: the name given as
+// {source}={path} (e.g. billing=/app/plugins/billing/migrations/), or else its base name. They are registered on the
+// migrations table as {source}/{name}, so identical names don't collide. The source must never change once the
+// migrations have run, so an explicit name is safer than the base name.
+func GetMigrationSource(directory string) string {
+	source, path := splitMigrationsDirectory(directory)
+	if source != "" {
+		return source
+	}
+
+	return filepath.Base(path)
+}
+
+// GetMigrationsDirectoryPath returns the path of a migrations directory given as {source}={path} (see
+// GetMigrationSource), or the given directory if it has no source.
+func GetMigrationsDirectoryPath(directory string) string {
+	_, path := splitMigrationsDirectory(directory)
+
+	return path
+}
+
+// HasMigrationSource returns true if a migrations directory is given as {source}={path} (see GetMigrationSource).
+func HasMigrationSource(directory string) bool {
+	source, _ := splitMigrationsDirectory(directory)
+
+	return source != ""
+}
+
+func splitMigrationsDirectory(directory string) (source string, path string) {
+	separatorIndex := strings.Index(directory, "=")
+	if separatorIndex < 0 || !migrationSourceName.MatchString(directory[:separatorIndex]) {
+		return "", directory
 	}
+
+	return directory[:separatorIndex], directory[separatorIndex+1:]
 }
 
-// GetMigrations returns a collection of Migrations from a given directory.
+// GetMigrations returns a collection of Migrations from a given directory (and the additional directories, if any),
+// ordered by their order, which must be unique across directories.
 func (service FetcherService) GetMigrations(migrationsDirectoryAbsolutePath string) (models.Collection, error) {
+	migrationsDirectoryAbsolutePath = helpers.AddTrailingSlashToPathIfNeeded(migrationsDirectoryAbsolutePath)
+	sources, err := getMigrationSources(migrationsDirectoryAbsolutePath, service.additionalDirectoryAbsolutePaths)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
 	migrationFilePathsFromFiles, runMigrationFilePaths, err := service.
-		readMigrationPathsFromTheFileSystemAndTheDB(migrationsDirectoryAbsolutePath)
+		readMigrationPathsFromTheFileSystemAndTheDB(migrationsDirectoryAbsolutePath, sources)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	runMigrationFilePaths, err = service.resolveSquashedMigrations(migrationFilePathsFromFiles, runMigrationFilePaths)
 	if err != nil {
 		return models.Collection{}, err
 	}
 
-	collection, err := service.parseRunMigrationsFromDB(runMigrationFilePaths)
+	collection, err := service.parseRunMigrationsFromDB(runMigrationFilePaths, sources)
 	if err != nil {
 		return models.Collection{}, err
 	}
 
-	collection, err = service.parseMigrationsFromFiles(migrationFilePathsFromFiles, collection)
+	collection, err = service.parseMigrationsFromFiles(migrationFilePathsFromFiles, sources, collection)
 	if err != nil {
 		return models.Collection{}, err
 	}
@@ -50,23 +113,193 @@ func (service FetcherService) GetMigrations(migrationsDirectoryAbsolutePath stri
 	return collection, nil
 }
 
+// getMigrationSources returns the source of each migrations directory (empty for the main one), mapped by path.
+func getMigrationSources(
+	migrationsDirectoryAbsolutePath string,
+	additionalDirectoryAbsolutePaths []string,
+) (map[string]string, error) {
+	if HasMigrationSource(migrationsDirectoryAbsolutePath) {
+		return nil, NewArgumentsError(errors.Errorf(
+			"the main migrations directory [%s] cannot be named (only the additional ones are registered by name)",
+			migrationsDirectoryAbsolutePath,
+		))
+	}
+
+	sources := map[string]string{migrationsDirectoryAbsolutePath: ""}
+	directoriesBySource := map[string]string{}
+	for _, directory := range additionalDirectoryAbsolutePaths {
+		directoryPath := helpers.AddTrailingSlashToPathIfNeeded(GetMigrationsDirectoryPath(directory))
+		if _, isDuplicated := sources[directoryPath]; isDuplicated {
+			return nil, NewArgumentsError(errors.Errorf("the migrations directory [%s] is given more than once",
+				directoryPath))
+		}
+
+		source := GetMigrationSource(directory)
+		if source == "." || source == ".." || source == "/" {
+			return nil, NewArgumentsError(errors.Errorf("invalid migrations directory [%s]", directoryPath))
+		}
+
+		if otherDirectoryPath, isUsed := directoriesBySource[source]; isUsed {
+			return nil, NewArgumentsError(errors.Errorf(
+				"the migrations directories [%s] and [%s] have the same name [%s] (name them with {name}={path})",
+				otherDirectoryPath,
+				directoryPath,
+				source,
+			))
+		}
+
+		directoriesBySource[source] = directoryPath
+		sources[directoryPath] = source
+	}
+
+	return sources, nil
+}
+
 func (service FetcherService) readMigrationPathsFromTheFileSystemAndTheDB(
 	migrationsDirectoryAbsolutePath string,
+	sources map[string]string,
 ) (pathsFromFiles []string, pathsFromDB []string, err error) {
 	pathsFromFiles, err = service.fileRepository.GetMigrationFilePaths(migrationsDirectoryAbsolutePath)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	for _, directory := range service.additionalDirectoryAbsolutePaths {
+		paths, err := service.fileRepository.GetMigrationFilePaths(GetMigrationsDirectoryPath(directory))
+		if err != nil {
+			return nil, nil, err
+		}
+		pathsFromFiles = append(pathsFromFiles, paths...)
+	}
+
 	pathsFromDB, err = service.dbRepository.GetAlreadyRunMigrationFilePaths(migrationsDirectoryAbsolutePath)
 	if err != nil {
 		return pathsFromFiles, nil, err
 	}
 
-	return pathsFromFiles, pathsFromDB, nil
+	return pathsFromFiles, resolveRunMigrationPaths(pathsFromDB, migrationsDirectoryAbsolutePath, sources), nil
+}
+
+// resolveRunMigrationPaths maps the run migrations of additional directories ({main directory}{source}/{name}) to
+// their files. The ones of directories that have not been given are left out.
+func resolveRunMigrationPaths(
+	paths []string,
+	migrationsDirectoryAbsolutePath string,
+	sources map[string]string,
+) []string {
+	directoriesBySource := map[string]string{}
+	for directoryPath, source := range sources {
+		directoriesBySource[source] = directoryPath
+	}
+
+	var resolvedPaths []string
+	for _, path := range paths {
+		qualifiedName := strings.TrimPrefix(path, migrationsDirectoryAbsolutePath)
+		separatorIndex := strings.Index(qualifiedName, "/")
+		if separatorIndex < 0 {
+			resolvedPaths = append(resolvedPaths, path)
+			continue
+		}
+
+		directoryPath, isGiven := directoriesBySource[qualifiedName[:separatorIndex]]
+		if isGiven {
+			resolvedPaths = append(resolvedPaths, directoryPath+qualifiedName[separatorIndex+1:])
+		}
+	}
+
+	return resolvedPaths
+}
+
+// getSourceOfPath returns the source of the migrations directory of a migration file.
+func getSourceOfPath(path string, sources map[string]string) string {
+	return sources[path[:strings.LastIndex(path, "/")+1]]
+}
+
+// resolveSquashedMigrations replaces the run migrations that have been squashed (see Squasher) with the squashed
+// migration, so it's considered run where its parts have been run.
+func (service FetcherService) resolveSquashedMigrations(
+	pathsFromFiles []string,
+	pathsFromDB []string,
+) ([]string, error) {
+	pathsOnDisk := map[string]bool{}
+	for _, path := range pathsFromFiles {
+		pathsOnDisk[path] = true
+	}
+
+	runPaths := map[string]bool{}
+	someRunMigrationIsMissing := false
+	for _, path := range pathsFromDB {
+		runPaths[path] = true
+		if !pathsOnDisk[path] {
+			someRunMigrationIsMissing = true
+		}
+	}
+
+	if !someRunMigrationIsMissing {
+		return pathsFromDB, nil
+	}
+
+	squashedPaths, err := getSquashedPaths(service.fileRepository, pathsFromFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolvedPaths []string
+	resolvedSquashedPaths := map[string]bool{}
+	for _, path := range pathsFromDB {
+		squashPath, isSquashed := squashedPaths[path]
+		if pathsOnDisk[path] || !isSquashed {
+			resolvedPaths = append(resolvedPaths, path)
+			continue
+		}
+
+		if resolvedSquashedPaths[squashPath] || runPaths[squashPath] {
+			continue
+		}
+
+		for partPath, partSquashPath := range squashedPaths {
+			if partSquashPath == squashPath && !runPaths[partPath] {
+				return nil, errors.Errorf(
+					"the squashed migration [%s] has been partially run (at least [%s] is missing), "+
+						"run the archived migrations first",
+					squashPath,
+					partPath,
+				)
+			}
+		}
+
+		resolvedSquashedPaths[squashPath] = true
+		resolvedPaths = append(resolvedPaths, squashPath)
+	}
+
+	return resolvedPaths, nil
 }
 
-func (service FetcherService) parseRunMigrationsFromDB(filePaths []string) (models.Collection, error) {
+// getSquashedPaths returns the paths of squashed migrations mapped to the path of the migration they are part of.
+func getSquashedPaths(
+	fileRepository repositories.FileRepository,
+	pathsFromFiles []string,
+) (map[string]string, error) {
+	squashedPaths := map[string]string{}
+	for _, path := range pathsFromFiles {
+		query, err := fileRepository.GetMigrationQuery(path)
+		if err != nil {
+			return nil, err
+		}
+
+		directoryPath := path[:strings.LastIndex(path, "/")+1]
+		for _, name := range GetSquashedMigrationNames(query) {
+			squashedPaths[directoryPath+name] = path
+		}
+	}
+
+	return squashedPaths, nil
+}
+
+func (service FetcherService) parseRunMigrationsFromDB(
+	filePaths []string,
+	sources map[string]string,
+) (models.Collection, error) {
 	collection := models.Collection{}
 	for _, filePath := range filePaths {
 		migrationQuery, err := service.fileRepository.GetMigrationQuery(filePath)
@@ -74,7 +307,12 @@ func (service FetcherService) parseRunMigrationsFromDB(filePaths []string) (mode
 			return collection, err
 		}
 
-		migration, err := models.NewMigration(filePath, migrationQuery, models.StatusSuccessful)
+		migration, err := models.NewMigrationFromSource(
+			getSourceOfPath(filePath, sources),
+			filePath,
+			migrationQuery,
+			models.StatusSuccessful,
+		)
 		if err != nil {
 			return collection, err
 		}
@@ -90,6 +328,7 @@ func (service FetcherService) parseRunMigrationsFromDB(filePaths []string) (mode
 
 func (service FetcherService) parseMigrationsFromFiles(
 	filePaths []string,
+	sources map[string]string,
 	collection models.Collection,
 ) (models.Collection, error) {
 	for _, migrationFilePath := range filePaths {
@@ -101,7 +340,12 @@ func (service FetcherService) parseMigrationsFromFiles(
 			return collection, err
 		}
 
-		migration, err := models.NewMigration(migrationFilePath, migrationQuery, models.StatusNotRun)
+		migration, err := models.NewMigrationFromSource(
+			getSourceOfPath(migrationFilePath, sources),
+			migrationFilePath,
+			migrationQuery,
+			models.StatusNotRun,
+		)
 		if err != nil {
 			return collection, err
 		}
@@ -5,9 +5,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/models"
 	"github.com/jimenezmaximiliano/migrations/services"
 )
 
@@ -134,3 +136,190 @@ func TestGettingMigrationsFailsIfAMigrationPathAlreadyRunCannotBeRead(test *test
 
 	assert.NotNil(test, err)
 }
+
+func TestGettingMigrationsConsidersASquashedMigrationRunIfItsPartsHaveBeenRun(test *testing.T) {
+	test.Parallel()
+
+	const squashedMigrationPath = "/tmp/2_squashed.sql"
+	const squashedMigrationQuery = "-- migrations:squashed 1_a.sql, 2_b.sql\nSELECT 1;\nSELECT 2;"
+	dbRepository := &mocks.DBRepository{}
+	defer dbRepository.AssertExpectations(test)
+	dbRepository.On("GetAlreadyRunMigrationFilePaths", migrationsDir).
+		Return([]string{migrationPath1, migrationPath2}, nil)
+
+	fileRepository := &mocks.FileRepository{}
+	defer fileRepository.AssertExpectations(test)
+	fileRepository.On("GetMigrationFilePaths", migrationsDir).
+		Return([]string{squashedMigrationPath}, nil)
+	fileRepository.On("GetMigrationQuery", squashedMigrationPath).
+		Return(squashedMigrationQuery, nil)
+
+	service := services.NewFetcherService(dbRepository, fileRepository)
+
+	migrations, err := service.GetMigrations(migrationsDir)
+
+	require.Nil(test, err)
+	require.Len(test, migrations.GetAll(), 1)
+	assert.Equal(test, squashedMigrationPath, migrations.GetAll()[0].GetAbsolutePath())
+	assert.Empty(test, migrations.GetMigrationsToRun())
+}
+
+func TestGettingMigrationsFailsIfASquashedMigrationHasBeenPartiallyRun(test *testing.T) {
+	test.Parallel()
+
+	const squashedMigrationPath = "/tmp/2_squashed.sql"
+	const squashedMigrationQuery = "-- migrations:squashed 1_a.sql, 2_b.sql\nSELECT 1;\nSELECT 2;"
+	dbRepository := &mocks.DBRepository{}
+	defer dbRepository.AssertExpectations(test)
+	dbRepository.On("GetAlreadyRunMigrationFilePaths", migrationsDir).
+		Return([]string{migrationPath1}, nil)
+
+	fileRepository := &mocks.FileRepository{}
+	defer fileRepository.AssertExpectations(test)
+	fileRepository.On("GetMigrationFilePaths", migrationsDir).
+		Return([]string{squashedMigrationPath}, nil)
+	fileRepository.On("GetMigrationQuery", squashedMigrationPath).
+		Return(squashedMigrationQuery, nil)
+
+	service := services.NewFetcherService(dbRepository, fileRepository)
+
+	_, err := service.GetMigrations(migrationsDir)
+
+	require.NotNil(test, err)
+	assert.Contains(test, err.Error(), "partially run")
+}
+
+func TestGettingMigrationsFromSeveralDirectories(test *testing.T) {
+	test.Parallel()
+
+	dbRepository := &mocks.DBRepository{}
+	defer dbRepository.AssertExpectations(test)
+	dbRepository.On("GetAlreadyRunMigrationFilePaths", "/app/core/").
+		Return([]string{"/app/core/1_a.sql", "/app/core/billing/2_b.sql", "/app/core/removed/5_e.sql"}, nil)
+
+	fileRepository := &mocks.FileRepository{}
+	defer fileRepository.AssertExpectations(test)
+	fileRepository.On("GetMigrationFilePaths", "/app/core/").
+		Return([]string{"/app/core/1_a.sql", "/app/core/3_c.sql"}, nil)
+	fileRepository.On("GetMigrationFilePaths", "/app/plugins/billing").
+		Return([]string{"/app/plugins/billing/2_b.sql", "/app/plugins/billing/4_d.sql"}, nil)
+	for _, path := range []string{
+		"/app/core/1_a.sql",
+		"/app/core/3_c.sql",
+		"/app/plugins/billing/2_b.sql",
+		"/app/plugins/billing/4_d.sql",
+	} {
+		fileRepository.On("GetMigrationQuery", path).Return("SELECT 1", nil)
+	}
+
+	service := services.NewFetcherService(dbRepository, fileRepository, "/app/plugins/billing")
+
+	migrations, err := service.GetMigrations("/app/core")
+
+	require.Nil(test, err)
+	require.Len(test, migrations.GetAll(), 4)
+
+	var names []string
+	for _, migration := range migrations.GetAll() {
+		names = append(names, models.GetQualifiedName(migration))
+	}
+	assert.Equal(test, []string{"1_a.sql", "billing/2_b.sql", "3_c.sql", "billing/4_d.sql"}, names)
+
+	migrationsToRun := migrations.GetMigrationsToRun()
+	require.Len(test, migrationsToRun, 2)
+	assert.Equal(test, "/app/core/3_c.sql", migrationsToRun[0].GetAbsolutePath())
+	assert.Equal(test, "/app/plugins/billing/4_d.sql", migrationsToRun[1].GetAbsolutePath())
+	assert.Equal(test, "billing", models.GetSource(migrationsToRun[1]))
+}
+
+func TestGettingMigrationsFailsIfTwoDirectoriesHaveMigrationsWithTheSameOrder(test *testing.T) {
+	test.Parallel()
+
+	dbRepository := &mocks.DBRepository{}
+	dbRepository.On("GetAlreadyRunMigrationFilePaths", "/app/core/").Return(nil, nil)
+
+	fileRepository := &mocks.FileRepository{}
+	fileRepository.On("GetMigrationFilePaths", "/app/core/").Return([]string{"/app/core/1_a.sql"}, nil)
+	fileRepository.On("GetMigrationFilePaths", "/app/billing/").Return([]string{"/app/billing/1_a.sql"}, nil)
+	fileRepository.On("GetMigrationQuery", "/app/core/1_a.sql").Return("SELECT 1", nil)
+	fileRepository.On("GetMigrationQuery", "/app/billing/1_a.sql").Return("SELECT 1", nil)
+
+	service := services.NewFetcherService(dbRepository, fileRepository, "/app/billing/")
+
+	_, err := service.GetMigrations("/app/core/")
+
+	require.NotNil(test, err)
+	assert.Contains(test, err.Error(), "billing/1_a.sql")
+}
+
+func TestGettingMigrationsFailsIfTwoDirectoriesHaveTheSameName(test *testing.T) {
+	test.Parallel()
+
+	service := services.NewFetcherService(
+		&mocks.DBRepository{},
+		&mocks.FileRepository{},
+		"/app/plugins/billing/migrations",
+		"/app/plugins/users/migrations",
+	)
+
+	_, err := service.GetMigrations("/app/core/")
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestGettingMigrationsFromNamedDirectories(test *testing.T) {
+	test.Parallel()
+
+	dbRepository := &mocks.DBRepository{}
+	defer dbRepository.AssertExpectations(test)
+	dbRepository.On("GetAlreadyRunMigrationFilePaths", "/app/core/").
+		Return([]string{"/app/core/billing/2_b.sql"}, nil)
+
+	fileRepository := &mocks.FileRepository{}
+	defer fileRepository.AssertExpectations(test)
+	fileRepository.On("GetMigrationFilePaths", "/app/core/").Return([]string{}, nil)
+	fileRepository.On("GetMigrationFilePaths", "/app/plugins/billing/migrations/").
+		Return([]string{"/app/plugins/billing/migrations/2_b.sql"}, nil)
+	fileRepository.On("GetMigrationFilePaths", "/app/plugins/users/migrations/").
+		Return([]string{"/app/plugins/users/migrations/3_c.sql"}, nil)
+	fileRepository.On("GetMigrationQuery", mock.AnythingOfType("string")).Return("SELECT 1", nil)
+
+	service := services.NewFetcherService(
+		dbRepository,
+		fileRepository,
+		"billing=/app/plugins/billing/migrations/",
+		"users=/app/plugins/users/migrations/",
+	)
+
+	migrations, err := service.GetMigrations("/app/core/")
+
+	require.Nil(test, err)
+	var names []string
+	for _, migration := range migrations.GetAll() {
+		names = append(names, models.GetQualifiedName(migration))
+	}
+	assert.Equal(test, []string{"billing/2_b.sql", "users/3_c.sql"}, names)
+	migrationsToRun := migrations.GetMigrationsToRun()
+	require.Len(test, migrationsToRun, 1)
+	assert.Equal(test, "/app/plugins/users/migrations/3_c.sql", migrationsToRun[0].GetAbsolutePath())
+}
+
+func TestGettingMigrationsFailsIfTheMainDirectoryIsNamed(test *testing.T) {
+	test.Parallel()
+
+	service := services.NewFetcherService(&mocks.DBRepository{}, &mocks.FileRepository{})
+
+	_, err := service.GetMigrations("core=/app/core/")
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestGettingTheSourceOfAMigrationsDirectory(test *testing.T) {
+	test.Parallel()
+
+	assert.Equal(test, "migrations", services.GetMigrationSource("/app/plugins/billing/migrations/"))
+	assert.Equal(test, "billing", services.GetMigrationSource("billing=/app/plugins/billing/migrations/"))
+	assert.Equal(test, "/app/plugins/billing/migrations/",
+		services.GetMigrationsDirectoryPath("billing=/app/plugins/billing/migrations/"))
+	assert.Equal(test, "/app/a=b/", services.GetMigrationsDirectoryPath("/app/a=b/"))
+}
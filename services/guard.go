@@ -0,0 +1,230 @@
+package services
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/helpers"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+)
+
+// DefaultProtectedEnvironment is the environment a DB is marked as by the protect command if none is given.
+const DefaultProtectedEnvironment = "production"
+
+// MigrationPreview describes what the migrate command is about to do, so it can be confirmed before doing it.
+type MigrationPreview struct {
+	Target            repositories.DBTarget
+	PendingMigrations []models.Migration
+	// DestructiveStatements are the statements of the pending migrations that lose data (see DestructiveLintRules).
+	DestructiveStatements []LintIssue
+	// ProtectedEnvironment is the environment the DB is marked as (see DBRepository.SetProtectedEnvironment), if any.
+	ProtectedEnvironment string
+}
+
+// IsProtected returns true if the DB is marked as a protected environment.
+func (preview MigrationPreview) IsProtected() bool {
+	return preview.ProtectedEnvironment != ""
+}
+
+// GetConfirmationAnswer returns what the user has to type to confirm: the name of the database (the file name for
+// SQLite), or "yes" if it's unknown.
+func (preview MigrationPreview) GetConfirmationAnswer() string {
+	if preview.Target.Database == "" {
+		return "yes"
+	}
+
+	if preview.Target.Driver == repositories.DialectSQLite {
+		return filepath.Base(preview.Target.Database)
+	}
+
+	return preview.Target.Database
+}
+
+// Guard gets what the migrate command is about to do, without doing it, and handles the protected environment mark
+// of the DB.
+type Guard interface {
+	GetMigrationPreview() (MigrationPreview, error)
+	GetProtectedEnvironment() (string, error)
+	Protect(environment string) error
+	Unprotect() error
+}
+
+type guardService struct {
+	migrationFetcherService         Fetcher
+	dbRepository                    repositories.DBRepository
+	destructiveRules                []LintRule
+	migrationsDirectoryAbsolutePath string
+}
+
+// Ensure guardService implements Guard.
+var _ Guard = guardService{}
+
+// NewGuardService returns an implementation of Guard.
+func NewGuardService(
+	migrationFetcherService Fetcher,
+	dbRepository repositories.DBRepository,
+	migrationsDirectoryAbsolutePath string,
+) Guard {
+	return guardService{
+		migrationFetcherService:         migrationFetcherService,
+		dbRepository:                    dbRepository,
+		destructiveRules:                DestructiveLintRules(),
+		migrationsDirectoryAbsolutePath: helpers.AddTrailingSlashToPathIfNeeded(migrationsDirectoryAbsolutePath),
+	}
+}
+
+// GetMigrationPreview returns the DB the migrations would run on, the pending migrations and their destructive
+// statements, and whether the DB is protected. The DB is unknown and unprotected if the DBRepository doesn't implement
+// repositories.DBTargetRepository.
+func (service guardService) GetMigrationPreview() (MigrationPreview, error) {
+	err := service.dbRepository.Ping()
+	if err != nil {
+		return MigrationPreview{}, NewDBConnectionError(errors.Wrap(err, "failed to connect to the DB"))
+	}
+
+	// The preview is read-only: the migrations table isn't created until the migrations are confirmed and run.
+	preview := MigrationPreview{}
+	targetRepository, hasTarget := repositories.UnwrapDBRepository(service.dbRepository).(repositories.DBTargetRepository)
+	if hasTarget {
+		preview.Target, err = targetRepository.GetTarget()
+		if err != nil {
+			return MigrationPreview{}, err
+		}
+
+		preview.ProtectedEnvironment, err = targetRepository.GetProtectedEnvironment()
+		if err != nil {
+			return MigrationPreview{}, NewBookkeepingError(err)
+		}
+	}
+
+	allMigrations, err := service.migrationFetcherService.GetMigrations(service.migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return MigrationPreview{}, err
+	}
+
+	preview.PendingMigrations = allMigrations.GetMigrationsToRun()
+	for _, migration := range preview.PendingMigrations {
+		preview.DestructiveStatements = append(preview.DestructiveStatements, service.getDestructiveStatements(migration)...)
+	}
+
+	return preview, nil
+}
+
+// getDestructiveStatements returns the statements of a migration that match DestructiveLintRules. Unlike the lint
+// command, the lint-ignore comments don't hide them: losing data must always be confirmed.
+func (service guardService) getDestructiveStatements(migration models.Migration) []LintIssue {
+	var statements []LintIssue
+	for _, statement := range splitStatements(migration.GetQuery()) {
+		for _, rule := range service.destructiveRules {
+			message, found := rule.Check(statement)
+			if !found {
+				continue
+			}
+
+			statements = append(statements, LintIssue{
+				FilePath: migration.GetAbsolutePath(),
+				Rule:     rule.GetName(),
+				Severity: rule.GetDefaultSeverity(),
+				Message:  message,
+			})
+		}
+	}
+
+	return statements
+}
+
+// GetProtectedEnvironment returns the environment the DB is marked as (e.g. production), or an empty string if it's not
+// protected. The DB is unprotected if the DBRepository doesn't implement repositories.DBTargetRepository.
+func (service guardService) GetProtectedEnvironment() (string, error) {
+	err := service.dbRepository.Ping()
+	if err != nil {
+		return "", NewDBConnectionError(errors.Wrap(err, "failed to connect to the DB"))
+	}
+
+	targetRepository, hasTarget := repositories.UnwrapDBRepository(service.dbRepository).(repositories.DBTargetRepository)
+	if !hasTarget {
+		return "", nil
+	}
+
+	environment, err := targetRepository.GetProtectedEnvironment()
+	if err != nil {
+		return "", NewBookkeepingError(err)
+	}
+
+	return environment, nil
+}
+
+// Protect marks the DB as a protected environment (e.g. production), so migrating it has to be explicitly allowed. It
+// needs a DBRepository that implements repositories.DBTargetRepository.
+func (service guardService) Protect(environment string) error {
+	if environment == "" {
+		return NewArgumentsError(errors.New("the protected environment cannot be empty"))
+	}
+
+	return service.setProtectedEnvironment(environment)
+}
+
+// Unprotect removes the protected environment mark of the DB.
+func (service guardService) Unprotect() error {
+	return service.setProtectedEnvironment("")
+}
+
+func (service guardService) setProtectedEnvironment(environment string) error {
+	targetRepository, hasTarget := repositories.UnwrapDBRepository(service.dbRepository).(repositories.DBTargetRepository)
+	if !hasTarget {
+		return NewBookkeepingError(errors.New("the DB repository cannot mark the DB as a protected environment"))
+	}
+
+	err := service.dbRepository.Ping()
+	if err != nil {
+		return NewDBConnectionError(errors.Wrap(err, "failed to connect to the DB"))
+	}
+
+	err = targetRepository.SetProtectedEnvironment(environment)
+	if err != nil {
+		return NewBookkeepingError(err)
+	}
+
+	return nil
+}
+
+// DestructiveLintRules returns the rules that find statements that lose data, shown before running the migrations
+// (even if a lint-ignore comment suppresses them). Unlike DefaultLintRules, they are not about locks or failures.
+func DestructiveLintRules() []LintRule {
+	return []LintRule{
+		regexpLintRule{
+			name:            "drop-database",
+			defaultSeverity: LintSeverityError,
+			message:         "dropping a database loses its data",
+			matches:         regexp.MustCompile(`(?i)^DROP (DATABASE|SCHEMA)\b`),
+		},
+		regexpLintRule{
+			name:            "drop-table",
+			defaultSeverity: LintSeverityError,
+			message:         "dropping a table loses its data",
+			matches:         regexp.MustCompile(`(?i)^DROP TABLE\b`),
+		},
+		regexpLintRule{
+			name:            "truncate-table",
+			defaultSeverity: LintSeverityError,
+			message:         "truncating a table deletes every row",
+			matches:         regexp.MustCompile(`(?i)^TRUNCATE\b`),
+		},
+		regexpLintRule{
+			name:            "drop-column",
+			defaultSeverity: LintSeverityError,
+			message:         "dropping a column loses its data",
+			matches:         regexp.MustCompile(`(?i)^ALTER TABLE\b.*\bDROP (COLUMN\b|[^ ]+ *(,|;|$))`),
+		},
+		regexpLintRule{
+			name:            "delete-without-where",
+			defaultSeverity: LintSeverityError,
+			message:         "a DELETE without a WHERE clause deletes every row",
+			matches:         regexp.MustCompile(`(?i)^DELETE\b`),
+			exceptions:      regexp.MustCompile(`(?i)\bWHERE\b`),
+		},
+	}
+}
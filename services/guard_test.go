@@ -0,0 +1,173 @@
+package services_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestGettingTheMigrationPreview(test *testing.T) {
+	test.Parallel()
+
+	collection := models.Collection{}
+	migration1, _ := models.NewMigration("/tmp/1_a.sql", "CREATE TABLE a (id INT)", models.StatusSuccessful)
+	require.Nil(test, collection.Add(migration1))
+	migration2, _ := models.NewMigration("/tmp/2_b.sql", "DROP TABLE a;\nDELETE FROM b", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration2))
+	migration3, _ := models.NewMigration("/tmp/3_c.sql", "DELETE FROM c WHERE id = 1", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration3))
+	ignoredQuery := "-- migrations:lint-ignore\nTRUNCATE d;"
+	migration4, _ := models.NewMigration("/tmp/4_d.sql", ignoredQuery, models.StatusNotRun)
+	require.Nil(test, collection.Add(migration4))
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+
+	target := repositories.DBTarget{Driver: repositories.DialectSQLite, Database: "/data/app.sqlite"}
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	targetRepository := &mocks.DBTargetRepository{}
+	defer targetRepository.AssertExpectations(test)
+	targetRepository.On("GetTarget").Return(target, nil)
+	targetRepository.On("GetProtectedEnvironment").Return("production", nil)
+
+	service := services.NewGuardService(fetcher, targetDBRepository{db, targetRepository}, "/tmp")
+
+	preview, err := service.GetMigrationPreview()
+
+	require.Nil(test, err)
+	assert.Equal(test, target, preview.Target)
+	assert.True(test, preview.IsProtected())
+	assert.Equal(test, "app.sqlite", preview.GetConfirmationAnswer())
+	require.Len(test, preview.PendingMigrations, 3)
+	require.Len(test, preview.DestructiveStatements, 3)
+	assert.Equal(test, "drop-table", preview.DestructiveStatements[0].Rule)
+	assert.Equal(test, "delete-without-where", preview.DestructiveStatements[1].Rule)
+	assert.Equal(test, "truncate-table", preview.DestructiveStatements[2].Rule)
+	assert.Equal(test, "/tmp/4_d.sql", preview.DestructiveStatements[2].FilePath)
+}
+
+func TestTheMigrationPreviewShowsDestructiveStatementsAfterAQuoteInAComment(test *testing.T) {
+	test.Parallel()
+
+	collection := models.Collection{}
+	query := "-- Drop the gophers' table\nDROP TABLE gophers;\n/* Don't keep the rows */\nDELETE FROM rows;"
+	migration, _ := models.NewMigration("/tmp/1_a.sql", query, models.StatusNotRun)
+	require.Nil(test, collection.Add(migration))
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+
+	service := services.NewGuardService(fetcher, db, "/tmp")
+
+	preview, err := service.GetMigrationPreview()
+
+	require.Nil(test, err)
+	require.Len(test, preview.DestructiveStatements, 2)
+	assert.Equal(test, "drop-table", preview.DestructiveStatements[0].Rule)
+	assert.Equal(test, "delete-without-where", preview.DestructiveStatements[1].Rule)
+}
+
+func TestGettingTheMigrationPreviewFailsIfTheDBIsNotReachable(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(fmt.Errorf("connection refused"))
+
+	service := services.NewGuardService(&mocks.Fetcher{}, db, "/tmp")
+
+	_, err := service.GetMigrationPreview()
+
+	assert.Equal(test, services.ExitCodeDBUnavailable, services.ExitCode(err))
+}
+
+func TestTheConfirmationAnswerIsTheDatabaseName(test *testing.T) {
+	test.Parallel()
+
+	preview := services.MigrationPreview{Target: repositories.DBTarget{Driver: repositories.DialectMySQL, Database: "app"}}
+	assert.Equal(test, "app", preview.GetConfirmationAnswer())
+
+	preview = services.MigrationPreview{Target: repositories.DBTarget{Driver: repositories.DialectMySQL}}
+	assert.Equal(test, "yes", preview.GetConfirmationAnswer())
+}
+
+func TestProtectingTheDB(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	targetRepository := &mocks.DBTargetRepository{}
+	defer targetRepository.AssertExpectations(test)
+	targetRepository.On("SetProtectedEnvironment", "staging").Return(nil).Once()
+	targetRepository.On("SetProtectedEnvironment", "").Return(nil).Once()
+
+	service := services.NewGuardService(&mocks.Fetcher{}, targetDBRepository{db, targetRepository}, "/tmp")
+
+	require.Nil(test, service.Protect("staging"))
+	require.Nil(test, service.Unprotect())
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(service.Protect("")))
+}
+
+func TestGettingTheProtectedEnvironmentOfTheDB(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	targetRepository := &mocks.DBTargetRepository{}
+	defer targetRepository.AssertExpectations(test)
+	targetRepository.On("GetProtectedEnvironment").Return("production", nil).Once()
+
+	environment, err := services.NewGuardService(&mocks.Fetcher{}, targetDBRepository{db, targetRepository}, "/tmp").
+		GetProtectedEnvironment()
+
+	require.Nil(test, err)
+	assert.Equal(test, "production", environment)
+
+	environment, err = services.NewGuardService(&mocks.Fetcher{}, db, "/tmp").GetProtectedEnvironment()
+
+	require.Nil(test, err)
+	assert.Empty(test, environment)
+}
+
+func TestTheDBIsUnknownIfTheDBRepositoryCannotDescribeIt(test *testing.T) {
+	test.Parallel()
+
+	migration, _ := models.NewMigration("/tmp/1_a.sql", "CREATE TABLE a (id INT)", models.StatusNotRun)
+	collection := models.Collection{}
+	require.Nil(test, collection.Add(migration))
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+
+	service := services.NewGuardService(fetcher, db, "/tmp")
+
+	preview, err := service.GetMigrationPreview()
+
+	require.Nil(test, err)
+	assert.False(test, preview.IsProtected())
+	assert.Equal(test, "yes", preview.GetConfirmationAnswer())
+	assert.Equal(test, services.ExitCodeBookkeepingFailed, services.ExitCode(service.Protect("production")))
+}
+
+// targetDBRepository is a DBRepository that implements repositories.DBTargetRepository.
+type targetDBRepository struct {
+	*mocks.DBRepository
+	*mocks.DBTargetRepository
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/repositories"
+)
+
+const (
+	// LintSeverityOff disables a lint rule.
+	LintSeverityOff = "off"
+	// LintSeverityWarning reports a lint issue without failing.
+	LintSeverityWarning = "warning"
+	// LintSeverityError reports a lint issue and makes the lint command fail.
+	LintSeverityError = "error"
+)
+
+var validLintSeverities = []string{LintSeverityOff, LintSeverityWarning, LintSeverityError}
+
+// lintIgnoreComment suppresses lint rules for a whole migration file:
+//
+//	-- migrations:lint-ignore drop-table, delete-without-where
+//
+// Without rule names, every rule is suppressed.
+var lintIgnoreComment = regexp.MustCompile(`(?m)^\s*--\s*migrations:lint-ignore\b(.*)$`)
+
+// LintRule checks a single SQL statement of a migration.
+type LintRule interface {
+	GetName() string
+	GetDefaultSeverity() string
+	// Check returns a message describing the issue, if the statement breaks the rule.
+	Check(statement string) (message string, found bool)
+}
+
+// LintIssue is a statement of a migration that breaks a LintRule.
+type LintIssue struct {
+	FilePath string
+	Rule     string
+	Severity string
+	Message  string
+}
+
+// Linter checks the contents of the migrations without connecting to the DB.
+type Linter interface {
+	Lint(migrationsDirectoryAbsolutePath string) ([]LintIssue, error)
+	LintMigration(migrationAbsolutePath, query string) []LintIssue
+}
+
+type lintService struct {
+	fileRepository repositories.FileRepository
+	rules          []LintRule
+	severities     map[string]string
+}
+
+// Ensure lintService implements Linter.
+var _ Linter = lintService{}
+
+// NewLintService returns an implementation of Linter. The severities (indexed by rule name) override the default
+// severity of each rule.
+func NewLintService(
+	fileRepository repositories.FileRepository,
+	rules []LintRule,
+	severities map[string]string,
+) (Linter, error) {
+	for ruleName, severity := range severities {
+		if !isLintRuleDefined(rules, ruleName) {
+			return nil, NewArgumentsError(errors.Errorf("unknown lint rule [%s] (available rules: %s)",
+				ruleName,
+				strings.Join(GetLintRuleNames(rules), ", ")))
+		}
+
+		if !isLintSeverityValid(severity) {
+			return nil, NewArgumentsError(errors.Errorf("invalid severity [%s] for the lint rule [%s]", severity, ruleName))
+		}
+	}
+
+	return lintService{
+		fileRepository: fileRepository,
+		rules:          rules,
+		severities:     severities,
+	}, nil
+}
+
+// Lint returns the issues found on every migration of the given directory.
+func (service lintService) Lint(migrationsDirectoryAbsolutePath string) ([]LintIssue, error) {
+	filePaths, err := service.fileRepository.GetMigrationFilePaths(migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := []LintIssue{}
+	for _, filePath := range filePaths {
+		query, err := service.fileRepository.GetMigrationQuery(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, service.LintMigration(filePath, query)...)
+	}
+
+	return issues, nil
+}
+
+// LintMigration returns the issues found on the query of a migration.
+func (service lintService) LintMigration(migrationAbsolutePath, query string) []LintIssue {
+	ignoredRules, allRulesAreIgnored := getIgnoredLintRules(query)
+	if allRulesAreIgnored {
+		return nil
+	}
+
+	var issues []LintIssue
+	for _, statement := range splitStatements(query) {
+		for _, rule := range service.rules {
+			severity := service.getSeverity(rule)
+			if severity == LintSeverityOff || ignoredRules[rule.GetName()] {
+				continue
+			}
+
+			message, found := rule.Check(statement)
+			if !found {
+				continue
+			}
+
+			issues = append(issues, LintIssue{
+				FilePath: migrationAbsolutePath,
+				Rule:     rule.GetName(),
+				Severity: severity,
+				Message:  message,
+			})
+		}
+	}
+
+	return issues
+}
+
+func (service lintService) getSeverity(rule LintRule) string {
+	severity, isOverridden := service.severities[rule.GetName()]
+	if isOverridden {
+		return severity
+	}
+
+	return rule.GetDefaultSeverity()
+}
+
+// HasLintErrors returns true if any of the given issues has an error severity.
+func HasLintErrors(issues []LintIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == LintSeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseLintSeverities parses a list of severities in the format rule=severity,rule=severity.
+func ParseLintSeverities(rawSeverities string) (map[string]string, error) {
+	severities := map[string]string{}
+	for _, rawSeverity := range strings.Split(rawSeverities, ",") {
+		rawSeverity = strings.TrimSpace(rawSeverity)
+		if rawSeverity == "" {
+			continue
+		}
+
+		parts := strings.SplitN(rawSeverity, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.Errorf("invalid lint severity [%s] (the format must be rule=severity)", rawSeverity)
+		}
+
+		severity := strings.TrimSpace(parts[1])
+		if !isLintSeverityValid(severity) {
+			return nil, errors.Errorf("invalid lint severity [%s] (valid severities: %s)",
+				rawSeverity,
+				strings.Join(validLintSeverities, ", "))
+		}
+		severities[strings.TrimSpace(parts[0])] = severity
+	}
+
+	return severities, nil
+}
+
+func getIgnoredLintRules(query string) (ignoredRules map[string]bool, allRulesAreIgnored bool) {
+	ignoredRules = map[string]bool{}
+	for _, match := range lintIgnoreComment.FindAllStringSubmatch(query, -1) {
+		ruleNames := strings.FieldsFunc(match[1], func(character rune) bool {
+			return character == ',' || character == ' ' || character == '\t' || character == '\r'
+		})
+		if len(ruleNames) == 0 {
+			return nil, true
+		}
+
+		for _, ruleName := range ruleNames {
+			ignoredRules[ruleName] = true
+		}
+	}
+
+	return ignoredRules, false
+}
+
+var sqlWhitespace = regexp.MustCompile(`\s+`)
+
+// splitStatements removes comments and string literals from a query and splits it into single spaced statements,
+// so rules don't need to deal with those details.
+func splitStatements(query string) []string {
+	var statements []string
+	for _, statement := range strings.Split(removeCommentsAndStrings(query), ";") {
+		statement = strings.TrimSpace(sqlWhitespace.ReplaceAllString(statement, " "))
+		if statement == "" {
+			continue
+		}
+		statements = append(statements, statement)
+	}
+
+	return statements
+}
+
+// removeCommentsAndStrings replaces the comments of a query with a space and its string literals with an empty string,
+// in a single left-to-right scan, so quotes inside comments and comment markers inside strings are ignored.
+func removeCommentsAndStrings(query string) string {
+	var result strings.Builder
+	for index := 0; index < len(query); index++ {
+		switch {
+		case strings.HasPrefix(query[index:], "--"):
+			end := strings.IndexByte(query[index:], '\n')
+			if end == -1 {
+				end = len(query) - index
+			}
+			index += end - 1
+			result.WriteByte(' ')
+		case strings.HasPrefix(query[index:], "/*"):
+			end := strings.Index(query[index+2:], "*/")
+			if end == -1 {
+				end = len(query) - index - 2
+			}
+			index += end + 3
+			result.WriteByte(' ')
+		case query[index] == '\'':
+			// Skip to the closing quote, taking '' as an escaped quote.
+			for index++; index < len(query); index++ {
+				if query[index] != '\'' {
+					continue
+				}
+				if index+1 < len(query) && query[index+1] == '\'' {
+					index++
+					continue
+				}
+				break
+			}
+			result.WriteString("''")
+		default:
+			result.WriteByte(query[index])
+		}
+	}
+
+	return result.String()
+}
+
+// regexpLintRule is a LintRule that matches statements using regular expressions.
+type regexpLintRule struct {
+	name            string
+	defaultSeverity string
+	message         string
+	matches         *regexp.Regexp
+	exceptions      *regexp.Regexp
+}
+
+// Ensure regexpLintRule implements LintRule.
+var _ LintRule = regexpLintRule{}
+
+func (rule regexpLintRule) GetName() string {
+	return rule.name
+}
+
+func (rule regexpLintRule) GetDefaultSeverity() string {
+	return rule.defaultSeverity
+}
+
+func (rule regexpLintRule) Check(statement string) (string, bool) {
+	if !rule.matches.MatchString(statement) {
+		return "", false
+	}
+
+	if rule.exceptions != nil && rule.exceptions.MatchString(statement) {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s: %s", rule.message, abbreviate(statement)), true
+}
+
+// DefaultLintRules returns the built-in lint rules.
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		regexpLintRule{
+			name:            "drop-table",
+			defaultSeverity: LintSeverityError,
+			message:         "dropping a table loses its data",
+			matches:         regexp.MustCompile(`(?i)^DROP TABLE\b`),
+		},
+		regexpLintRule{
+			name:            "delete-without-where",
+			defaultSeverity: LintSeverityError,
+			message:         "a DELETE without a WHERE clause deletes every row",
+			matches:         regexp.MustCompile(`(?i)^DELETE\b`),
+			exceptions:      regexp.MustCompile(`(?i)\bWHERE\b`),
+		},
+		regexpLintRule{
+			name:            "not-null-column-without-default",
+			defaultSeverity: LintSeverityError,
+			message:         "adding a NOT NULL column without a default fails on tables with rows",
+			matches:         regexp.MustCompile(`(?i)^ALTER TABLE\b.*\bADD\b.*\bNOT NULL\b`),
+			exceptions:      regexp.MustCompile(`(?i)\bDEFAULT\b`),
+		},
+		regexpLintRule{
+			name:            "non-concurrent-index",
+			defaultSeverity: LintSeverityWarning,
+			message:         "building an index without CONCURRENTLY (or LOCK=NONE) blocks writes on big tables",
+			matches:         regexp.MustCompile(`(?i)^CREATE (UNIQUE )?INDEX\b|^ALTER TABLE\b.*\bADD (UNIQUE )?(INDEX|KEY)\b`),
+			exceptions:      regexp.MustCompile(`(?i)\bCONCURRENTLY\b|\bLOCK ?= ?NONE\b`),
+		},
+	}
+}
+
+// GetLintRuleNames returns the sorted names of the given rules.
+func GetLintRuleNames(rules []LintRule) []string {
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, rule.GetName())
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func isLintRuleDefined(rules []LintRule, ruleName string) bool {
+	for _, rule := range rules {
+		if rule.GetName() == ruleName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isLintSeverityValid(severity string) bool {
+	for _, validSeverity := range validLintSeverities {
+		if validSeverity == severity {
+			return true
+		}
+	}
+
+	return false
+}
+
+func abbreviate(statement string) string {
+	const maxLength = 60
+	if len(statement) <= maxLength {
+		return statement
+	}
+
+	return statement[:maxLength] + "..."
+}
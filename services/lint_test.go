@@ -0,0 +1,140 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestLintingDangerousStatements(test *testing.T) {
+	test.Parallel()
+
+	linter, err := services.NewLintService(&mocks.FileRepository{}, services.DefaultLintRules(), nil)
+	require.Nil(test, err)
+
+	cases := map[string]string{
+		"DROP TABLE gophers;":  "drop-table",
+		"delete from gophers;": "delete-without-where",
+		"ALTER TABLE gophers ADD COLUMN name VARCHAR(255) NOT NULL;":                        "not-null-column-without-default",
+		"CREATE INDEX gophers_name ON gophers (name);":                                      "non-concurrent-index",
+		"CREATE UNIQUE INDEX gophers_name ON gophers (name);":                               "non-concurrent-index",
+		"ALTER TABLE gophers\n  ADD INDEX gophers_name (name);":                             "non-concurrent-index",
+		"SELECT 1;\n/* a comment */ DROP TABLE IF EXISTS golfers; -- x":                     "drop-table",
+		"-- don't keep the legacy table\nDROP TABLE users;\nINSERT INTO t VALUES ('a');":    "drop-table",
+		"/* don't keep the legacy table */\nDROP TABLE users;\nINSERT INTO t VALUES ('a');": "drop-table",
+		"INSERT INTO t VALUES ('-- it''s /* not a comment');\nDROP TABLE users;":            "drop-table",
+	}
+
+	for query, rule := range cases {
+		issues := linter.LintMigration("/tmp/1_a.sql", query)
+
+		require.Len(test, issues, 1, query)
+		assert.Equal(test, rule, issues[0].Rule, query)
+		assert.Equal(test, "/tmp/1_a.sql", issues[0].FilePath, query)
+	}
+}
+
+func TestLintingSafeStatements(test *testing.T) {
+	test.Parallel()
+
+	linter, err := services.NewLintService(&mocks.FileRepository{}, services.DefaultLintRules(), nil)
+	require.Nil(test, err)
+
+	queries := []string{
+		"CREATE TABLE gophers (id INT);",
+		"DELETE FROM gophers WHERE id = 1;",
+		"ALTER TABLE gophers ADD COLUMN name VARCHAR(255) NOT NULL DEFAULT '';",
+		"ALTER TABLE gophers ADD COLUMN name VARCHAR(255) NULL;",
+		"CREATE INDEX CONCURRENTLY gophers_name ON gophers (name);",
+		"CREATE INDEX gophers_name ON gophers (name) ALGORITHM=INPLACE LOCK=NONE;",
+		"INSERT INTO logs (message) VALUES ('DROP TABLE gophers;');",
+		"-- DROP TABLE gophers;\nSELECT 1;",
+	}
+
+	for _, query := range queries {
+		assert.Empty(test, linter.LintMigration("/tmp/1_a.sql", query), query)
+	}
+}
+
+func TestSuppressingLintRulesWithComments(test *testing.T) {
+	test.Parallel()
+
+	linter, err := services.NewLintService(&mocks.FileRepository{}, services.DefaultLintRules(), nil)
+	require.Nil(test, err)
+
+	query := "-- migrations:lint-ignore drop-table\nDROP TABLE gophers;\nDELETE FROM golfers;"
+	issues := linter.LintMigration("/tmp/1_a.sql", query)
+	require.Len(test, issues, 1)
+	assert.Equal(test, "delete-without-where", issues[0].Rule)
+
+	query = "-- migrations:lint-ignore\nDROP TABLE gophers;\nDELETE FROM golfers;"
+	assert.Empty(test, linter.LintMigration("/tmp/1_a.sql", query))
+}
+
+func TestConfiguringLintSeverities(test *testing.T) {
+	test.Parallel()
+
+	severities := map[string]string{
+		"drop-table":           services.LintSeverityWarning,
+		"delete-without-where": services.LintSeverityOff,
+	}
+	linter, err := services.NewLintService(&mocks.FileRepository{}, services.DefaultLintRules(), severities)
+	require.Nil(test, err)
+
+	issues := linter.LintMigration("/tmp/1_a.sql", "DROP TABLE gophers; DELETE FROM golfers;")
+
+	require.Len(test, issues, 1)
+	assert.Equal(test, services.LintSeverityWarning, issues[0].Severity)
+	assert.False(test, services.HasLintErrors(issues))
+}
+
+func TestConfiguringAnUnknownLintRule(test *testing.T) {
+	test.Parallel()
+
+	severities := map[string]string{"oops": services.LintSeverityOff}
+	_, err := services.NewLintService(&mocks.FileRepository{}, services.DefaultLintRules(), severities)
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestLintingAMigrationsDirectory(test *testing.T) {
+	test.Parallel()
+
+	fileRepository := &mocks.FileRepository{}
+	defer fileRepository.AssertExpectations(test)
+	fileRepository.On("GetMigrationFilePaths", "/tmp/").Return([]string{"/tmp/1_a.sql", "/tmp/2_b.sql"}, nil)
+	fileRepository.On("GetMigrationQuery", "/tmp/1_a.sql").Return("CREATE TABLE gophers (id INT);", nil)
+	fileRepository.On("GetMigrationQuery", "/tmp/2_b.sql").Return("DROP TABLE gophers;", nil)
+
+	linter, err := services.NewLintService(fileRepository, services.DefaultLintRules(), nil)
+	require.Nil(test, err)
+
+	issues, err := linter.Lint("/tmp/")
+
+	require.Nil(test, err)
+	require.Len(test, issues, 1)
+	assert.Equal(test, "/tmp/2_b.sql", issues[0].FilePath)
+	assert.True(test, services.HasLintErrors(issues))
+}
+
+func TestParsingLintSeverities(test *testing.T) {
+	test.Parallel()
+
+	severities, err := services.ParseLintSeverities("drop-table=warning, non-concurrent-index=off")
+
+	require.Nil(test, err)
+	assert.Equal(test, map[string]string{
+		"drop-table":           services.LintSeverityWarning,
+		"non-concurrent-index": services.LintSeverityOff,
+	}, severities)
+
+	_, err = services.ParseLintSeverities("drop-table")
+	assert.NotNil(test, err)
+
+	_, err = services.ParseLintSeverities("drop-table=fatal")
+	assert.NotNil(test, err)
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/models"
+)
+
+// Metrics collects measurements about migration runs.
+type Metrics interface {
+	SetPendingMigrations(count int)
+	ObserveMigration(migration models.Migration, duration time.Duration)
+}
+
+type nilMetrics struct{}
+
+// Ensure nilMetrics implements Metrics.
+var _ Metrics = nilMetrics{}
+
+func (metrics nilMetrics) SetPendingMigrations(count int) {}
+
+func (metrics nilMetrics) ObserveMigration(migration models.Migration, duration time.Duration) {}
+
+// DefaultDurationBuckets are the upper bounds (in seconds) of the migration duration histogram.
+var DefaultDurationBuckets = []float64{0.1, 0.5, 1, 5, 15, 30, 60, 300, 900, 1800, 3600}
+
+// MetricsCollector is an implementation of Metrics that keeps the measurements in memory,
+// so they can be exported in the Prometheus text exposition format.
+type MetricsCollector struct {
+	mutex            sync.Mutex
+	applied          uint64
+	failed           uint64
+	pending          int
+	lastAppliedOrder uint64
+	buckets          []float64
+	bucketCounts     []uint64
+	durationSum      float64
+	durationCount    uint64
+}
+
+// Ensure MetricsCollector implements Metrics.
+var _ Metrics = &MetricsCollector{}
+
+// NewMetricsCollector returns a MetricsCollector using DefaultDurationBuckets.
+func NewMetricsCollector() *MetricsCollector {
+	return NewMetricsCollectorWithBuckets(DefaultDurationBuckets)
+}
+
+// NewMetricsCollectorWithBuckets returns a MetricsCollector using the given histogram buckets (in seconds).
+func NewMetricsCollectorWithBuckets(buckets []float64) *MetricsCollector {
+	sortedBuckets := make([]float64, len(buckets))
+	copy(sortedBuckets, buckets)
+	sort.Float64s(sortedBuckets)
+
+	return &MetricsCollector{
+		buckets:      sortedBuckets,
+		bucketCounts: make([]uint64, len(sortedBuckets)),
+	}
+}
+
+// SetPendingMigrations sets the amount of migrations that have not been run yet.
+func (collector *MetricsCollector) SetPendingMigrations(count int) {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	collector.pending = count
+}
+
+// ObserveMigration records the result and the duration of a run migration.
+func (collector *MetricsCollector) ObserveMigration(migration models.Migration, duration time.Duration) {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	if migration.HasFailed() {
+		collector.failed++
+	}
+
+	if migration.WasSuccessful() {
+		collector.applied++
+		if migration.GetOrder() > collector.lastAppliedOrder {
+			collector.lastAppliedOrder = migration.GetOrder()
+		}
+	}
+
+	seconds := duration.Seconds()
+	for index, upperBound := range collector.buckets {
+		if seconds <= upperBound {
+			collector.bucketCounts[index]++
+		}
+	}
+	collector.durationSum += seconds
+	collector.durationCount++
+}
+
+// WritePrometheus writes the collected metrics to the given writer using the Prometheus text exposition format.
+func (collector *MetricsCollector) WritePrometheus(writer io.Writer) error {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	output := &metricsWriter{writer: writer}
+
+	output.header("migrations_applied_total", "counter", "Number of migrations run successfully.")
+	output.line("migrations_applied_total %d\n", collector.applied)
+
+	output.header("migrations_failed_total", "counter", "Number of migrations that failed.")
+	output.line("migrations_failed_total %d\n", collector.failed)
+
+	output.header("migrations_duration_seconds", "histogram", "Duration of each migration run.")
+	for index, upperBound := range collector.buckets {
+		output.line(
+			"migrations_duration_seconds_bucket{le=\"%s\"} %d\n",
+			formatFloat(upperBound),
+			collector.bucketCounts[index],
+		)
+	}
+	output.line("migrations_duration_seconds_bucket{le=\"+Inf\"} %d\n", collector.durationCount)
+	output.line("migrations_duration_seconds_sum %s\n", formatFloat(collector.durationSum))
+	output.line("migrations_duration_seconds_count %d\n", collector.durationCount)
+
+	output.header("migrations_pending", "gauge", "Number of migrations that have not been run yet.")
+	output.line("migrations_pending %d\n", collector.pending)
+
+	output.header("migrations_last_applied_order", "gauge", "Order of the last migration run successfully.")
+	output.line("migrations_last_applied_order %d\n", collector.lastAppliedOrder)
+
+	return errors.Wrap(output.err, "failed to write metrics")
+}
+
+// WriteTextFile writes the collected metrics to the given file path (to be used with the node exporter's textfile
+// collector). The file is written atomically, so the collector never reads a partial file.
+func (collector *MetricsCollector) WriteTextFile(filePath string) (err error) {
+	temporaryFile, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create a temporary metrics file for [%s]", filePath)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(temporaryFile.Name())
+		}
+	}()
+
+	err = collector.WritePrometheus(temporaryFile)
+	if closeErr := temporaryFile.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to write the metrics file [%s]", filePath)
+	}
+
+	err = os.Chmod(temporaryFile.Name(), 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write the metrics file [%s]", filePath)
+	}
+
+	err = os.Rename(temporaryFile.Name(), filePath)
+
+	return errors.Wrapf(err, "failed to write the metrics file [%s]", filePath)
+}
+
+// metricsWriter keeps the first error that occurs while writing, so the output can be written without
+// checking errors on every line.
+type metricsWriter struct {
+	writer io.Writer
+	err    error
+}
+
+func (output *metricsWriter) header(name, metricType, help string) {
+	output.line("# HELP %s %s\n", name, help)
+	output.line("# TYPE %s %s\n", name, metricType)
+}
+
+func (output *metricsWriter) line(format string, a ...interface{}) {
+	if output.err != nil {
+		return
+	}
+
+	_, output.err = fmt.Fprintf(output.writer, format, a...)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
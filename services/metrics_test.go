@@ -0,0 +1,91 @@
+package services_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestWritingMetricsWithoutObservations(test *testing.T) {
+	test.Parallel()
+
+	collector := services.NewMetricsCollector()
+	output := &bytes.Buffer{}
+
+	err := collector.WritePrometheus(output)
+
+	require.Nil(test, err)
+	assert.Contains(test, output.String(), "# TYPE migrations_applied_total counter\nmigrations_applied_total 0\n")
+	assert.Contains(test, output.String(), "# TYPE migrations_failed_total counter\nmigrations_failed_total 0\n")
+	assert.Contains(test, output.String(), "migrations_duration_seconds_count 0\n")
+	assert.Contains(test, output.String(), "migrations_pending 0\n")
+	assert.Contains(test, output.String(), "migrations_last_applied_order 0\n")
+}
+
+func TestWritingMetricsAfterObservingMigrations(test *testing.T) {
+	test.Parallel()
+
+	collector := services.NewMetricsCollectorWithBuckets([]float64{10, 1})
+
+	successful, err := models.NewMigration("/tmp/2_b.sql", "SELECT 1", models.StatusSuccessful)
+	require.Nil(test, err)
+	collector.ObserveMigration(successful, 500*time.Millisecond)
+
+	failed, err := models.NewMigration("/tmp/3_c.sql", "SELECT 1", models.StatusNotRun)
+	require.Nil(test, err)
+	collector.ObserveMigration(failed.NewAsFailed(errors.New("oops")), 2*time.Second)
+
+	collector.SetPendingMigrations(1)
+
+	output := &bytes.Buffer{}
+	err = collector.WritePrometheus(output)
+
+	require.Nil(test, err)
+	assert.Contains(test, output.String(), "migrations_applied_total 1\n")
+	assert.Contains(test, output.String(), "migrations_failed_total 1\n")
+	assert.Contains(test, output.String(), "# TYPE migrations_duration_seconds histogram\n")
+	assert.Contains(test, output.String(), "migrations_duration_seconds_bucket{le=\"1\"} 1\n"+
+		"migrations_duration_seconds_bucket{le=\"10\"} 2\n"+
+		"migrations_duration_seconds_bucket{le=\"+Inf\"} 2\n"+
+		"migrations_duration_seconds_sum 2.5\n"+
+		"migrations_duration_seconds_count 2\n")
+	assert.Contains(test, output.String(), "migrations_pending 1\n")
+	assert.Contains(test, output.String(), "migrations_last_applied_order 2\n")
+}
+
+func TestWritingMetricsToATextFile(test *testing.T) {
+	test.Parallel()
+
+	collector := services.NewMetricsCollector()
+	filePath := filepath.Join(test.TempDir(), "migrations.prom")
+
+	err := collector.WriteTextFile(filePath)
+	require.Nil(test, err)
+
+	contents, err := ioutil.ReadFile(filePath)
+	require.Nil(test, err)
+	assert.Contains(test, string(contents), "migrations_applied_total 0\n")
+
+	files, err := ioutil.ReadDir(filepath.Dir(filePath))
+	require.Nil(test, err)
+	assert.Len(test, files, 1)
+}
+
+func TestWritingMetricsToATextFileFailsIfTheDirectoryDoesNotExist(test *testing.T) {
+	test.Parallel()
+
+	collector := services.NewMetricsCollector()
+
+	err := collector.WriteTextFile(filepath.Join(test.TempDir(), "missing", "migrations.prom"))
+
+	assert.NotNil(test, err)
+}
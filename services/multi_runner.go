@@ -0,0 +1,168 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/models"
+)
+
+// DefaultConcurrency is the number of targets a MultiRunner runs migrations on at the same time by default.
+const DefaultConcurrency = 1
+
+// RunTarget is a DB (a tenant database or schema) to run the migrations on, with the Runner to do it.
+type RunTarget struct {
+	Name   string
+	Runner Runner
+}
+
+// TargetResult is the result of running the migrations on a RunTarget. Err is the error returned by its Runner or,
+// if none, a MigrationError if any of its migrations failed.
+type TargetResult struct {
+	Migrations models.Collection
+	Err        error
+}
+
+// MultiRunResult holds the result of running the migrations on every target.
+type MultiRunResult struct {
+	// TargetNames are the names of the targets, in the order they were given.
+	TargetNames []string
+	Results     map[string]TargetResult
+}
+
+// GetCollections returns the migrations run on each target, mapped by target name.
+func (result MultiRunResult) GetCollections() map[string]models.Collection {
+	collections := make(map[string]models.Collection, len(result.Results))
+	for name, targetResult := range result.Results {
+		collections[name] = targetResult.Migrations
+	}
+
+	return collections
+}
+
+// GetFailedTargetNames returns the names of the targets that failed, in the order they were given.
+func (result MultiRunResult) GetFailedTargetNames() []string {
+	var names []string
+	for _, name := range result.TargetNames {
+		if result.Results[name].Err != nil {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Err returns nil if every target succeeded or, otherwise, the error of the first failed target (so ExitCode returns
+// its category) with the number of failed targets.
+func (result MultiRunResult) Err() error {
+	failedTargetNames := result.GetFailedTargetNames()
+	if len(failedTargetNames) == 0 {
+		return nil
+	}
+
+	return errors.Wrapf(
+		result.Results[failedTargetNames[0]].Err,
+		"%d of %d target(s) failed, first [%s]",
+		len(failedTargetNames),
+		len(result.TargetNames),
+		failedTargetNames[0],
+	)
+}
+
+// MultiRunner runs the same migrations on several targets.
+type MultiRunner interface {
+	RunMigrations(targets []RunTarget) (MultiRunResult, error)
+}
+
+type multiRunnerService struct {
+	concurrency int
+}
+
+// Ensure multiRunnerService implements MultiRunner.
+var _ MultiRunner = multiRunnerService{}
+
+// NewMultiRunnerService returns an implementation of MultiRunner that runs migrations on up to concurrency targets at
+// the same time (DefaultConcurrency if it's not positive).
+func NewMultiRunnerService(concurrency int) MultiRunner {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	return multiRunnerService{
+		concurrency: concurrency,
+	}
+}
+
+// RunMigrations runs the migrations on every target. A failure on a target doesn't stop the others (see
+// MultiRunResult.Err). An error is only returned if the targets are invalid, before running anything.
+func (service multiRunnerService) RunMigrations(targets []RunTarget) (MultiRunResult, error) {
+	err := validateRunTargets(targets)
+	if err != nil {
+		return MultiRunResult{}, err
+	}
+
+	result := MultiRunResult{
+		TargetNames: make([]string, len(targets)),
+		Results:     make(map[string]TargetResult, len(targets)),
+	}
+
+	var mutex sync.Mutex
+	var waitGroup sync.WaitGroup
+	slots := make(chan struct{}, service.concurrency)
+	for index, target := range targets {
+		result.TargetNames[index] = target.Name
+		waitGroup.Add(1)
+		slots <- struct{}{}
+
+		go func(target RunTarget) {
+			defer waitGroup.Done()
+			defer func() { <-slots }()
+
+			targetResult := runTarget(target)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			result.Results[target.Name] = targetResult
+		}(target)
+	}
+
+	waitGroup.Wait()
+
+	return result, nil
+}
+
+func validateRunTargets(targets []RunTarget) error {
+	if len(targets) == 0 {
+		return NewArgumentsError(errors.New("there are no targets to run the migrations on"))
+	}
+
+	names := map[string]bool{}
+	for _, target := range targets {
+		if target.Name == "" {
+			return NewArgumentsError(errors.New("every target must have a name"))
+		}
+		if names[target.Name] {
+			return NewArgumentsError(errors.Errorf("the target [%s] is given more than once", target.Name))
+		}
+		names[target.Name] = true
+	}
+
+	return nil
+}
+
+// runTarget runs the migrations on a target, turning a panic into an error so the other targets are not affected.
+func runTarget(target RunTarget) (targetResult TargetResult) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			targetResult.Err = errors.Errorf("running migrations on [%s] panicked: %v", target.Name, recovered)
+		}
+	}()
+
+	migrations, err := target.Runner.RunMigrations()
+	if err == nil {
+		err = ErrorFromRunMigrations(migrations)
+	}
+
+	return TargetResult{Migrations: migrations, Err: err}
+}
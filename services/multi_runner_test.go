@@ -0,0 +1,119 @@
+package services_test
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestRunningMigrationsOnSeveralTargets(test *testing.T) {
+	test.Parallel()
+
+	successfulMigrations := models.Collection{}
+	migration, err := models.NewMigration("/tmp/1_a.sql", "SELECT 1", models.StatusSuccessful)
+	require.Nil(test, err)
+	require.Nil(test, successfulMigrations.Add(migration))
+
+	failedMigrations := models.Collection{}
+	require.Nil(test, failedMigrations.Add(migration.NewAsFailed(fmt.Errorf("syntax error"))))
+
+	tenantA := &mocks.Runner{}
+	defer tenantA.AssertExpectations(test)
+	tenantA.On("RunMigrations").Return(successfulMigrations, nil)
+	tenantB := &mocks.Runner{}
+	defer tenantB.AssertExpectations(test)
+	tenantB.On("RunMigrations").Return(failedMigrations, nil)
+	tenantC := &mocks.Runner{}
+	defer tenantC.AssertExpectations(test)
+	tenantC.On("RunMigrations").Return(models.Collection{}, services.NewDBConnectionError(fmt.Errorf("timeout")))
+	tenantD := &mocks.Runner{}
+	defer tenantD.AssertExpectations(test)
+	tenantD.On("RunMigrations").Return(models.Collection{}, nil)
+
+	service := services.NewMultiRunnerService(2)
+
+	result, err := service.RunMigrations([]services.RunTarget{
+		{Name: "tenant_a", Runner: tenantA},
+		{Name: "tenant_b", Runner: tenantB},
+		{Name: "tenant_c", Runner: tenantC},
+		{Name: "tenant_d", Runner: tenantD},
+	})
+
+	require.Nil(test, err)
+	assert.Equal(test, []string{"tenant_a", "tenant_b", "tenant_c", "tenant_d"}, result.TargetNames)
+	assert.Equal(test, []string{"tenant_b", "tenant_c"}, result.GetFailedTargetNames())
+	tenantACollection := result.GetCollections()["tenant_a"]
+	assert.Len(test, tenantACollection.GetAll(), 1)
+	assert.Equal(test, services.ExitCodeMigrationFailed, services.ExitCode(result.Err()))
+	assert.Contains(test, result.Err().Error(), "2 of 4 target(s) failed, first [tenant_b]")
+	assert.Equal(test, services.ExitCodeDBUnavailable, services.ExitCode(result.Results["tenant_c"].Err))
+	assert.Nil(test, result.Results["tenant_d"].Err)
+}
+
+func TestRunningMigrationsOnSeveralTargetsRespectsTheConcurrency(test *testing.T) {
+	test.Parallel()
+
+	var running, maxRunning int32
+	targets := make([]services.RunTarget, 6)
+	for index := range targets {
+		runner := &mocks.Runner{}
+		runner.On("RunMigrations").Return(func() models.Collection {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+
+			return models.Collection{}
+		}, nil)
+		targets[index] = services.RunTarget{Name: fmt.Sprintf("tenant_%d", index), Runner: runner}
+	}
+
+	result, err := services.NewMultiRunnerService(2).RunMigrations(targets)
+
+	require.Nil(test, err)
+	assert.Nil(test, result.Err())
+	assert.LessOrEqual(test, atomic.LoadInt32(&maxRunning), int32(2))
+}
+
+func TestRunningMigrationsOnSeveralTargetsIsolatesPanics(test *testing.T) {
+	test.Parallel()
+
+	panicking := &mocks.Runner{}
+	panicking.On("RunMigrations").Return(func() models.Collection { panic("boom") }, nil)
+	healthy := &mocks.Runner{}
+	defer healthy.AssertExpectations(test)
+	healthy.On("RunMigrations").Return(models.Collection{}, nil)
+
+	result, err := services.NewMultiRunnerService(1).RunMigrations([]services.RunTarget{
+		{Name: "panicking", Runner: panicking},
+		{Name: "healthy", Runner: healthy},
+	})
+
+	require.Nil(test, err)
+	assert.Equal(test, []string{"panicking"}, result.GetFailedTargetNames())
+	assert.Contains(test, result.Results["panicking"].Err.Error(), "boom")
+}
+
+func TestRunningMigrationsOnTargetsWithTheSameName(test *testing.T) {
+	test.Parallel()
+
+	_, err := services.NewMultiRunnerService(1).RunMigrations([]services.RunTarget{
+		{Name: "tenant", Runner: &mocks.Runner{}},
+		{Name: "tenant", Runner: &mocks.Runner{}},
+	})
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
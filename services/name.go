@@ -0,0 +1,56 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// NameStyleCamelCase formats the name of new migrations as camelCase (e.g. createGophersTable).
+	NameStyleCamelCase = "camel"
+	// NameStyleSnakeCase formats the name of new migrations as snake_case (e.g. create_gophers_table).
+	NameStyleSnakeCase = "snake"
+)
+
+var validNameStyles = []string{NameStyleCamelCase, NameStyleSnakeCase}
+
+// SanitizeMigrationName turns the name of a new migration into a safe file name part using a style (NameStyle*
+// constants): words are split on spaces, punctuation and case changes, and the .sql extension is removed.
+// Names with path separators and names without letters or digits are rejected.
+func SanitizeMigrationName(name string, style string) (string, error) {
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return "", NewArgumentsError(errors.Errorf(
+			"invalid 'name' option: [%s] (it cannot contain path separators or '..')",
+			name,
+		))
+	}
+
+	trimmedName := strings.TrimSpace(name)
+	if strings.HasSuffix(strings.ToLower(trimmedName), ".sql") {
+		trimmedName = trimmedName[:len(trimmedName)-len(".sql")]
+	}
+
+	words := splitWords(trimmedName)
+	if len(words) == 0 {
+		return "", NewArgumentsError(errors.Errorf(
+			"invalid 'name' option: [%s] (it must contain letters or digits)",
+			name,
+		))
+	}
+
+	if style == NameStyleSnakeCase {
+		return strings.Join(words, "_"), nil
+	}
+
+	for index := 1; index < len(words); index++ {
+		characters := []rune(words[index])
+		words[index] = strings.ToUpper(string(characters[0])) + string(characters[1:])
+	}
+
+	return strings.Join(words, ""), nil
+}
+
+func isNameStyleValid(style string) bool {
+	return containsString(validNameStyles, style)
+}
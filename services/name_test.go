@@ -0,0 +1,59 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestSanitizingMigrationNames(test *testing.T) {
+	test.Parallel()
+
+	cases := []struct {
+		name          string
+		style         string
+		sanitizedName string
+	}{
+		{"createGophersTable", services.NameStyleCamelCase, "createGophersTable"},
+		{"createGophersTable", services.NameStyleSnakeCase, "create_gophers_table"},
+		{"create gophers table", services.NameStyleCamelCase, "createGophersTable"},
+		{"  create-gophers  table ", services.NameStyleSnakeCase, "create_gophers_table"},
+		{"create_gophers_table.sql", services.NameStyleCamelCase, "createGophersTable"},
+		{"addURLToUsers", services.NameStyleSnakeCase, "add_url_to_users"},
+		{"add email (v2)!", services.NameStyleSnakeCase, "add_email_v2"},
+		{"a.sql", services.NameStyleCamelCase, "a"},
+		{"x.SQL", services.NameStyleSnakeCase, "x"},
+		{"crear tabla año", services.NameStyleCamelCase, "crearTablaAño"},
+	}
+
+	for _, testCase := range cases {
+		sanitizedName, err := services.SanitizeMigrationName(testCase.name, testCase.style)
+
+		require.Nil(test, err, testCase.name)
+		assert.Equal(test, testCase.sanitizedName, sanitizedName, testCase.name)
+	}
+}
+
+func TestSanitizingInvalidMigrationNames(test *testing.T) {
+	test.Parallel()
+
+	names := []string{"", "   ", ".sql", "---", "../createGophersTable", "migrations/createGophersTable", `a\b`, "a..b"}
+
+	for _, name := range names {
+		_, err := services.SanitizeMigrationName(name, services.NameStyleCamelCase)
+
+		assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err), name)
+	}
+}
+
+func TestTheErrorOfAnInvalidMigrationNameShowsTheGivenName(test *testing.T) {
+	test.Parallel()
+
+	_, err := services.SanitizeMigrationName(".sql", services.NameStyleCamelCase)
+
+	require.NotNil(test, err)
+	assert.Contains(test, err.Error(), "[.sql]")
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/helpers"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+)
+
+const (
+	// NumberingTimestamp prefixes new migrations with the current Unix time in nanoseconds.
+	NumberingTimestamp = "timestamp"
+	// NumberingSequential prefixes new migrations with the highest order on the migrations directories plus one
+	// (e.g. 0042_addUsers.sql).
+	NumberingSequential = "sequential"
+)
+
+// DefaultNumberingPadding is the default length of sequential prefixes (padded with zeros).
+const DefaultNumberingPadding = 4
+
+var validNumberingModes = []string{NumberingTimestamp, NumberingSequential}
+
+// Numberer picks the order of new migrations.
+type Numberer interface {
+	// GetNextOrder returns the order of a new migration and the prefix of its file name.
+	GetNextOrder(migrationsDirectoryAbsolutePath string, now time.Time) (order uint64, prefix string, err error)
+}
+
+type numberingService struct {
+	fileRepository                   repositories.FileRepository
+	fileSystem                       adapters.FileSystem
+	mode                             string
+	padding                          uint64
+	additionalDirectoryAbsolutePaths []string
+}
+
+// Ensure numberingService implements Numberer.
+var _ Numberer = numberingService{}
+
+// NewNumberingService returns an implementation of Numberer using a numbering mode (Numbering* constants). The padding
+// is only used by NumberingSequential, which also takes the orders of the additional directories (given as
+// {source}={path} or just a path, see GetMigrationSource) into account, since orders are unique across directories.
+func NewNumberingService(
+	fileRepository repositories.FileRepository,
+	fileSystem adapters.FileSystem,
+	mode string,
+	padding uint64,
+	additionalDirectoryAbsolutePaths ...string,
+) Numberer {
+	return numberingService{
+		fileRepository:                   fileRepository,
+		fileSystem:                       fileSystem,
+		mode:                             mode,
+		padding:                          padding,
+		additionalDirectoryAbsolutePaths: additionalDirectoryAbsolutePaths,
+	}
+}
+
+// GetNextOrder returns the order of a new migration and the prefix of its file name.
+func (service numberingService) GetNextOrder(
+	migrationsDirectoryAbsolutePath string,
+	now time.Time,
+) (uint64, string, error) {
+	if service.mode != NumberingSequential {
+		order := uint64(now.UnixNano())
+		return order, fmt.Sprintf("%d", order), nil
+	}
+
+	directories := []string{helpers.AddTrailingSlashToPathIfNeeded(migrationsDirectoryAbsolutePath)}
+	for _, directory := range service.additionalDirectoryAbsolutePaths {
+		directories = append(directories, helpers.AddTrailingSlashToPathIfNeeded(GetMigrationsDirectoryPath(directory)))
+	}
+
+	var maxOrder uint64
+	for _, directory := range directories {
+		directoryMaxOrder, err := service.getMaxOrder(directory)
+		if err != nil {
+			return 0, "", err
+		}
+
+		if directoryMaxOrder > maxOrder {
+			maxOrder = directoryMaxOrder
+		}
+	}
+
+	order := maxOrder + 1
+	for _, directory := range directories {
+		err := service.checkCollisions(directory, order)
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
+	return order, fmt.Sprintf("%0*d", int(service.padding), order), nil
+}
+
+// getMaxOrder returns the highest order of the migrations of a directory (0 if it has none).
+func (service numberingService) getMaxOrder(migrationsDirectoryAbsolutePath string) (uint64, error) {
+	filePaths, err := service.fileRepository.GetMigrationFilePaths(migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return 0, err
+	}
+
+	var maxOrder uint64
+	for _, filePath := range filePaths {
+		migration, err := models.NewMigration(filePath, "", models.StatusNotRun)
+		if err != nil {
+			return 0, errors.Wrap(err, "cannot pick the next sequential order")
+		}
+
+		if migration.GetOrder() > maxOrder {
+			maxOrder = migration.GetOrder()
+		}
+	}
+
+	return maxOrder, nil
+}
+
+// checkCollisions fails if any other file on the migrations directory (e.g. a down migration without its migration,
+// or a file with another extension) already uses the order.
+func (service numberingService) checkCollisions(migrationsDirectoryAbsolutePath string, order uint64) error {
+	files, err := service.fileSystem.ReadDir(migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return errors.Wrapf(
+			err,
+			"could not read files from the migrations directory [%s]",
+			migrationsDirectoryAbsolutePath,
+		)
+	}
+
+	for _, file := range files {
+		fileOrder, err := strconv.ParseUint(strings.SplitN(file.Name(), "_", 2)[0], 10, 64)
+		if err == nil && fileOrder == order {
+			return errors.Errorf(
+				"the order %d is already used by [%s] on the migrations directory",
+				order,
+				migrationsDirectoryAbsolutePath+file.Name(),
+			)
+		}
+	}
+
+	return nil
+}
+
+func isNumberingModeValid(mode string) bool {
+	return containsString(validNumberingModes, mode)
+}
@@ -0,0 +1,92 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func getNumberingService(mode string, padding uint64, additionalDirectories ...string) services.Numberer {
+	return services.NewNumberingService(
+		repositories.NewFileRepository(adapters.IOUtilAdapter{}),
+		adapters.IOUtilAdapter{},
+		mode,
+		padding,
+		additionalDirectories...,
+	)
+}
+
+func TestGettingATimestampOrder(test *testing.T) {
+	test.Parallel()
+
+	now := time.Unix(1627676712, 447528000)
+
+	order, prefix, err := getNumberingService(services.NumberingTimestamp, 4).GetNextOrder(test.TempDir(), now)
+
+	require.Nil(test, err)
+	assert.Equal(test, uint64(1627676712447528000), order)
+	assert.Equal(test, "1627676712447528000", prefix)
+}
+
+func TestGettingASequentialOrder(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "0001_createGophersTable.sql", "SELECT 1;")
+	writeFile(test, directory, "0041_createGolfersTable.sql", "SELECT 1;")
+	writeFile(test, directory, "0041_createGolfersTable.down.sql", "SELECT 1;")
+	writeFile(test, directory, "README.md", "# Migrations")
+
+	order, prefix, err := getNumberingService(services.NumberingSequential, 4).GetNextOrder(directory, time.Now())
+
+	require.Nil(test, err)
+	assert.Equal(test, uint64(42), order)
+	assert.Equal(test, "0042", prefix)
+}
+
+func TestGettingASequentialOrderWithSeveralDirectories(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "0001_createGophersTable.sql", "SELECT 1;")
+	pluginDirectory := test.TempDir()
+	writeFile(test, pluginDirectory, "0007_createGolfersTable.sql", "SELECT 1;")
+	billingDirectory := test.TempDir()
+	writeFile(test, billingDirectory, "0003_createInvoicesTable.sql", "SELECT 1;")
+
+	numberer := getNumberingService(services.NumberingSequential, 4, pluginDirectory, "billing="+billingDirectory)
+	order, prefix, err := numberer.GetNextOrder(directory, time.Now())
+
+	require.Nil(test, err)
+	assert.Equal(test, uint64(8), order)
+	assert.Equal(test, "0008", prefix)
+}
+
+func TestGettingTheFirstSequentialOrder(test *testing.T) {
+	test.Parallel()
+
+	order, prefix, err := getNumberingService(services.NumberingSequential, 0).GetNextOrder(test.TempDir(), time.Now())
+
+	require.Nil(test, err)
+	assert.Equal(test, uint64(1), order)
+	assert.Equal(test, "1", prefix)
+}
+
+func TestGettingASequentialOrderFailsIfAnotherFileUsesIt(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "0001_createGophersTable.sql", "SELECT 1;")
+	writeFile(test, directory, "0002_createGolfersTable.down.sql", "SELECT 1;")
+
+	_, _, err := getNumberingService(services.NumberingSequential, 4).GetNextOrder(directory, time.Now())
+
+	require.NotNil(test, err)
+	assert.Contains(test, err.Error(), "0002_createGolfersTable.down.sql")
+}
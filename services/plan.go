@@ -0,0 +1,94 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/helpers"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+)
+
+// Planner renders the migrations that would be run as a single SQL script.
+type Planner interface {
+	GetPlan() (string, error)
+}
+
+type planService struct {
+	migrationFetcherService         Fetcher
+	dbRepository                    repositories.DBRepository
+	dialect                         string
+	migrationsDirectoryAbsolutePath string
+}
+
+// Ensure planService implements Planner.
+var _ Planner = planService{}
+
+// NewPlanService returns an implementation of Planner. The dialect is the one of the DB (see
+// repositories.NewDBRepositoryForDialect), used to render the statement that creates the migrations table.
+func NewPlanService(
+	migrationFetcherService Fetcher,
+	DBRepository repositories.DBRepository,
+	dialect string,
+	migrationsDirectoryAbsolutePath string,
+) Planner {
+	return planService{
+		migrationFetcherService:         migrationFetcherService,
+		dbRepository:                    DBRepository,
+		dialect:                         dialect,
+		migrationsDirectoryAbsolutePath: helpers.AddTrailingSlashToPathIfNeeded(migrationsDirectoryAbsolutePath),
+	}
+}
+
+const planSeparator = "-- ---------------------------------------------------------------------------\n"
+
+// GetPlan returns a script with the contents of every migration that has not been run yet (in order), each one
+// followed by the statement that registers it on the migrations table. The script starts by creating the migrations
+// table if it doesn't exist. It doesn't modify the DB: without a migrations table, every migration is pending.
+func (service planService) GetPlan() (string, error) {
+	err := service.dbRepository.Ping()
+	if err != nil {
+		return "", NewDBConnectionError(errors.Wrap(err, "failed to connect to the DB"))
+	}
+
+	allMigrations, err := service.migrationFetcherService.GetMigrations(service.migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return "", err
+	}
+
+	return renderPlan(service.dialect, allMigrations.GetMigrationsToRun()), nil
+}
+
+func renderPlan(dialect string, migrationsToRun []models.Migration) string {
+	plan := &strings.Builder{}
+	plan.WriteString("-- Migration plan generated by github.com/jimenezmaximiliano/migrations\n")
+	plan.WriteString(fmt.Sprintf("-- Migrations to run: %d\n", len(migrationsToRun)))
+	plan.WriteString("\n")
+	plan.WriteString(repositories.GetCreateMigrationsTableScript(dialect))
+	plan.WriteString("\n")
+
+	for index, migration := range migrationsToRun {
+		plan.WriteString("\n")
+		plan.WriteString(planSeparator)
+		plan.WriteString(fmt.Sprintf(
+			"-- Migration %d/%d: %s\n",
+			index+1,
+			len(migrationsToRun),
+			models.GetQualifiedName(migration),
+		))
+		plan.WriteString(planSeparator)
+		plan.WriteString("\n")
+		query := strings.TrimSpace(migration.GetQuery())
+		if query != "" && !strings.HasSuffix(query, ";") {
+			query += ";"
+		}
+		plan.WriteString(query)
+		plan.WriteString("\n\n")
+		plan.WriteString(repositories.GetRegisterRunMigrationScript(models.GetQualifiedName(migration)))
+		plan.WriteString("\n")
+	}
+
+	return plan.String()
+}
@@ -0,0 +1,109 @@
+package services_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestGettingAPlan(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	collection := models.Collection{}
+	alreadyRun, _ := models.NewMigration("/tmp/1_a.sql", "SELECT 1;", models.StatusSuccessful)
+	require.Nil(test, collection.Add(alreadyRun))
+	migration2, _ := models.NewMigration("/tmp/2_b.sql", "SELECT 2;\n", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration2))
+	migration3, _ := models.NewMigration("/tmp/3_c.sql", "SELECT 3;", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration3))
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+
+	service := services.NewPlanService(fetcher, db, repositories.DialectSQLite, "/tmp")
+
+	plan, err := service.GetPlan()
+
+	require.Nil(test, err)
+	assert.Contains(test, plan, "-- Migrations to run: 2\n")
+	createTable := "CREATE TABLE IF NOT EXISTS migrations (id INTEGER PRIMARY KEY AUTOINCREMENT, migration TEXT);"
+	assert.Contains(test, plan, "\n"+createTable+"\n")
+	assert.Less(test, strings.Index(plan, "CREATE TABLE"), strings.Index(plan, "2_b.sql"))
+	assert.NotContains(test, plan, "1_a.sql")
+	assert.Contains(test, plan, "-- Migration 1/2: 2_b.sql\n")
+	assert.Contains(test, plan, "SELECT 2;\n\nINSERT INTO migrations (migration) VALUES ('2_b.sql');\n")
+	assert.Contains(test, plan, "-- Migration 2/2: 3_c.sql\n")
+	assert.Contains(test, plan, "SELECT 3;\n\nINSERT INTO migrations (migration) VALUES ('3_c.sql');\n")
+	assert.Less(test, strings.Index(plan, "2_b.sql"), strings.Index(plan, "3_c.sql"))
+}
+
+func TestTheStatementsOfAPlanAreTerminated(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	collection := models.Collection{}
+	migration, _ := models.NewMigration("/tmp/1_a.sql", "UPDATE a SET b = 1 \n\n", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration))
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+
+	service := services.NewPlanService(fetcher, db, repositories.DialectSQLite, "/tmp")
+
+	plan, err := service.GetPlan()
+
+	require.Nil(test, err)
+	assert.Contains(test, plan, "UPDATE a SET b = 1;\n\nINSERT INTO migrations (migration) VALUES ('1_a.sql');\n")
+}
+
+func TestGettingAPlanWithoutMigrationsToRun(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	fetcher.On("GetMigrations", "/tmp/").Return(models.Collection{}, nil)
+
+	service := services.NewPlanService(fetcher, db, repositories.DialectSQLite, "/tmp")
+
+	plan, err := service.GetPlan()
+
+	require.Nil(test, err)
+	assert.Contains(test, plan, "-- Migrations to run: 0\n")
+	assert.NotContains(test, plan, "INSERT")
+}
+
+func TestGettingAPlanFailsIfTheDBConnectionDoesNotWork(test *testing.T) {
+	test.Parallel()
+
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(fmt.Errorf("db connection error"))
+
+	service := services.NewPlanService(fetcher, db, repositories.DialectSQLite, "/tmp")
+
+	_, err := service.GetPlan()
+
+	assert.Equal(test, services.ExitCodeDBUnavailable, services.ExitCode(err))
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"time"
+
+	"github.com/jimenezmaximiliano/migrations/models"
+)
+
+// DefaultHeartbeatInterval is how often the command reports a migration that is still running.
+const DefaultHeartbeatInterval = 10 * time.Second
+
+// Progress is notified while migrations are being run, before the final result is available.
+type Progress interface {
+	MigrationStarted(migration models.Migration)
+	MigrationStillRunning(migration models.Migration, elapsed time.Duration)
+	MigrationFinished(migration models.Migration, duration time.Duration)
+}
+
+type nilProgress struct{}
+
+// Ensure nilProgress implements Progress.
+var _ Progress = nilProgress{}
+
+func (progress nilProgress) MigrationStarted(migration models.Migration) {}
+
+func (progress nilProgress) MigrationStillRunning(migration models.Migration, elapsed time.Duration) {
+}
+
+func (progress nilProgress) MigrationFinished(migration models.Migration, duration time.Duration) {}
+
+// DisplayProgress is an implementation of Progress that outputs the progress using an ExtendedDisplay.
+type DisplayProgress struct {
+	display ExtendedDisplay
+}
+
+// Ensure DisplayProgress implements Progress.
+var _ Progress = DisplayProgress{}
+
+// NewDisplayProgress returns a DisplayProgress.
+func NewDisplayProgress(display ExtendedDisplay) DisplayProgress {
+	return DisplayProgress{
+		display: display,
+	}
+}
+
+// MigrationStarted announces a migration that is about to be run.
+func (progress DisplayProgress) MigrationStarted(migration models.Migration) {
+	progress.display.DisplayMigrationStarted(migration)
+}
+
+// MigrationStillRunning reports a migration that is taking a while.
+func (progress DisplayProgress) MigrationStillRunning(migration models.Migration, elapsed time.Duration) {
+	progress.display.DisplayMigrationStillRunning(migration, elapsed)
+}
+
+// MigrationFinished does nothing, since the result of every migration is displayed at the end of the run.
+func (progress DisplayProgress) MigrationFinished(migration models.Migration, duration time.Duration) {
+}
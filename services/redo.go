@@ -0,0 +1,176 @@
+package services
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/helpers"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+)
+
+// RedoResult contains the migrations reverted by Redo and the result of running them again.
+type RedoResult struct {
+	Reverted  models.Collection
+	Reapplied models.Collection
+}
+
+// Redoer reverts the last run migrations and runs them again (meant for local development).
+type Redoer interface {
+	Redo(steps uint64) (RedoResult, error)
+}
+
+type redoService struct {
+	migrationFetcherService         Fetcher
+	fileRepository                  repositories.FileRepository
+	dbRepository                    repositories.DBRepository
+	migrationsDirectoryAbsolutePath string
+}
+
+// Ensure redoService implements Redoer.
+var _ Redoer = redoService{}
+
+// NewRedoService returns an implementation of Redoer.
+func NewRedoService(
+	migrationFetcherService Fetcher,
+	fileRepository repositories.FileRepository,
+	dbRepository repositories.DBRepository,
+	migrationsDirectoryAbsolutePath string,
+) Redoer {
+	return redoService{
+		migrationFetcherService:         migrationFetcherService,
+		fileRepository:                  fileRepository,
+		dbRepository:                    dbRepository,
+		migrationsDirectoryAbsolutePath: helpers.AddTrailingSlashToPathIfNeeded(migrationsDirectoryAbsolutePath),
+	}
+}
+
+// Redo reverts the last steps run migrations (in the reverse order they were run) using their down migrations
+// (see repositories.DownMigrationSuffix), and runs them again. Every down migration is read before reverting anything.
+// It needs a DBRepository that implements repositories.RunMigrationUnregisterer and repositories.RunMigrationsLister.
+func (service redoService) Redo(steps uint64) (RedoResult, error) {
+	result := RedoResult{}
+	unregisterer, err := getRunMigrationUnregisterer(service.dbRepository)
+	if err != nil {
+		return result, err
+	}
+
+	migrations, err := service.getMigrationsToRedo(steps)
+	if err != nil {
+		return result, err
+	}
+
+	downQueries := make([]string, len(migrations))
+	for index, migration := range migrations {
+		downQueries[index], err = repositories.GetDownMigrationQuery(service.fileRepository, migration.GetAbsolutePath())
+		if err != nil {
+			return result, NewArgumentsError(errors.Wrapf(
+				err,
+				"the migration [%s] cannot be reverted",
+				models.GetQualifiedName(migration),
+			))
+		}
+	}
+
+	for index := len(migrations) - 1; index >= 0; index-- {
+		migration := migrations[index]
+		err = service.dbRepository.RunMigrationQuery(downQueries[index])
+		if err != nil {
+			return result, NewMigrationError(migration.NewAsFailed(errors.Wrap(err, "failed to revert the migration")))
+		}
+
+		err = unregisterer.UnregisterRunMigration(models.GetQualifiedName(migration))
+		if err != nil {
+			return result, NewBookkeepingError(err)
+		}
+
+		err = result.Reverted.Add(migration.NewAsNotRun())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, migration := range migrations {
+		err = service.dbRepository.RunMigrationQuery(migration.GetQuery())
+		if err != nil {
+			failedMigration := migration.NewAsFailed(err)
+			_ = result.Reapplied.Add(failedMigration)
+			return result, NewMigrationError(failedMigration)
+		}
+
+		err = service.dbRepository.RegisterRunMigration(models.GetQualifiedName(migration))
+		if err != nil {
+			return result, NewBookkeepingError(err)
+		}
+
+		err = result.Reapplied.Add(migration.NewAsSuccessful())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// getMigrationsToRedo returns the last steps run migrations in the order they were run, which is the order of the
+// migrations table (see repositories.RunMigrationsLister) and not necessarily the one of the files.
+func (service redoService) getMigrationsToRedo(steps uint64) ([]models.Migration, error) {
+	lister, canList := repositories.UnwrapDBRepository(service.dbRepository).(repositories.RunMigrationsLister)
+	if !canList {
+		return nil, NewBookkeepingError(errors.New("the DB repository cannot list the migrations in the order they were run"))
+	}
+
+	err := service.dbRepository.Ping()
+	if err != nil {
+		return nil, NewDBConnectionError(errors.Wrap(err, "failed to connect to the DB"))
+	}
+
+	err = service.dbRepository.CreateMigrationsTableIfNeeded()
+	if err != nil {
+		return nil, NewBookkeepingError(err)
+	}
+
+	allMigrations, err := service.migrationFetcherService.GetMigrations(service.migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return nil, err
+	}
+
+	runMigrations := map[string]models.Migration{}
+	for _, migration := range allMigrations.GetAll() {
+		if !migration.ShouldBeRun() {
+			runMigrations[models.GetQualifiedName(migration)] = migration
+		}
+	}
+
+	if steps == 0 || steps > uint64(len(runMigrations)) {
+		return nil, NewArgumentsError(errors.Errorf(
+			"cannot redo %d migration(s) (%d migration(s) have been run)",
+			steps,
+			len(runMigrations),
+		))
+	}
+
+	runFileNames, err := lister.GetRunMigrationFileNames()
+	if err != nil {
+		return nil, NewBookkeepingError(err)
+	}
+
+	// A migration registered more than once (see the repair command) is redone once, as of its last run.
+	migrations := make([]models.Migration, steps)
+	redone := map[string]bool{}
+	for index := len(runFileNames) - 1; index >= 0 && len(redone) < len(migrations); index-- {
+		fileName := runFileNames[index]
+		if redone[fileName] {
+			continue
+		}
+
+		migration, hasFile := runMigrations[fileName]
+		if !hasFile {
+			return nil, NewArgumentsError(errors.Errorf("the run migration [%s] has no migration file to redo", fileName))
+		}
+
+		redone[fileName] = true
+		migrations[len(migrations)-len(redone)] = migration
+	}
+
+	return migrations, nil
+}
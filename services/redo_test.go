@@ -0,0 +1,199 @@
+package services_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// redoableDBRepository is a DBRepository that implements the optional interfaces needed by Redo.
+type redoableDBRepository struct {
+	*mocks.DBRepository
+	*mocks.RunMigrationUnregisterer
+	*mocks.RunMigrationsLister
+}
+
+func getRunMigrationsLister(fileNames ...string) *mocks.RunMigrationsLister {
+	lister := &mocks.RunMigrationsLister{}
+	if len(fileNames) > 0 {
+		lister.On("GetRunMigrationFileNames").Return(fileNames, nil).Once()
+	}
+
+	return lister
+}
+
+func getRedoFetcher(test *testing.T) *mocks.Fetcher {
+	fetcher := &mocks.Fetcher{}
+	collection := models.Collection{}
+	migration1, _ := models.NewMigration("/tmp/1_a.sql", "SELECT 1", models.StatusSuccessful)
+	require.Nil(test, collection.Add(migration1))
+	migration2, _ := models.NewMigration("/tmp/2_b.sql", "SELECT 2", models.StatusSuccessful)
+	require.Nil(test, collection.Add(migration2))
+	migration3, _ := models.NewMigration("/tmp/3_c.sql", "SELECT 3", models.StatusSuccessful)
+	require.Nil(test, collection.Add(migration3))
+	migration4, _ := models.NewMigration("/tmp/4_d.sql", "SELECT 4", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration4))
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+
+	return fetcher
+}
+
+func TestRedoingMigrations(test *testing.T) {
+	test.Parallel()
+
+	fetcher := getRedoFetcher(test)
+	defer fetcher.AssertExpectations(test)
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	files.On("GetMigrationQuery", "/tmp/2_b.down.sql").Return("UNDO 2", nil)
+	files.On("GetMigrationQuery", "/tmp/3_c.down.sql").Return("UNDO 3", nil)
+
+	var queries []string
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	unregisterer := &mocks.RunMigrationUnregisterer{}
+	defer unregisterer.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("RunMigrationQuery", mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { queries = append(queries, args.String(0)) }).
+		Return(nil)
+	unregisterer.On("UnregisterRunMigration", "3_c.sql").Return(nil)
+	unregisterer.On("UnregisterRunMigration", "2_b.sql").Return(nil)
+	db.On("RegisterRunMigration", "2_b.sql").Return(nil)
+	db.On("RegisterRunMigration", "3_c.sql").Return(nil)
+
+	lister := getRunMigrationsLister("1_a.sql", "2_b.sql", "3_c.sql")
+	defer lister.AssertExpectations(test)
+
+	service := services.NewRedoService(fetcher, files, redoableDBRepository{db, unregisterer, lister}, "/tmp")
+
+	result, err := service.Redo(2)
+
+	require.Nil(test, err)
+	assert.Equal(test, []string{"UNDO 3", "UNDO 2", "SELECT 2", "SELECT 3"}, queries)
+	require.Len(test, result.Reverted.GetAll(), 2)
+	require.Len(test, result.Reapplied.GetAll(), 2)
+	assert.True(test, result.Reapplied.GetAll()[0].WasSuccessful())
+	assert.Equal(test, "2_b.sql", result.Reapplied.GetAll()[0].GetName())
+}
+
+func TestRedoingMigrationsInTheOrderTheyWereRun(test *testing.T) {
+	test.Parallel()
+
+	fetcher := getRedoFetcher(test)
+	defer fetcher.AssertExpectations(test)
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	files.On("GetMigrationQuery", "/tmp/2_b.down.sql").Return("UNDO 2", nil)
+	files.On("GetMigrationQuery", "/tmp/3_c.down.sql").Return("UNDO 3", nil)
+
+	var queries []string
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	unregisterer := &mocks.RunMigrationUnregisterer{}
+	defer unregisterer.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("RunMigrationQuery", mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { queries = append(queries, args.String(0)) }).
+		Return(nil)
+	unregisterer.On("UnregisterRunMigration", mock.AnythingOfType("string")).Return(nil)
+	db.On("RegisterRunMigration", mock.AnythingOfType("string")).Return(nil)
+	// 3_c.sql was run before 2_b.sql (e.g. merged from another branch), and 3_c.sql was registered twice.
+	lister := getRunMigrationsLister("1_a.sql", "3_c.sql", "3_c.sql", "2_b.sql")
+	defer lister.AssertExpectations(test)
+
+	service := services.NewRedoService(fetcher, files, redoableDBRepository{db, unregisterer, lister}, "/tmp")
+
+	_, err := service.Redo(2)
+
+	require.Nil(test, err)
+	assert.Equal(test, []string{"UNDO 2", "UNDO 3", "SELECT 3", "SELECT 2"}, queries)
+}
+
+func TestRedoingFailsBeforeRevertingAnythingIfADownMigrationIsMissing(test *testing.T) {
+	test.Parallel()
+
+	fetcher := getRedoFetcher(test)
+	defer fetcher.AssertExpectations(test)
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	files.On("GetMigrationQuery", "/tmp/2_b.down.sql").Return("", fmt.Errorf("file not found"))
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	unregisterer := &mocks.RunMigrationUnregisterer{}
+	defer unregisterer.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	lister := getRunMigrationsLister("1_a.sql", "2_b.sql", "3_c.sql")
+	defer lister.AssertExpectations(test)
+
+	service := services.NewRedoService(fetcher, files, redoableDBRepository{db, unregisterer, lister}, "/tmp")
+
+	_, err := service.Redo(2)
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+	db.AssertNotCalled(test, "RunMigrationQuery", mock.Anything)
+}
+
+func TestRedoingFailsIfThereAreNotEnoughRunMigrations(test *testing.T) {
+	test.Parallel()
+
+	fetcher := getRedoFetcher(test)
+	defer fetcher.AssertExpectations(test)
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	unregisterer := &mocks.RunMigrationUnregisterer{}
+	defer unregisterer.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	lister := getRunMigrationsLister()
+	defer lister.AssertExpectations(test)
+
+	service := services.NewRedoService(fetcher, files, redoableDBRepository{db, unregisterer, lister}, "/tmp")
+
+	_, err := service.Redo(4)
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestRedoingReportsAFailedMigration(test *testing.T) {
+	test.Parallel()
+
+	fetcher := getRedoFetcher(test)
+	defer fetcher.AssertExpectations(test)
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	files.On("GetMigrationQuery", "/tmp/3_c.down.sql").Return("UNDO 3", nil)
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	unregisterer := &mocks.RunMigrationUnregisterer{}
+	defer unregisterer.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("RunMigrationQuery", "UNDO 3").Return(nil)
+	unregisterer.On("UnregisterRunMigration", "3_c.sql").Return(nil)
+	db.On("RunMigrationQuery", "SELECT 3").Return(fmt.Errorf("syntax error"))
+	lister := getRunMigrationsLister("1_a.sql", "2_b.sql", "3_c.sql")
+	defer lister.AssertExpectations(test)
+
+	service := services.NewRedoService(fetcher, files, redoableDBRepository{db, unregisterer, lister}, "/tmp")
+
+	result, err := service.Redo(1)
+
+	assert.Equal(test, services.ExitCodeMigrationFailed, services.ExitCode(err))
+	require.Len(test, result.Reapplied.GetAll(), 1)
+	assert.True(test, result.Reapplied.GetAll()[0].HasFailed())
+}
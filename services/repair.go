@@ -0,0 +1,239 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/helpers"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+)
+
+const (
+	// RepairActionRemoveDuplicates leaves a single row for a migration registered more than once.
+	RepairActionRemoveDuplicates = "remove-duplicates"
+	// RepairActionRename updates the row of a migration whose file has been renamed (same order, different name).
+	RepairActionRename = "rename"
+)
+
+// RepairAction is a fix for an inconsistency between the migrations table and the migrations directory.
+type RepairAction struct {
+	Kind             string
+	MigrationName    string
+	NewMigrationName string
+	Description      string
+}
+
+// RepairPlan is the list of fixes for the migrations table. Unresolved describes the inconsistencies that cannot be
+// fixed automatically.
+type RepairPlan struct {
+	Actions    []RepairAction
+	Unresolved []string
+}
+
+// IsEmpty returns true if the migrations table is consistent with the migrations directory.
+func (plan RepairPlan) IsEmpty() bool {
+	return len(plan.Actions) == 0 && len(plan.Unresolved) == 0
+}
+
+// Repairer reconciles the migrations table with the migrations directory. It only touches the migrations table.
+type Repairer interface {
+	GetRepairPlan() (RepairPlan, error)
+	Repair(plan RepairPlan) error
+}
+
+type repairService struct {
+	fileRepository                  repositories.FileRepository
+	dbRepository                    repositories.DBRepository
+	migrationsDirectoryAbsolutePath string
+}
+
+// Ensure repairService implements Repairer.
+var _ Repairer = repairService{}
+
+// NewRepairService returns an implementation of Repairer.
+func NewRepairService(
+	fileRepository repositories.FileRepository,
+	dbRepository repositories.DBRepository,
+	migrationsDirectoryAbsolutePath string,
+) Repairer {
+	return repairService{
+		fileRepository:                  fileRepository,
+		dbRepository:                    dbRepository,
+		migrationsDirectoryAbsolutePath: helpers.AddTrailingSlashToPathIfNeeded(migrationsDirectoryAbsolutePath),
+	}
+}
+
+// GetRepairPlan compares the migrations table with the migrations directory and returns the fixes needed, without
+// applying them. It detects:
+//   - migrations registered more than once.
+//   - registered migrations whose file has been renamed (a single unregistered file with the same order).
+//
+// Registered migrations without a matching file are reported as unresolved (unless they have been squashed).
+func (service repairService) GetRepairPlan() (RepairPlan, error) {
+	err := service.dbRepository.Ping()
+	if err != nil {
+		return RepairPlan{}, NewDBConnectionError(errors.Wrap(err, "failed to connect to the DB"))
+	}
+
+	err = service.dbRepository.CreateMigrationsTableIfNeeded()
+	if err != nil {
+		return RepairPlan{}, NewBookkeepingError(err)
+	}
+
+	filePaths, err := service.fileRepository.GetMigrationFilePaths(service.migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return RepairPlan{}, err
+	}
+
+	registeredPaths, err := service.dbRepository.GetAlreadyRunMigrationFilePaths(service.migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return RepairPlan{}, NewBookkeepingError(err)
+	}
+	registeredPaths = withoutAdditionalDirectoryPaths(registeredPaths, service.migrationsDirectoryAbsolutePath)
+
+	plan := getRepairPlan(getFileNames(filePaths), getFileNames(registeredPaths))
+	if plan.IsEmpty() {
+		return plan, nil
+	}
+
+	squashedPaths, err := getSquashedPaths(service.fileRepository, filePaths)
+	if err != nil {
+		return RepairPlan{}, err
+	}
+
+	return getRepairPlan(getFileNames(filePaths), getFileNames(withoutSquashedPaths(registeredPaths, squashedPaths))), nil
+}
+
+// withoutAdditionalDirectoryPaths removes the migrations registered from additional migrations directories
+// ({source}/{name}, see GetMigrationSource), since only the main directory is repaired.
+func withoutAdditionalDirectoryPaths(paths []string, migrationsDirectoryAbsolutePath string) []string {
+	var result []string
+	for _, path := range paths {
+		if !strings.Contains(strings.TrimPrefix(path, migrationsDirectoryAbsolutePath), "/") {
+			result = append(result, path)
+		}
+	}
+
+	return result
+}
+
+// withoutSquashedPaths removes the paths of the migrations that have been squashed (see Squasher).
+func withoutSquashedPaths(paths []string, squashedPaths map[string]string) []string {
+	var result []string
+	for _, path := range paths {
+		if _, isSquashed := squashedPaths[path]; !isSquashed {
+			result = append(result, path)
+		}
+	}
+
+	return result
+}
+
+// Repair applies the fixes of a plan (see GetRepairPlan). Each fix is a single statement that keeps a row for the
+// migration, so a failure halfway never leaves a run migration unregistered. It needs a DBRepository that implements
+// repositories.RunMigrationsRepairer.
+func (service repairService) Repair(plan RepairPlan) error {
+	repairer, canRepair := repositories.UnwrapDBRepository(service.dbRepository).(repositories.RunMigrationsRepairer)
+	if !canRepair {
+		return NewBookkeepingError(errors.New("the DB repository cannot repair the migrations table"))
+	}
+
+	for _, action := range plan.Actions {
+		var err error
+		if action.Kind == RepairActionRename {
+			err = repairer.RenameRunMigration(action.MigrationName, action.NewMigrationName)
+		} else {
+			err = repairer.RemoveDuplicatedRunMigration(action.MigrationName)
+		}
+		if err != nil {
+			return NewBookkeepingError(err)
+		}
+	}
+
+	return nil
+}
+
+func getRepairPlan(fileNames []string, registeredNames []string) RepairPlan {
+	filesOnDisk := map[string]bool{}
+	for _, fileName := range fileNames {
+		filesOnDisk[fileName] = true
+	}
+
+	registrations := map[string]int{}
+	var uniqueRegisteredNames []string
+	for _, registeredName := range registeredNames {
+		if registrations[registeredName] == 0 {
+			uniqueRegisteredNames = append(uniqueRegisteredNames, registeredName)
+		}
+		registrations[registeredName]++
+	}
+	sort.Strings(uniqueRegisteredNames)
+
+	unregisteredFileNamesByOrder := map[uint64][]string{}
+	for _, fileName := range fileNames {
+		order, ok := getOrderFromFileName(fileName)
+		if ok && registrations[fileName] == 0 {
+			unregisteredFileNamesByOrder[order] = append(unregisteredFileNamesByOrder[order], fileName)
+		}
+	}
+
+	plan := RepairPlan{}
+	for _, registeredName := range uniqueRegisteredNames {
+		if filesOnDisk[registeredName] {
+			if registrations[registeredName] > 1 {
+				plan.Actions = append(plan.Actions, RepairAction{
+					Kind:          RepairActionRemoveDuplicates,
+					MigrationName: registeredName,
+					Description: fmt.Sprintf("%s is registered %d times, keep a single row",
+						registeredName,
+						registrations[registeredName]),
+				})
+			}
+			continue
+		}
+
+		order, ok := getOrderFromFileName(registeredName)
+		candidates := unregisteredFileNamesByOrder[order]
+		if !ok || len(candidates) != 1 {
+			plan.Unresolved = append(plan.Unresolved, fmt.Sprintf(
+				"%s is registered but its file does not exist on the migrations directory",
+				registeredName,
+			))
+			continue
+		}
+
+		plan.Actions = append(plan.Actions, RepairAction{
+			Kind:             RepairActionRename,
+			MigrationName:    registeredName,
+			NewMigrationName: candidates[0],
+			Description:      fmt.Sprintf("%s has been renamed to %s, update its row", registeredName, candidates[0]),
+		})
+		// The same file cannot be the new name of two rows.
+		delete(unregisteredFileNamesByOrder, order)
+	}
+
+	return plan
+}
+
+func getOrderFromFileName(fileName string) (uint64, bool) {
+	migration, err := models.NewMigration(fileName, "", models.StatusNotRun)
+	if err != nil {
+		return 0, false
+	}
+
+	return migration.GetOrder(), true
+}
+
+func getFileNames(paths []string) []string {
+	fileNames := make([]string, 0, len(paths))
+	for _, path := range paths {
+		fileNames = append(fileNames, filepath.Base(path))
+	}
+
+	return fileNames
+}
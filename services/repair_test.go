@@ -0,0 +1,209 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestGettingARepairPlan(test *testing.T) {
+	test.Parallel()
+
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	files.On("GetMigrationFilePaths", "/tmp/").Return([]string{
+		"/tmp/1_a.sql",
+		"/tmp/2_renamed.sql",
+		"/tmp/3_c.sql",
+	}, nil)
+	files.On("GetMigrationQuery", mock.AnythingOfType("string")).Return("SELECT 1", nil)
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("GetAlreadyRunMigrationFilePaths", "/tmp/").Return([]string{
+		"/tmp/1_a.sql",
+		"/tmp/1_a.sql",
+		"/tmp/2_b.sql",
+		"/tmp/4_deleted.sql",
+	}, nil)
+
+	service := services.NewRepairService(files, db, "/tmp")
+
+	plan, err := service.GetRepairPlan()
+
+	require.Nil(test, err)
+	require.Len(test, plan.Actions, 2)
+	assert.Equal(test, services.RepairActionRemoveDuplicates, plan.Actions[0].Kind)
+	assert.Equal(test, "1_a.sql", plan.Actions[0].MigrationName)
+	assert.Equal(test, services.RepairActionRename, plan.Actions[1].Kind)
+	assert.Equal(test, "2_b.sql", plan.Actions[1].MigrationName)
+	assert.Equal(test, "2_renamed.sql", plan.Actions[1].NewMigrationName)
+	require.Len(test, plan.Unresolved, 1)
+	assert.Contains(test, plan.Unresolved[0], "4_deleted.sql")
+}
+
+func TestGettingAnEmptyRepairPlan(test *testing.T) {
+	test.Parallel()
+
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	files.On("GetMigrationFilePaths", "/tmp/").Return([]string{"/tmp/1_a.sql", "/tmp/2_b.sql"}, nil)
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("GetAlreadyRunMigrationFilePaths", "/tmp/").Return([]string{"/tmp/1_a.sql"}, nil)
+
+	service := services.NewRepairService(files, db, "/tmp")
+
+	plan, err := service.GetRepairPlan()
+
+	require.Nil(test, err)
+	assert.True(test, plan.IsEmpty())
+}
+
+func TestARenameIsNotGuessedIfThereAreSeveralCandidates(test *testing.T) {
+	test.Parallel()
+
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	files.On("GetMigrationFilePaths", "/tmp/").Return([]string{"/tmp/2_x.sql", "/tmp/2_y.sql"}, nil)
+	files.On("GetMigrationQuery", mock.AnythingOfType("string")).Return("SELECT 1", nil)
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("GetAlreadyRunMigrationFilePaths", "/tmp/").Return([]string{"/tmp/2_b.sql"}, nil)
+
+	service := services.NewRepairService(files, db, "/tmp")
+
+	plan, err := service.GetRepairPlan()
+
+	require.Nil(test, err)
+	assert.Empty(test, plan.Actions)
+	assert.Len(test, plan.Unresolved, 1)
+}
+
+func TestGettingARepairPlanFailsIfTheDBConnectionDoesNotWork(test *testing.T) {
+	test.Parallel()
+
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(fmt.Errorf("db connection error"))
+
+	service := services.NewRepairService(files, db, "/tmp")
+
+	_, err := service.GetRepairPlan()
+
+	assert.Equal(test, services.ExitCodeDBUnavailable, services.ExitCode(err))
+}
+
+// repairableDBRepository is a DBRepository that implements repositories.RunMigrationsRepairer.
+type repairableDBRepository struct {
+	*mocks.DBRepository
+	*mocks.RunMigrationsRepairer
+}
+
+func TestRepairing(test *testing.T) {
+	test.Parallel()
+
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	repairer := &mocks.RunMigrationsRepairer{}
+	defer repairer.AssertExpectations(test)
+	repairer.On("RemoveDuplicatedRunMigration", "1_a.sql").Return(nil).Once()
+	repairer.On("RenameRunMigration", "2_b.sql", "2_renamed.sql").Return(nil).Once()
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	retryingDB := services.NewRetryingDBRepository(
+		context.Background(),
+		repairableDBRepository{DBRepository: db, RunMigrationsRepairer: repairer},
+		services.ConnectionRetry{Timeout: time.Second},
+		nil,
+	)
+
+	service := services.NewRepairService(files, retryingDB, "/tmp")
+
+	err := service.Repair(services.RepairPlan{
+		Actions: []services.RepairAction{
+			{Kind: services.RepairActionRemoveDuplicates, MigrationName: "1_a.sql"},
+			{Kind: services.RepairActionRename, MigrationName: "2_b.sql", NewMigrationName: "2_renamed.sql"},
+		},
+	})
+
+	assert.Nil(test, err)
+	db.AssertNotCalled(test, "UnregisterRunMigration", mock.Anything)
+}
+
+func TestRepairingFailsIfARowCannotBeDeleted(test *testing.T) {
+	test.Parallel()
+
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	repairer := &mocks.RunMigrationsRepairer{}
+	defer repairer.AssertExpectations(test)
+	repairer.On("RemoveDuplicatedRunMigration", "1_a.sql").Return(fmt.Errorf("delete failed"))
+	db := repairableDBRepository{DBRepository: &mocks.DBRepository{}, RunMigrationsRepairer: repairer}
+
+	service := services.NewRepairService(files, db, "/tmp")
+
+	err := service.Repair(services.RepairPlan{
+		Actions: []services.RepairAction{{Kind: services.RepairActionRemoveDuplicates, MigrationName: "1_a.sql"}},
+	})
+
+	assert.Equal(test, services.ExitCodeBookkeepingFailed, services.ExitCode(err))
+}
+
+func TestRepairingFailsIfTheDBRepositoryCannotRepair(test *testing.T) {
+	test.Parallel()
+
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+
+	service := services.NewRepairService(files, db, "/tmp")
+
+	err := service.Repair(services.RepairPlan{
+		Actions: []services.RepairAction{{Kind: services.RepairActionRemoveDuplicates, MigrationName: "1_a.sql"}},
+	})
+
+	assert.Equal(test, services.ExitCodeBookkeepingFailed, services.ExitCode(err))
+}
+
+func TestSquashedMigrationsAreNotRepaired(test *testing.T) {
+	test.Parallel()
+
+	files := &mocks.FileRepository{}
+	defer files.AssertExpectations(test)
+	files.On("GetMigrationFilePaths", "/tmp/").Return([]string{"/tmp/2_squashed.sql"}, nil)
+	files.On("GetMigrationQuery", "/tmp/2_squashed.sql").
+		Return("-- migrations:squashed 1_a.sql, 2_b.sql\nSELECT 1;", nil)
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("GetAlreadyRunMigrationFilePaths", "/tmp/").Return([]string{"/tmp/1_a.sql", "/tmp/2_b.sql"}, nil)
+
+	service := services.NewRepairService(files, db, "/tmp")
+
+	plan, err := service.GetRepairPlan()
+
+	require.Nil(test, err)
+	assert.True(test, plan.IsEmpty())
+}
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+	"time"
+
 	"github.com/pkg/errors"
 
 	"github.com/jimenezmaximiliano/migrations/helpers"
@@ -17,37 +20,73 @@ type runnerService struct {
 	migrationFetcherService         Fetcher
 	dbRepository                    repositories.DBRepository
 	migrationsDirectoryAbsolutePath string
+	metrics                         Metrics
+	progress                        Progress
+	heartbeatInterval               time.Duration
 }
 
 // Ensure runnerService implements Runner.
 var _ Runner = runnerService{}
 
-// Ensure runnerService implements Runner.
-var _ Runner = runnerService{}
+// RunnerOption customizes the behaviour of the Runner returned by NewRunnerService.
+type RunnerOption func(service *runnerService)
+
+// WithMetrics makes the Runner report the result and duration of each migration to the given Metrics.
+func WithMetrics(metrics Metrics) RunnerOption {
+	return func(service *runnerService) {
+		service.metrics = metrics
+	}
+}
+
+// WithProgress makes the Runner announce each migration as it starts and report it every heartbeatInterval while it's
+// still running (a zero interval disables the heartbeat).
+func WithProgress(progress Progress, heartbeatInterval time.Duration) RunnerOption {
+	return func(service *runnerService) {
+		service.progress = progress
+		service.heartbeatInterval = heartbeatInterval
+	}
+}
+
+// WithConnectionRetry makes the Runner retry to connect to the DB (e.g. while it's starting) instead of failing on the
+// first attempt (see NewRetryingDBRepository).
+func WithConnectionRetry(ctx context.Context, retry ConnectionRetry, progress ConnectionProgress) RunnerOption {
+	return func(service *runnerService) {
+		service.dbRepository = NewRetryingDBRepository(ctx, service.dbRepository, retry, progress)
+	}
+}
 
 // NewRunnerService returns an implementation of Runner.
 func NewRunnerService(
 	migrationFetcherService Fetcher,
 	DBRepository repositories.DBRepository,
-	migrationsDirectoryAbsolutePath string) Runner {
+	migrationsDirectoryAbsolutePath string,
+	options ...RunnerOption) Runner {
 
-	return runnerService{
+	service := runnerService{
 		migrationFetcherService:         migrationFetcherService,
 		dbRepository:                    DBRepository,
 		migrationsDirectoryAbsolutePath: helpers.AddTrailingSlashToPathIfNeeded(migrationsDirectoryAbsolutePath),
+		metrics:                         nilMetrics{},
+		progress:                        nilProgress{},
+	}
+
+	for _, option := range options {
+		option(&service)
 	}
+
+	return service
 }
 
 // RunMigrations runs a collection of migrations checking first if they have been run already.
 func (service runnerService) RunMigrations() (models.Collection, error) {
 	err := service.dbRepository.Ping()
 	if err != nil {
-		return models.Collection{}, errors.Wrap(err, "failed to connect to the DB")
+		return models.Collection{}, NewDBConnectionError(errors.Wrap(err, "failed to connect to the DB"))
 	}
 
 	err = service.dbRepository.CreateMigrationsTableIfNeeded()
 	if err != nil {
-		return models.Collection{}, err
+		return models.Collection{}, NewBookkeepingError(err)
 	}
 
 	allMigrations, err := service.migrationFetcherService.GetMigrations(service.migrationsDirectoryAbsolutePath)
@@ -56,12 +95,16 @@ func (service runnerService) RunMigrations() (models.Collection, error) {
 	}
 
 	migrationsToRun := allMigrations.GetMigrationsToRun()
+	service.metrics.SetPendingMigrations(len(migrationsToRun))
 
 	if len(migrationsToRun) == 0 {
 		return models.Collection{}, nil
 	}
 
-	return service.runMigrations(migrationsToRun)
+	result, err := service.runMigrations(migrationsToRun)
+	service.metrics.SetPendingMigrations(len(result.GetMigrationsToRun()) + countFailed(result))
+
+	return result, err
 }
 
 func (service runnerService) runMigrations(migrationsToRun []models.Migration) (models.Collection, error) {
@@ -78,9 +121,12 @@ func (service runnerService) runMigrations(migrationsToRun []models.Migration) (
 			continue
 		}
 
-		err := service.dbRepository.RunMigrationQuery(migration.GetQuery())
+		duration, err := service.runMigrationQuery(migration)
 		if err != nil {
-			err = result.Add(migration.NewAsFailed(errors.WithStack(err)))
+			failedMigration := migration.NewAsFailed(errors.WithStack(err))
+			service.metrics.ObserveMigration(failedMigration, duration)
+			service.progress.MigrationFinished(failedMigration, duration)
+			err = result.Add(failedMigration)
 			if err != nil {
 				return result, err
 			}
@@ -90,16 +136,67 @@ func (service runnerService) runMigrations(migrationsToRun []models.Migration) (
 			continue
 		}
 
-		err = result.Add(migration.NewAsSuccessful())
+		successfulMigration := migration.NewAsSuccessful()
+		service.progress.MigrationFinished(successfulMigration, duration)
+		err = result.Add(successfulMigration)
 		if err != nil {
 			return result, err
 		}
 
-		err = service.dbRepository.RegisterRunMigration(migration.GetName())
+		err = service.dbRepository.RegisterRunMigration(models.GetQualifiedName(migration))
 		if err != nil {
-			return result, err
+			// It will run again, so it's not applied.
+			service.metrics.ObserveMigration(migration.NewAsFailed(errors.WithStack(err)), duration)
+			return result, NewBookkeepingError(err)
 		}
+		service.metrics.ObserveMigration(successfulMigration, duration)
 	}
 
 	return result, nil
 }
+
+// runMigrationQuery runs the query of a migration, reporting its progress while it's running.
+func (service runnerService) runMigrationQuery(migration models.Migration) (time.Duration, error) {
+	service.progress.MigrationStarted(migration)
+	startedAt := time.Now()
+
+	if service.heartbeatInterval <= 0 {
+		err := service.dbRepository.RunMigrationQuery(migration.GetQuery())
+
+		return time.Since(startedAt), err
+	}
+
+	finished := make(chan struct{})
+	heartbeatStopped := make(chan struct{})
+	go func() {
+		defer close(heartbeatStopped)
+		ticker := time.NewTicker(service.heartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-finished:
+				return
+			case <-ticker.C:
+				service.progress.MigrationStillRunning(migration, time.Since(startedAt))
+			}
+		}
+	}()
+
+	err := service.dbRepository.RunMigrationQuery(migration.GetQuery())
+	close(finished)
+	<-heartbeatStopped
+
+	return time.Since(startedAt), err
+}
+
+func countFailed(migrations models.Collection) int {
+	failed := 0
+	for _, migration := range migrations.GetAll() {
+		if migration.HasFailed() {
+			failed++
+		}
+	}
+
+	return failed
+}
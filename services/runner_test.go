@@ -1,8 +1,10 @@
 package services_test
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,6 +29,7 @@ func TestRunningMigrationsFailsIfTheDBConnectionDoesNotWork(test *testing.T) {
 	_, err := service.RunMigrations()
 
 	assert.NotNil(test, err)
+	assert.Equal(test, services.ExitCodeDBUnavailable, services.ExitCode(err))
 }
 
 func TestRunningMigrationsFailsIfAMigrationsTableCannotBeCreated(test *testing.T) {
@@ -163,3 +166,120 @@ func TestRunningAMigrationSuccessfullyAndThenFailingToRegisterIt(test *testing.T
 	assert.Equal(test, "/tmp/1_a.sql", result.GetAll()[0].GetAbsolutePath())
 	assert.True(test, result.GetAll()[0].WasSuccessful())
 }
+
+func TestRunningMigrationsReportsMetrics(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("RunMigrationQuery", "SELECT 1").Return(nil)
+	db.On("RegisterRunMigration", "1_a.sql").Return(nil)
+	db.On("RunMigrationQuery", "SELECT 2").Return(fmt.Errorf("query failed"))
+
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	collection := models.Collection{}
+	migration1, _ := models.NewMigration("/tmp/1_a.sql", "SELECT 1", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration1))
+	migration2, _ := models.NewMigration("/tmp/2_b.sql", "SELECT 2", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration2))
+	migration3, _ := models.NewMigration("/tmp/3_c.sql", "SELECT 3", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration3))
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+
+	metrics := services.NewMetricsCollector()
+	service := services.NewRunnerService(fetcher, db, "/tmp", services.WithMetrics(metrics))
+
+	_, err := service.RunMigrations()
+	require.Nil(test, err)
+
+	output := &bytes.Buffer{}
+	require.Nil(test, metrics.WritePrometheus(output))
+	assert.Contains(test, output.String(), "migrations_applied_total 1\n")
+	assert.Contains(test, output.String(), "migrations_failed_total 1\n")
+	assert.Contains(test, output.String(), "migrations_duration_seconds_count 2\n")
+	assert.Contains(test, output.String(), "migrations_pending 2\n")
+	assert.Contains(test, output.String(), "migrations_last_applied_order 1\n")
+}
+
+func TestAMigrationThatCannotBeRegisteredIsReportedAsFailed(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("RunMigrationQuery", "SELECT 1").Return(nil)
+	db.On("RegisterRunMigration", "1_a.sql").Return(fmt.Errorf("failed to register run migration"))
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	collection := models.Collection{}
+	migration, _ := models.NewMigration("/tmp/1_a.sql", "SELECT 1", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration))
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+
+	metrics := services.NewMetricsCollector()
+	service := services.NewRunnerService(fetcher, db, "/tmp", services.WithMetrics(metrics))
+
+	_, err := service.RunMigrations()
+	assert.Equal(test, services.ExitCodeBookkeepingFailed, services.ExitCode(err))
+
+	output := &bytes.Buffer{}
+	require.Nil(test, metrics.WritePrometheus(output))
+	assert.Contains(test, output.String(), "migrations_applied_total 0\n")
+	assert.Contains(test, output.String(), "migrations_failed_total 1\n")
+}
+
+func TestRunningMigrationsReportsProgress(test *testing.T) {
+	test.Parallel()
+
+	db := &mocks.DBRepository{}
+	defer db.AssertExpectations(test)
+	db.On("Ping").Return(nil)
+	db.On("CreateMigrationsTableIfNeeded").Return(nil)
+	db.On("RunMigrationQuery", "SELECT 1").Return(nil).After(50 * time.Millisecond)
+	db.On("RegisterRunMigration", "1_a.sql").Return(nil)
+	db.On("RunMigrationQuery", "SELECT 2").Return(fmt.Errorf("query failed"))
+
+	fetcher := &mocks.Fetcher{}
+	defer fetcher.AssertExpectations(test)
+	collection := models.Collection{}
+	migration1, _ := models.NewMigration("/tmp/1_a.sql", "SELECT 1", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration1))
+	migration2, _ := models.NewMigration("/tmp/2_b.sql", "SELECT 2", models.StatusNotRun)
+	require.Nil(test, collection.Add(migration2))
+	fetcher.On("GetMigrations", "/tmp/").Return(collection, nil)
+
+	progress := &progressLogger{}
+	service := services.NewRunnerService(fetcher, db, "/tmp", services.WithProgress(progress, 10*time.Millisecond))
+
+	_, err := service.RunMigrations()
+	require.Nil(test, err)
+
+	require.GreaterOrEqual(test, len(progress.events), 5)
+	assert.Equal(test, "started 1_a.sql", progress.events[0])
+	assert.Equal(test, "still running 1_a.sql", progress.events[1])
+	assert.Equal(test, []string{
+		"finished 1_a.sql 2",
+		"started 2_b.sql",
+		"finished 2_b.sql -1",
+	}, progress.events[len(progress.events)-3:])
+}
+
+type progressLogger struct {
+	events []string
+}
+
+func (logger *progressLogger) MigrationStarted(migration models.Migration) {
+	logger.events = append(logger.events, "started "+migration.GetName())
+}
+
+func (logger *progressLogger) MigrationStillRunning(migration models.Migration, elapsed time.Duration) {
+	logger.events = append(logger.events, "still running "+migration.GetName())
+}
+
+func (logger *progressLogger) MigrationFinished(migration models.Migration, duration time.Duration) {
+	logger.events = append(logger.events, fmt.Sprintf("finished %s %d", migration.GetName(), migration.GetStatus()))
+}
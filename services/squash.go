@@ -0,0 +1,214 @@
+package services
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/helpers"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+)
+
+// SquashArchiveDirectoryName is the subdirectory of the migrations directory where squashed migrations are moved.
+const SquashArchiveDirectoryName = "archive"
+
+// DefaultSquashName is the name of the squashed migration if none is given.
+const DefaultSquashName = "squashed"
+
+// squashedMigrationsComment lists the migrations a squashed migration is made of (on its first line):
+//
+//	-- migrations:squashed 1_a.sql, 2_b.sql
+var squashedMigrationsComment = regexp.MustCompile(`(?m)^\s*--\s*migrations:squashed\b(.*)$`)
+
+// SquashResult describes a squashed migration.
+type SquashResult struct {
+	FilePath             string
+	SquashedFilePaths    []string
+	ArchiveDirectoryPath string
+}
+
+// Squasher collapses old migrations into a single one.
+type Squasher interface {
+	Squash(migrationsDirectoryAbsolutePath string, order uint64, name string) (SquashResult, error)
+}
+
+type squashService struct {
+	fileRepository repositories.FileRepository
+	fileSystem     adapters.FileSystem
+}
+
+// Ensure squashService implements Squasher.
+var _ Squasher = squashService{}
+
+// NewSquashService returns an implementation of Squasher.
+func NewSquashService(fileRepository repositories.FileRepository, fileSystem adapters.FileSystem) Squasher {
+	return squashService{
+		fileRepository: fileRepository,
+		fileSystem:     fileSystem,
+	}
+}
+
+// Squash concatenates every migration up to the given order (inclusive) into a new migration with that order, and
+// moves the originals (and their down migrations) to the archive subdirectory. It doesn't connect to the DB: the
+// squashed migration lists its parts, so it's considered run on DBs where its parts have been run
+// (see FetcherService.GetMigrations). It needs a FileSystem that implements adapters.FileMover.
+func (service squashService) Squash(
+	migrationsDirectoryAbsolutePath string,
+	order uint64,
+	name string,
+) (SquashResult, error) {
+	fileMover, canMove := service.fileSystem.(adapters.FileMover)
+	if !canMove {
+		return SquashResult{}, errors.New("the file system cannot move the squashed migrations to the archive")
+	}
+
+	migrationsDirectoryAbsolutePath = helpers.AddTrailingSlashToPathIfNeeded(migrationsDirectoryAbsolutePath)
+	if name == "" {
+		name = DefaultSquashName
+	}
+
+	migrations, err := service.getMigrationsToSquash(migrationsDirectoryAbsolutePath, order)
+	if err != nil {
+		return SquashResult{}, err
+	}
+
+	result := SquashResult{
+		FilePath:             fmt.Sprintf("%s%d_%s.sql", migrationsDirectoryAbsolutePath, order, name),
+		ArchiveDirectoryPath: migrationsDirectoryAbsolutePath + SquashArchiveDirectoryName + "/",
+	}
+	for _, migration := range migrations {
+		if migration.GetAbsolutePath() == result.FilePath {
+			return SquashResult{}, NewArgumentsError(errors.Errorf(
+				"the squashed migration would overwrite [%s] (use the 'name' option to choose another name)",
+				result.FilePath,
+			))
+		}
+		result.SquashedFilePaths = append(result.SquashedFilePaths, migration.GetAbsolutePath())
+	}
+
+	err = service.fileRepository.CreateMigration(result.FilePath, renderSquashedMigration(migrations))
+	if err != nil {
+		return SquashResult{}, err
+	}
+
+	err = service.archive(fileMover, migrations, result.ArchiveDirectoryPath)
+	if err != nil {
+		return result, errors.Wrapf(err, "the squashed migration [%s] has been created but the squashed migrations "+
+			"could not be archived (move them manually to [%s])", result.FilePath, result.ArchiveDirectoryPath)
+	}
+
+	return result, nil
+}
+
+func (service squashService) getMigrationsToSquash(
+	migrationsDirectoryAbsolutePath string,
+	order uint64,
+) ([]models.Migration, error) {
+	filePaths, err := service.fileRepository.GetMigrationFilePaths(migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := models.Collection{}
+	for _, filePath := range filePaths {
+		query, err := service.fileRepository.GetMigrationQuery(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, err := models.NewMigration(filePath, query, models.StatusNotRun)
+		if err != nil {
+			return nil, err
+		}
+
+		if migration.GetOrder() > order {
+			continue
+		}
+
+		err = collection.Add(migration)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if collection.IsEmpty() {
+		return nil, NewArgumentsError(errors.Errorf("there are no migrations to squash up to the order %d", order))
+	}
+
+	return collection.GetAll(), nil
+}
+
+func (service squashService) archive(
+	fileMover adapters.FileMover,
+	migrations []models.Migration,
+	archiveDirectoryPath string,
+) error {
+	err := fileMover.MkdirAll(archiveDirectoryPath, fs.FileMode(0755))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the archive directory [%s]", archiveDirectoryPath)
+	}
+
+	for _, migration := range migrations {
+		err = fileMover.Rename(migration.GetAbsolutePath(), archiveDirectoryPath+migration.GetName())
+		if err != nil {
+			return errors.Wrapf(err, "failed to archive the migration [%s]", migration.GetAbsolutePath())
+		}
+
+		downMigrationPath := repositories.GetDownMigrationPath(migration.GetAbsolutePath())
+		_, err = service.fileSystem.ReadFile(downMigrationPath)
+		if err != nil {
+			// There is no down migration.
+			continue
+		}
+
+		err = fileMover.Rename(
+			downMigrationPath,
+			archiveDirectoryPath+repositories.GetDownMigrationPath(migration.GetName()),
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to archive the down migration [%s]", downMigrationPath)
+		}
+	}
+
+	return nil
+}
+
+func renderSquashedMigration(migrations []models.Migration) string {
+	var names []string
+	for _, migration := range migrations {
+		// Keep the parts of squashed migrations, so DBs where they have been run are still recognized.
+		names = append(names, GetSquashedMigrationNames(migration.GetQuery())...)
+		names = append(names, migration.GetName())
+	}
+
+	script := &strings.Builder{}
+	script.WriteString(fmt.Sprintf("-- migrations:squashed %s\n", strings.Join(names, ", ")))
+	for _, migration := range migrations {
+		query := strings.TrimSpace(squashedMigrationsComment.ReplaceAllString(migration.GetQuery(), ""))
+		if query != "" && !strings.HasSuffix(query, ";") {
+			query += ";"
+		}
+
+		script.WriteString(fmt.Sprintf("\n-- %s\n%s\n", migration.GetName(), query))
+	}
+
+	return script.String()
+}
+
+// GetSquashedMigrationNames returns the file names of the migrations a squashed migration is made of (none if the
+// query is not a squashed migration).
+func GetSquashedMigrationNames(query string) []string {
+	var names []string
+	for _, match := range squashedMigrationsComment.FindAllStringSubmatch(query, -1) {
+		names = append(names, strings.FieldsFunc(match[1], func(character rune) bool {
+			return character == ',' || character == ' ' || character == '\t' || character == '\r'
+		})...)
+	}
+
+	return names
+}
@@ -0,0 +1,105 @@
+package services_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func getSquashService() services.Squasher {
+	return services.NewSquashService(repositories.NewFileRepository(adapters.IOUtilAdapter{}), adapters.IOUtilAdapter{})
+}
+
+func TestSquashingMigrations(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "1_createGophersTable.sql", "CREATE TABLE gophers (id INT);\n")
+	writeFile(test, directory, "1_createGophersTable.down.sql", "DROP TABLE gophers;")
+	writeFile(test, directory, "2_insertGopher.sql", "INSERT INTO gophers VALUES (1)")
+	writeFile(test, directory, "3_insertGopher.sql", "INSERT INTO gophers VALUES (2);")
+
+	result, err := getSquashService().Squash(directory, 2, "")
+
+	require.Nil(test, err)
+	assert.Equal(test, directory+"/2_squashed.sql", result.FilePath)
+	assert.Len(test, result.SquashedFilePaths, 2)
+
+	squashedQuery, err := ioutil.ReadFile(result.FilePath)
+	require.Nil(test, err)
+	assert.Equal(test, "-- migrations:squashed 1_createGophersTable.sql, 2_insertGopher.sql\n"+
+		"\n-- 1_createGophersTable.sql\nCREATE TABLE gophers (id INT);\n"+
+		"\n-- 2_insertGopher.sql\nINSERT INTO gophers VALUES (1);\n", string(squashedQuery))
+
+	remainingFiles, err := filepath.Glob(filepath.Join(directory, "*.sql"))
+	require.Nil(test, err)
+	assert.Equal(test, []string{
+		filepath.Join(directory, "2_squashed.sql"),
+		filepath.Join(directory, "3_insertGopher.sql"),
+	}, remainingFiles)
+
+	archivedFiles, err := filepath.Glob(filepath.Join(directory, services.SquashArchiveDirectoryName, "*.sql"))
+	require.Nil(test, err)
+	assert.Len(test, archivedFiles, 3)
+}
+
+func TestSquashingASquashedMigrationKeepsItsParts(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "2_squashed.sql", "-- migrations:squashed 1_a.sql, 2_b.sql\n\n-- 1_a.sql\nSELECT 1;\n")
+	writeFile(test, directory, "3_c.sql", "SELECT 3;")
+
+	result, err := getSquashService().Squash(directory, 3, "baseline")
+
+	require.Nil(test, err)
+	squashedQuery, err := ioutil.ReadFile(result.FilePath)
+	require.Nil(test, err)
+	assert.Equal(test,
+		[]string{"1_a.sql", "2_b.sql", "2_squashed.sql", "3_c.sql"},
+		services.GetSquashedMigrationNames(string(squashedQuery)))
+}
+
+func TestSquashingFailsIfThereAreNoMigrationsToSquash(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "5_a.sql", "SELECT 1;")
+
+	_, err := getSquashService().Squash(directory, 2, "")
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestSquashingFailsIfTheSquashedMigrationWouldOverwriteAMigration(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "1_a.sql", "SELECT 1;")
+	writeFile(test, directory, "2_squashed.sql", "SELECT 2;")
+
+	_, err := getSquashService().Squash(directory, 2, "")
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestSquashingFailsBeforeCreatingAnythingIfTheFileSystemCannotMoveFiles(test *testing.T) {
+	test.Parallel()
+
+	fileRepository := &mocks.FileRepository{}
+	defer fileRepository.AssertExpectations(test)
+	fileSystem := &mocks.FileSystem{}
+	defer fileSystem.AssertExpectations(test)
+
+	_, err := services.NewSquashService(fileRepository, fileSystem).Squash("/tmp", 2, "")
+
+	assert.Equal(test, services.ExitCodeUnknownError, services.ExitCode(err))
+}
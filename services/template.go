@@ -0,0 +1,208 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/helpers"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+)
+
+// DefaultTemplateFileName is the template used by the create command if no template is given and the file exists on
+// the migrations directory (.migration-template.down.sql is the template for the down migration).
+const DefaultTemplateFileName = ".migration-template.sql"
+
+// defaultMigrationQuery is the content of new migrations without a template.
+const defaultMigrationQuery = "SELECT 1;"
+
+// TemplateData are the values of the placeholders of a template:
+//
+//	{{name}}      the name of the migration.
+//	{{timestamp}} the order of the migration.
+//	{{date}}      the creation date (RFC 3339).
+//	{{author}}    the user name from the git config.
+//	{{table}}     the table name guessed from the name of the migration (e.g. createGophersTable -> gophers).
+type TemplateData struct {
+	Name  string
+	Order uint64
+	Date  time.Time
+}
+
+// RenderedMigration is the content of a new migration. Down is empty if there's no template for the down migration.
+type RenderedMigration struct {
+	Up   string
+	Down string
+}
+
+// Templater renders the content of new migrations.
+type Templater interface {
+	Render(migrationsDirectoryAbsolutePath, templatePath string, data TemplateData) (RenderedMigration, error)
+}
+
+type templateService struct {
+	fileSystem adapters.FileSystem
+	getAuthor  func() string
+}
+
+// Ensure templateService implements Templater.
+var _ Templater = templateService{}
+
+// NewTemplateService returns an implementation of Templater. getAuthor returns the value of the {{author}} placeholder
+// (see adapters.GetGitAuthor).
+func NewTemplateService(fileSystem adapters.FileSystem, getAuthor func() string) Templater {
+	return templateService{
+		fileSystem: fileSystem,
+		getAuthor:  getAuthor,
+	}
+}
+
+// Render returns the content of a new migration using a template. The template path can be a file (and its .down.sql
+// sibling for the down migration) or a directory with up.sql and down.sql files. Without a template path,
+// DefaultTemplateFileName is used if it exists on the migrations directory, otherwise a sample query.
+func (service templateService) Render(
+	migrationsDirectoryAbsolutePath,
+	templatePath string,
+	data TemplateData,
+) (RenderedMigration, error) {
+	template, err := service.getTemplate(migrationsDirectoryAbsolutePath, templatePath)
+	if err != nil {
+		return RenderedMigration{}, err
+	}
+
+	replacer := strings.NewReplacer(
+		"{{name}}", data.Name,
+		"{{timestamp}}", fmt.Sprintf("%d", data.Order),
+		"{{date}}", data.Date.Format(time.RFC3339),
+		"{{author}}", service.getAuthor(),
+		"{{table}}", GuessTableName(data.Name),
+	)
+
+	return RenderedMigration{
+		Up:   replacer.Replace(template.Up),
+		Down: replacer.Replace(template.Down),
+	}, nil
+}
+
+func (service templateService) getTemplate(
+	migrationsDirectoryAbsolutePath,
+	templatePath string,
+) (RenderedMigration, error) {
+	if templatePath == "" {
+		defaultTemplatePath := helpers.AddTrailingSlashToPathIfNeeded(migrationsDirectoryAbsolutePath) +
+			DefaultTemplateFileName
+		template, err := service.getFileTemplate(defaultTemplatePath)
+		if err != nil {
+			return RenderedMigration{Up: defaultMigrationQuery}, nil
+		}
+
+		return template, nil
+	}
+
+	template, err := service.getFileTemplate(templatePath)
+	if err == nil {
+		return template, nil
+	}
+
+	templateDirectoryPath := helpers.AddTrailingSlashToPathIfNeeded(templatePath)
+	up, err := service.fileSystem.ReadFile(templateDirectoryPath + "up.sql")
+	if err != nil {
+		return RenderedMigration{}, NewArgumentsError(errors.Wrapf(
+			err,
+			"invalid 'template' option: [%s] (it must be a file or a directory with an up.sql file)",
+			templatePath,
+		))
+	}
+
+	down, err := service.fileSystem.ReadFile(templateDirectoryPath + "down.sql")
+	if err != nil {
+		// There is no template for the down migration.
+		down = nil
+	}
+
+	return RenderedMigration{Up: string(up), Down: string(down)}, nil
+}
+
+func (service templateService) getFileTemplate(templatePath string) (RenderedMigration, error) {
+	up, err := service.fileSystem.ReadFile(templatePath)
+	if err != nil {
+		return RenderedMigration{}, err
+	}
+
+	down, err := service.fileSystem.ReadFile(repositories.GetDownMigrationPath(templatePath))
+	if err != nil {
+		// There is no template for the down migration.
+		down = nil
+	}
+
+	return RenderedMigration{Up: string(up), Down: string(down)}, nil
+}
+
+// tableNameVerbs are the words removed from the beginning of a migration name to guess the table name.
+var tableNameVerbs = map[string]bool{
+	"create": true, "add": true, "drop": true, "alter": true, "update": true, "remove": true,
+	"insert": true, "delete": true, "rename": true, "change": true, "modify": true, "populate": true,
+}
+
+// tableNamePrepositions separate the columns from the table on a migration name (e.g. addEmailToUsers).
+var tableNamePrepositions = map[string]bool{"to": true, "from": true, "in": true, "into": true, "on": true}
+
+// GuessTableName guesses the table name from the name of a migration (snake_case, kebab-case or camelCase):
+// createGophersTable -> gophers, add_email_to_users -> users.
+func GuessTableName(migrationName string) string {
+	words := splitWords(strings.TrimSuffix(migrationName, ".sql"))
+	for index := len(words) - 1; index >= 0; index-- {
+		if tableNamePrepositions[words[index]] {
+			words = words[index+1:]
+			break
+		}
+	}
+
+	if len(words) > 0 && tableNameVerbs[words[0]] {
+		words = words[1:]
+	}
+
+	if len(words) > 0 && words[len(words)-1] == "table" {
+		words = words[:len(words)-1]
+	}
+
+	return strings.Join(words, "_")
+}
+
+// splitWords splits a snake_case, kebab-case or camelCase name into lowercase words (acronyms are kept together:
+// addURLToUsers -> add, url, to, users).
+func splitWords(name string) []string {
+	var words []string
+	word := &strings.Builder{}
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, strings.ToLower(word.String()))
+			word.Reset()
+		}
+	}
+
+	characters := []rune(name)
+	for index, character := range characters {
+		if !unicode.IsLetter(character) && !unicode.IsDigit(character) {
+			flush()
+			continue
+		}
+
+		if unicode.IsUpper(character) && index > 0 {
+			previous := characters[index-1]
+			nextIsLower := index+1 < len(characters) && unicode.IsLower(characters[index+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				flush()
+			}
+		}
+
+		word.WriteRune(character)
+	}
+	flush()
+
+	return words
+}
@@ -0,0 +1,89 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func getTemplateService() services.Templater {
+	return services.NewTemplateService(adapters.IOUtilAdapter{}, func() string {
+		return "Gopher"
+	})
+}
+
+var templateData = services.TemplateData{
+	Name:  "createGophersTable",
+	Order: 1627676712447528000,
+	Date:  time.Date(2021, 7, 30, 20, 25, 12, 0, time.UTC),
+}
+
+func TestRenderingAMigrationWithoutATemplate(test *testing.T) {
+	test.Parallel()
+
+	migration, err := getTemplateService().Render(test.TempDir(), "", templateData)
+
+	require.Nil(test, err)
+	assert.Equal(test, "SELECT 1;", migration.Up)
+	assert.Equal(test, "", migration.Down)
+}
+
+func TestRenderingAMigrationWithTheDefaultTemplate(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, services.DefaultTemplateFileName,
+		"-- {{name}} ({{timestamp}}) by {{author}} on {{date}}\nCREATE TABLE {{table}} (id INT);")
+	writeFile(test, directory, ".migration-template.down.sql", "DROP TABLE {{table}};")
+
+	migration, err := getTemplateService().Render(directory, "", templateData)
+
+	require.Nil(test, err)
+	assert.Equal(test, "-- createGophersTable (1627676712447528000) by Gopher on 2021-07-30T20:25:12Z\n"+
+		"CREATE TABLE gophers (id INT);", migration.Up)
+	assert.Equal(test, "DROP TABLE gophers;", migration.Down)
+}
+
+func TestRenderingAMigrationWithATemplateDirectory(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "up.sql", "ALTER TABLE {{table}} ADD COLUMN email TEXT;")
+
+	migration, err := getTemplateService().Render("/tmp", directory, services.TemplateData{Name: "add_email_to_users"})
+
+	require.Nil(test, err)
+	assert.Equal(test, "ALTER TABLE users ADD COLUMN email TEXT;", migration.Up)
+	assert.Equal(test, "", migration.Down)
+}
+
+func TestRenderingAMigrationFailsIfTheTemplateDoesNotExist(test *testing.T) {
+	test.Parallel()
+
+	_, err := getTemplateService().Render("/tmp", test.TempDir()+"/missing.sql", templateData)
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestGuessingTableNames(test *testing.T) {
+	test.Parallel()
+
+	cases := map[string]string{
+		"createGophersTable":     "gophers",
+		"create_gophers_table":   "gophers",
+		"add_email_to_users":     "users",
+		"addEmailToUsers.sql":    "users",
+		"insertGopher":           "gopher",
+		"drop-golf-scores-table": "golf_scores",
+		"":                       "",
+	}
+
+	for name, expectedTableName := range cases {
+		assert.Equal(test, expectedTableName, services.GuessTableName(name), name)
+	}
+}
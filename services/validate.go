@@ -0,0 +1,159 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/helpers"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+)
+
+var (
+	validMigrationFileName   = regexp.MustCompile(`^[0-9]+_.+\.sql$`)
+	migrationLikeFileName    = regexp.MustCompile(`^[0-9]+_`)
+	caseInsensitiveExtension = regexp.MustCompile(`(?i)\.sql$`)
+)
+
+// ValidationProblem describes something wrong with a file on the migrations directory.
+type ValidationProblem struct {
+	FilePath string
+	Problem  string
+}
+
+// Validator checks the migrations directory without connecting to the DB.
+type Validator interface {
+	Validate(migrationsDirectoryAbsolutePath string, additionalDirectoryAbsolutePaths ...string) (
+		[]ValidationProblem,
+		error,
+	)
+}
+
+type validatorService struct {
+	fileSystem adapters.FileSystem
+}
+
+// Ensure validatorService implements Validator.
+var _ Validator = validatorService{}
+
+// NewValidatorService returns an implementation of Validator.
+func NewValidatorService(fileSystem adapters.FileSystem) Validator {
+	return validatorService{
+		fileSystem: fileSystem,
+	}
+}
+
+// Validate returns every problem found on the migrations directory, and the additional directories if any (orders must
+// be unique across directories). An error is only returned if a directory cannot be read.
+func (service validatorService) Validate(
+	migrationsDirectoryAbsolutePath string,
+	additionalDirectoryAbsolutePaths ...string,
+) ([]ValidationProblem, error) {
+	problems := []ValidationProblem{}
+	fileNamesByOrder := map[uint64]string{}
+	directoryPaths := append([]string{migrationsDirectoryAbsolutePath}, additionalDirectoryAbsolutePaths...)
+	for index, directoryPath := range directoryPaths {
+		source := ""
+		if index > 0 {
+			source = GetMigrationSource(directoryPath)
+			directoryPath = GetMigrationsDirectoryPath(directoryPath)
+		}
+
+		directoryProblems, err := service.validateDirectory(directoryPath, source, fileNamesByOrder)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, directoryProblems...)
+	}
+
+	return problems, nil
+}
+
+func (service validatorService) validateDirectory(
+	migrationsDirectoryAbsolutePath string,
+	source string,
+	fileNamesByOrder map[uint64]string,
+) ([]ValidationProblem, error) {
+	migrationsDirectoryAbsolutePath = helpers.AddTrailingSlashToPathIfNeeded(migrationsDirectoryAbsolutePath)
+	files, err := service.fileSystem.ReadDir(migrationsDirectoryAbsolutePath)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err,
+			"could not read files from the migrations directory [%s]",
+			migrationsDirectoryAbsolutePath,
+		)
+	}
+
+	var problems []ValidationProblem
+	fileNames := map[string]bool{}
+	for _, file := range files {
+		fileNames[file.Name()] = true
+	}
+
+	for _, file := range files {
+		if file.IsDir() || repositories.IsHiddenFile(file.Name()) {
+			continue
+		}
+
+		if repositories.IsDownMigrationPath(file.Name()) {
+			upFileName := strings.TrimSuffix(file.Name(), repositories.DownMigrationSuffix) + ".sql"
+			if !fileNames[upFileName] {
+				problems = append(problems, ValidationProblem{
+					FilePath: migrationsDirectoryAbsolutePath + file.Name(),
+					Problem:  fmt.Sprintf("the down migration doesn't have a migration to revert (%s)", upFileName),
+				})
+			}
+			continue
+		}
+
+		filePath := migrationsDirectoryAbsolutePath + file.Name()
+		for _, problem := range service.validateFile(filePath, source, fileNamesByOrder) {
+			problems = append(problems, ValidationProblem{FilePath: filePath, Problem: problem})
+		}
+	}
+
+	return problems, nil
+}
+
+func (service validatorService) validateFile(filePath, source string, fileNamesByOrder map[uint64]string) []string {
+	fileName := filePath[strings.LastIndex(filePath, "/")+1:]
+	if !strings.HasSuffix(fileName, ".sql") {
+		if migrationLikeFileName.MatchString(fileName) || caseInsensitiveExtension.MatchString(fileName) {
+			return []string{"the file looks like a migration but it doesn't end in .sql (it will be ignored)"}
+		}
+
+		return nil
+	}
+
+	if !validMigrationFileName.MatchString(fileName) {
+		return []string{"invalid file name (the format must be {number}_{string}.sql)"}
+	}
+
+	migration, err := models.NewMigrationFromSource(source, filePath, "", models.StatusNotRun)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid file name (%s)", err)}
+	}
+
+	var problems []string
+	otherFileName, orderIsDuplicated := fileNamesByOrder[migration.GetOrder()]
+	if orderIsDuplicated {
+		problems = append(problems, fmt.Sprintf("the order %d is already used by %s", migration.GetOrder(), otherFileName))
+	} else {
+		fileNamesByOrder[migration.GetOrder()] = models.GetQualifiedName(migration)
+	}
+
+	query, err := service.fileSystem.ReadFile(filePath)
+	if err != nil {
+		return append(problems, fmt.Sprintf("the file cannot be read (%s)", err))
+	}
+
+	if strings.TrimSpace(string(query)) == "" {
+		problems = append(problems, "the migration is empty")
+	}
+
+	return problems
+}
@@ -0,0 +1,130 @@
+package services_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/mocks"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestValidatingAValidMigrationsDirectory(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "1_createGophersTable.sql", "CREATE TABLE gophers (id INT);")
+	writeFile(test, directory, "1_createGophersTable.down.sql", "DROP TABLE gophers;")
+	writeFile(test, directory, "2_insertGopher.sql", "INSERT INTO gophers VALUES (1);")
+	writeFile(test, directory, "README.md", "# Migrations")
+	writeFile(test, directory, services.DefaultTemplateFileName, "-- {{.Name}}")
+	require.Nil(test, os.Mkdir(filepath.Join(directory, "archive"), 0755))
+
+	service := services.NewValidatorService(adapters.IOUtilAdapter{})
+
+	problems, err := service.Validate(directory)
+
+	require.Nil(test, err)
+	assert.Empty(test, problems)
+}
+
+func TestValidatingReportsEveryProblem(test *testing.T) {
+	test.Parallel()
+
+	directory := test.TempDir()
+	writeFile(test, directory, "1_createGophersTable.sql", "CREATE TABLE gophers (id INT);")
+	writeFile(test, directory, "1_duplicatedOrder.sql", "SELECT 1;")
+	writeFile(test, directory, "2_empty.sql", " \n\t\n")
+	writeFile(test, directory, "3_notSql.txt", "SELECT 1;")
+	writeFile(test, directory, "4_upperCase.SQL", "SELECT 1;")
+	writeFile(test, directory, "createGolfersTable.sql", "SELECT 1;")
+	writeFile(test, directory, "99999999999999999999_tooBig.sql", "SELECT 1;")
+	writeFile(test, directory, "5_orphan.down.sql", "SELECT 1;")
+
+	service := services.NewValidatorService(adapters.IOUtilAdapter{})
+
+	problems, err := service.Validate(directory)
+
+	require.Nil(test, err)
+	problemsByFile := map[string]string{}
+	for _, problem := range problems {
+		problemsByFile[filepath.Base(problem.FilePath)] = problem.Problem
+	}
+
+	assert.Len(test, problems, 7)
+	assert.Contains(test, problemsByFile["1_duplicatedOrder.sql"], "already used by 1_createGophersTable.sql")
+	assert.Contains(test, problemsByFile["2_empty.sql"], "empty")
+	assert.Contains(test, problemsByFile["3_notSql.txt"], "doesn't end in .sql")
+	assert.Contains(test, problemsByFile["4_upperCase.SQL"], "doesn't end in .sql")
+	assert.Contains(test, problemsByFile["createGolfersTable.sql"], "invalid file name")
+	assert.Contains(test, problemsByFile["99999999999999999999_tooBig.sql"], "invalid file name")
+	assert.Contains(test, problemsByFile["5_orphan.down.sql"], "doesn't have a migration to revert")
+}
+
+func TestValidatingSeveralDirectoriesReportsOrdersUsedOnBoth(test *testing.T) {
+	test.Parallel()
+
+	core := test.TempDir()
+	billing := filepath.Join(test.TempDir(), "billing")
+	require.Nil(test, os.Mkdir(billing, 0755))
+	writeFile(test, core, "1_createGophersTable.sql", "CREATE TABLE gophers (id INT);")
+	writeFile(test, billing, "1_createInvoicesTable.sql", "CREATE TABLE invoices (id INT);")
+	writeFile(test, billing, "2_createPaymentsTable.sql", "CREATE TABLE payments (id INT);")
+
+	service := services.NewValidatorService(adapters.IOUtilAdapter{})
+
+	problems, err := service.Validate(core, billing)
+
+	require.Nil(test, err)
+	require.Len(test, problems, 1)
+	assert.Equal(test, filepath.Join(billing, "1_createInvoicesTable.sql"), problems[0].FilePath)
+	assert.Contains(test, problems[0].Problem, "already used by 1_createGophersTable.sql")
+}
+
+func TestValidatingReportsUnreadableFiles(test *testing.T) {
+	test.Parallel()
+
+	file := &mocks.File{}
+	file.On("Name").Return("1_a.sql")
+	file.On("IsDir").Return(false)
+	fileSystem := &mocks.FileSystem{}
+	defer fileSystem.AssertExpectations(test)
+	fileSystem.On("ReadDir", "/tmp/").Return([]os.FileInfo{file}, nil)
+	fileSystem.On("ReadFile", "/tmp/1_a.sql").Return(nil, fmt.Errorf("permission denied"))
+
+	service := services.NewValidatorService(fileSystem)
+
+	problems, err := service.Validate("/tmp")
+
+	require.Nil(test, err)
+	require.Len(test, problems, 1)
+	assert.Equal(test, "/tmp/1_a.sql", problems[0].FilePath)
+	assert.Contains(test, problems[0].Problem, "permission denied")
+}
+
+func TestValidatingFailsIfTheDirectoryCannotBeRead(test *testing.T) {
+	test.Parallel()
+
+	fileSystem := &mocks.FileSystem{}
+	defer fileSystem.AssertExpectations(test)
+	fileSystem.On("ReadDir", "/tmp/").Return(nil, fmt.Errorf("no such directory"))
+
+	service := services.NewValidatorService(fileSystem)
+
+	_, err := service.Validate("/tmp")
+
+	assert.NotNil(test, err)
+}
+
+func writeFile(test *testing.T, directory, fileName, contents string) {
+	test.Helper()
+
+	err := ioutil.WriteFile(filepath.Join(directory, fileName), []byte(contents), 0644)
+	require.Nil(test, err)
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"runtime/debug"
+)
+
+const (
+	// ModulePath is the path of this module, used to find its version on the build info.
+	ModulePath = "github.com/jimenezmaximiliano/migrations"
+	// DevelopmentVersion is the version reported when the module version is unknown (e.g. built from a local copy).
+	DevelopmentVersion = "(devel)"
+)
+
+// ReadBuildInfo returns the build info of the running binary (see debug.ReadBuildInfo).
+type ReadBuildInfo func() (*debug.BuildInfo, bool)
+
+// GetVersion returns the version of this module: the version of the main module if the binary is built from it (e.g.
+// go install .../cmd/migrations@v1.2.3) or the version of the dependency otherwise (a custom binary).
+func GetVersion(readBuildInfo ReadBuildInfo) string {
+	buildInfo, ok := readBuildInfo()
+	if !ok || buildInfo == nil {
+		return DevelopmentVersion
+	}
+
+	if buildInfo.Main.Path == ModulePath {
+		return getModuleVersion(&buildInfo.Main)
+	}
+
+	for _, dependency := range buildInfo.Deps {
+		if dependency.Path == ModulePath {
+			return getModuleVersion(dependency)
+		}
+	}
+
+	return DevelopmentVersion
+}
+
+func getModuleVersion(module *debug.Module) string {
+	if module.Replace != nil {
+		module = module.Replace
+	}
+
+	if module.Version == "" {
+		return DevelopmentVersion
+	}
+
+	return module.Version
+}
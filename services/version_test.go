@@ -0,0 +1,66 @@
+package services_test
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestGettingTheVersionOfTheModule(test *testing.T) {
+	test.Parallel()
+
+	cases := map[string]struct {
+		buildInfo *debug.BuildInfo
+		ok        bool
+		expected  string
+	}{
+		"no build info": {nil, false, services.DevelopmentVersion},
+		"main module": {
+			&debug.BuildInfo{Main: debug.Module{Path: services.ModulePath, Version: "v1.2.3"}},
+			true,
+			"v1.2.3",
+		},
+		"dependency": {
+			&debug.BuildInfo{
+				Main: debug.Module{Path: "example.com/app", Version: "(devel)"},
+				Deps: []*debug.Module{
+					{Path: "github.com/pkg/errors", Version: "v0.9.1"},
+					{Path: services.ModulePath, Version: "v1.4.0"},
+				},
+			},
+			true,
+			"v1.4.0",
+		},
+		"replaced dependency": {
+			&debug.BuildInfo{
+				Main: debug.Module{Path: "example.com/app"},
+				Deps: []*debug.Module{
+					{Path: services.ModulePath, Version: "v1.4.0", Replace: &debug.Module{Path: "../migrations"}},
+				},
+			},
+			true,
+			services.DevelopmentVersion,
+		},
+		"not a dependency": {
+			&debug.BuildInfo{Main: debug.Module{Path: "example.com/app"}},
+			true,
+			services.DevelopmentVersion,
+		},
+	}
+
+	for name, testCase := range cases {
+		testCase := testCase
+		test.Run(name, func(test *testing.T) {
+			test.Parallel()
+
+			version := services.GetVersion(func() (*debug.BuildInfo, bool) {
+				return testCase.buildInfo, testCase.ok
+			})
+
+			assert.Equal(test, testCase.expected, version)
+		})
+	}
+}
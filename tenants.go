@@ -0,0 +1,244 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimenezmaximiliano/migrations/adapters"
+	"github.com/jimenezmaximiliano/migrations/models"
+	"github.com/jimenezmaximiliano/migrations/repositories"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+// RunMigrationsOnDBs runs the same migrations on several DBs (e.g. one per tenant), mapped by name, on up to
+// concurrency DBs at the same time. A failure on a DB doesn't stop the others: check MultiRunResult.Err.
+func RunMigrationsOnDBs(
+	DBs map[string]*sql.DB,
+	migrationsDirectoryAbsolutePath string,
+	concurrency int,
+) (services.MultiRunResult, error) {
+	names := make([]string, 0, len(DBs))
+	for name := range DBs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fileRepository := repositories.NewFileRepository(adapters.IOUtilAdapter{})
+	arguments := services.Arguments{
+		MigrationsPath: migrationsDirectoryAbsolutePath,
+	}
+
+	targets := make([]services.RunTarget, len(names))
+	for index, name := range names {
+		targets[index] = services.RunTarget{
+			Name:   name,
+			Runner: getMigrationRunner(DBs[name], fileRepository, arguments),
+		}
+	}
+
+	return services.NewMultiRunnerService(concurrency).RunMigrations(targets)
+}
+
+// RunMigrationsOnSchemas runs the same migrations on several schemas of a MySQL server (e.g. one per tenant), on up to
+// concurrency schemas at the same time. Each schema has its own migrations table. A failure on a schema doesn't stop
+// the others: check MultiRunResult.Err.
+func RunMigrationsOnSchemas(
+	DB *sql.DB,
+	schemas []string,
+	migrationsDirectoryAbsolutePath string,
+	concurrency int,
+) (services.MultiRunResult, error) {
+	arguments := services.Arguments{
+		MigrationsPath: migrationsDirectoryAbsolutePath,
+		Schemas:        schemas,
+		Concurrency:    uint64(concurrency),
+	}
+	fileRepository := repositories.NewFileRepository(adapters.IOUtilAdapter{})
+
+	targets, err := getSchemaRunTargets(context.Background(), DB, fileRepository, arguments, nil)
+	if err != nil {
+		return services.MultiRunResult{}, err
+	}
+
+	return services.NewMultiRunnerService(concurrency).RunMigrations(targets)
+}
+
+// getSchemaRunTargets returns a RunTarget per schema. If the 'connect-timeout' option is set, connecting to the DB is
+// retried, notifying each attempt to the progress (if any).
+func getSchemaRunTargets(
+	ctx context.Context,
+	DB *sql.DB,
+	fileRepository repositories.FileRepository,
+	arguments services.Arguments,
+	connectionProgress services.ConnectionProgress,
+) ([]services.RunTarget, error) {
+	if getDialect(DB) != repositories.DialectMySQL {
+		return nil, services.NewArgumentsError(errors.New("schemas are only supported on MySQL"))
+	}
+
+	targets := make([]services.RunTarget, len(arguments.Schemas))
+	for index, schema := range arguments.Schemas {
+		targets[index] = services.RunTarget{
+			Name: schema,
+			Runner: schemaRunner{
+				ctx:                ctx,
+				DB:                 DB,
+				schema:             schema,
+				fileRepository:     fileRepository,
+				arguments:          arguments,
+				connectionProgress: connectionProgress,
+			},
+		}
+	}
+
+	return targets, nil
+}
+
+// schemaRunner runs migrations on a dedicated connection that uses a schema.
+type schemaRunner struct {
+	ctx                context.Context
+	DB                 *sql.DB
+	schema             string
+	fileRepository     repositories.FileRepository
+	arguments          services.Arguments
+	connectionProgress services.ConnectionProgress
+}
+
+// Ensure schemaRunner implements services.Runner.
+var _ services.Runner = schemaRunner{}
+
+// RunMigrations runs the migrations on the schema.
+func (runner schemaRunner) RunMigrations() (migrations models.Collection, err error) {
+	err = runner.useSchema(func(dbRepository repositories.DBRepository) error {
+		migrationFetcher := services.NewFetcherService(
+			dbRepository,
+			runner.fileRepository,
+			runner.arguments.GetAdditionalMigrationsPaths()...,
+		)
+		migrations, err = services.NewRunnerService(
+			migrationFetcher,
+			dbRepository,
+			runner.arguments.MigrationsPath,
+		).RunMigrations()
+
+		return err
+	})
+
+	return migrations, err
+}
+
+// useSchema calls the function with a DBRepository on a dedicated connection that uses the schema. The connection is
+// discarded afterwards, so the schema doesn't leak to the connection pool. Connecting to the DB is retried if the
+// 'connect-timeout' option is set.
+func (runner schemaRunner) useSchema(function func(dbRepository repositories.DBRepository) error) error {
+	ctx := runner.ctx
+	err := withConnectionRetry(ctx, getDBRepository(runner.DB), runner.arguments, runner.connectionProgress).Ping()
+	if err != nil {
+		return services.NewDBConnectionError(errors.Wrap(err, "failed to connect to the DB"))
+	}
+
+	conn, err := runner.DB.Conn(ctx)
+	if err != nil {
+		return services.NewDBConnectionError(errors.Wrap(err, "failed to connect to the DB"))
+	}
+	defer func() {
+		_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		_ = conn.Close()
+	}()
+
+	_, err = conn.ExecContext(ctx, "USE `"+strings.ReplaceAll(runner.schema, "`", "``")+"`")
+	if err != nil {
+		return services.NewDBSetupError(errors.Wrapf(err, "could not use the schema [%s]", runner.schema))
+	}
+
+	return function(repositories.NewDBRepositoryForDialect(
+		adapters.NewConnAdapter(ctx, conn),
+		repositories.DialectMySQL,
+	))
+}
+
+// getSchemaGuards returns a Guard per schema (see getSchemaRunTargets).
+func getSchemaGuards(
+	ctx context.Context,
+	DB *sql.DB,
+	fileRepository repositories.FileRepository,
+	arguments services.Arguments,
+	connectionProgress services.ConnectionProgress,
+) []services.Guard {
+	guards := make([]services.Guard, len(arguments.Schemas))
+	for index, schema := range arguments.Schemas {
+		guards[index] = schemaGuard{
+			runner: schemaRunner{
+				ctx:                ctx,
+				DB:                 DB,
+				schema:             schema,
+				fileRepository:     fileRepository,
+				arguments:          arguments,
+				connectionProgress: connectionProgress,
+			},
+		}
+	}
+
+	return guards
+}
+
+// schemaGuard handles the migration preview and the protected environment mark of a schema, on a dedicated
+// connection that uses it (see schemaRunner).
+type schemaGuard struct {
+	runner schemaRunner
+}
+
+// Ensure schemaGuard implements services.Guard.
+var _ services.Guard = schemaGuard{}
+
+// GetMigrationPreview returns the migration preview of the schema.
+func (guard schemaGuard) GetMigrationPreview() (preview services.MigrationPreview, err error) {
+	err = guard.withGuard(func(schemaGuard services.Guard) error {
+		preview, err = schemaGuard.GetMigrationPreview()
+		return err
+	})
+
+	return preview, err
+}
+
+// GetProtectedEnvironment returns the environment the schema is marked as, if any.
+func (guard schemaGuard) GetProtectedEnvironment() (environment string, err error) {
+	err = guard.withGuard(func(schemaGuard services.Guard) error {
+		environment, err = schemaGuard.GetProtectedEnvironment()
+		return err
+	})
+
+	return environment, err
+}
+
+// Protect marks the schema as a protected environment.
+func (guard schemaGuard) Protect(environment string) error {
+	return guard.withGuard(func(schemaGuard services.Guard) error {
+		return schemaGuard.Protect(environment)
+	})
+}
+
+// Unprotect removes the protected environment mark of the schema.
+func (guard schemaGuard) Unprotect() error {
+	return guard.withGuard(func(schemaGuard services.Guard) error {
+		return schemaGuard.Unprotect()
+	})
+}
+
+func (guard schemaGuard) withGuard(function func(schemaGuard services.Guard) error) error {
+	runner := guard.runner
+
+	return runner.useSchema(func(dbRepository repositories.DBRepository) error {
+		migrationFetcher := services.NewFetcherService(
+			dbRepository,
+			runner.fileRepository,
+			runner.arguments.GetAdditionalMigrationsPaths()...,
+		)
+		return function(services.NewGuardService(migrationFetcher, dbRepository, runner.arguments.MigrationsPath))
+	})
+}
@@ -0,0 +1,77 @@
+package migrations_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jimenezmaximiliano/migrations"
+	"github.com/jimenezmaximiliano/migrations/services"
+)
+
+func TestRunningMigrationsOnSeveralSQLiteDBs(test *testing.T) {
+	directory := test.TempDir()
+	DBs := map[string]*sql.DB{}
+	for _, tenant := range []string{"tenant_a", "tenant_b"} {
+		db, err := sql.Open("sqlite", filepath.Join(directory, tenant+".sqlite"))
+		require.Nil(test, err)
+		defer func() {
+			assert.Nil(test, db.Close())
+		}()
+		DBs[tenant] = db
+	}
+
+	_, err := DBs["tenant_b"].Exec("CREATE TABLE gophers (id INTEGER)")
+	require.Nil(test, err)
+
+	result, err := migrations.RunMigrationsOnDBs(DBs, "./fixtures/sqlite", 2)
+
+	require.Nil(test, err)
+	assert.Equal(test, []string{"tenant_a", "tenant_b"}, result.TargetNames)
+	assert.Equal(test, []string{"tenant_b"}, result.GetFailedTargetNames())
+	assert.Equal(test, services.ExitCodeMigrationFailed, services.ExitCode(result.Err()))
+
+	tenantA := result.GetCollections()["tenant_a"]
+	assert.Len(test, tenantA.GetAll(), 2)
+}
+
+func TestRunningMigrationsOnSchemasIsOnlySupportedOnMySQL(test *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(test.TempDir(), "db.sqlite"))
+	require.Nil(test, err)
+	defer func() {
+		assert.Nil(test, db.Close())
+	}()
+
+	_, err = migrations.RunMigrationsOnSchemas(db, []string{"tenant_a"}, "./fixtures/sqlite", 1)
+
+	assert.Equal(test, services.ExitCodeInvalidArguments, services.ExitCode(err))
+}
+
+func TestMigratingSeveralSchemasRetriesToConnectToTheDB(test *testing.T) {
+	test.Parallel()
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	setupDB := func() (*sql.DB, error) {
+		// Nothing listens on port 1, so connecting is refused (a retryable error).
+		return sql.Open("mysql", "user:password@tcp(127.0.0.1:1)/db")
+	}
+	args := []string{
+		"migrate",
+		"-path=./fixtures/sqlite",
+		"-schemas=tenant_a,tenant_b",
+		"-connect-timeout=300ms",
+		"-connect-backoff=50ms",
+		"-yes",
+	}
+
+	exitCode := migrations.RunCommand(context.Background(), args, stdout, stderr, setupDB)
+
+	assert.Equal(test, services.ExitCodeDBUnavailable, exitCode)
+	assert.Contains(test, stdout.String()+stderr.String(), "Could not connect to the DB (attempt 2)")
+}